@@ -0,0 +1,239 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/urfave/cli/v3"
+	"go.uber.org/zap"
+
+	"github.com/suchasplus/ne/internal/bbolthelper"
+)
+
+// importFormats maps the --format names of `kvbuilder import` to their importers.
+var importFormats = map[string]func(path string) importFunc{
+	"csv": func(path string) importFunc {
+		return func(ctx context.Context, store *bbolthelper.DBStore, opts bbolthelper.ImportOptions) (int, error) {
+			return store.ImportCSV(ctx, path, opts)
+		}
+	},
+	"cedict": func(path string) importFunc {
+		return func(ctx context.Context, store *bbolthelper.DBStore, opts bbolthelper.ImportOptions) (int, error) {
+			return store.ImportCEDICT(ctx, path, opts)
+		}
+	},
+	"tatoeba": func(path string) importFunc {
+		return func(ctx context.Context, store *bbolthelper.DBStore, opts bbolthelper.ImportOptions) (int, error) {
+			return store.ImportTatoeba(ctx, path, opts)
+		}
+	},
+	"wiktionary": func(path string) importFunc {
+		return func(ctx context.Context, store *bbolthelper.DBStore, opts bbolthelper.ImportOptions) (int, error) {
+			return store.ImportWiktionary(ctx, path, opts)
+		}
+	},
+	"wordnet": func(path string) importFunc {
+		return func(ctx context.Context, store *bbolthelper.DBStore, opts bbolthelper.ImportOptions) (int, error) {
+			return store.ImportWordNet(ctx, path, opts)
+		}
+	},
+	"stardict": func(path string) importFunc {
+		return func(ctx context.Context, store *bbolthelper.DBStore, opts bbolthelper.ImportOptions) (int, error) {
+			return store.ImportStarDict(ctx, path, opts)
+		}
+	},
+}
+
+// defaultBuckets are the buckets `kvbuilder import` writes sources of a format to without --dict,
+// so that importing them never replaces ECDICT in the default bucket.
+var defaultBuckets = map[string]string{
+	"cedict":     "cedict",
+	"tatoeba":    "tatoeba",
+	"wiktionary": "wiktionary",
+	"wordnet":    "wordnet",
+}
+
+// defaultBucket returns the bucket a source is imported into without --dict: the default bucket
+// for ECDICT, a bucket named after the format or the StarDict bookname otherwise. Other tabular
+// sources have no default and need --dict.
+func defaultBucket(path, format string, mf *mappingFlags) (string, error) {
+	if bucket, ok := defaultBuckets[format]; ok {
+		return bucket, nil
+	}
+	switch {
+	case format == "stardict":
+		name, err := bbolthelper.StarDictBookName(path)
+		if err != nil {
+			return "", err
+		}
+		if bucket := strings.Join(strings.Fields(strings.ToLower(strings.ReplaceAll(name, ":", " "))), "-"); bucket != "" {
+			return bucket, nil
+		}
+		return strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)), nil
+	case format == "csv" && !mf.set(), mf.mapping == "ecdict":
+		return bbolthelper.DefaultBucketName, nil
+	}
+	return "", fmt.Errorf("--dict is required for %s sources other than ECDICT", format)
+}
+
+// tableImporter returns an importer of a tabular source, read according to a column mapping.
+func tableImporter(path, format string, mapping bbolthelper.Mapping) importFunc {
+	return func(ctx context.Context, store *bbolthelper.DBStore, opts bbolthelper.ImportOptions) (int, error) {
+		return store.ImportTable(ctx, path, format, mapping, opts)
+	}
+}
+
+// importFormatNames lists the supported --format values for help and error messages.
+const importFormatNames = "csv, tsv, jsonl, sqlite, stardict, cedict, wordnet, wiktionary, tatoeba"
+
+// mappingFlags holds the column mapping flags of `kvbuilder import` for tabular sources.
+type mappingFlags struct {
+	mapping     string
+	table       string
+	key         string
+	rename      []string
+	drop        []string
+	transform   []string
+	keepKeyCase bool
+}
+
+// set reports whether any mapping flag was given.
+func (f *mappingFlags) set() bool {
+	return f.mapping != "" || f.table != "" || f.key != "" || len(f.rename) > 0 || len(f.drop) > 0 ||
+		len(f.transform) > 0 || f.keepKeyCase
+}
+
+// build returns the mapping named or read by --mapping, overridden by the other flags.
+func (f *mappingFlags) build() (bbolthelper.Mapping, error) {
+	var m bbolthelper.Mapping
+	if f.mapping != "" {
+		var err error
+		if m, err = bbolthelper.LoadMapping(f.mapping); err != nil {
+			return m, err
+		}
+	}
+	if f.table != "" {
+		m.Table = f.table
+	}
+	if f.key != "" {
+		m.Key = f.key
+	}
+	m.KeepKeyCase = m.KeepKeyCase || f.keepKeyCase
+	m.Drop = append(m.Drop, f.drop...)
+	for _, r := range f.rename {
+		from, to, ok := strings.Cut(r, "=")
+		if !ok || from == "" || to == "" {
+			return m, fmt.Errorf("invalid --rename '%s' (want column=field)", r)
+		}
+		if m.Rename == nil {
+			m.Rename = make(map[string]string)
+		}
+		m.Rename[from] = to
+	}
+	for _, t := range f.transform {
+		col, name, ok := strings.Cut(t, "=")
+		if !ok || col == "" || name == "" {
+			return m, fmt.Errorf("invalid --transform '%s' (want column=transform[:argument])", t)
+		}
+		if m.Transforms == nil {
+			m.Transforms = make(map[string][]string)
+		}
+		m.Transforms[col] = append(m.Transforms[col], name)
+	}
+	return m, m.Validate()
+}
+
+// importCommand returns the `kvbuilder import` subcommand, which builds a dictionary from a source
+// in any supported format.
+func importCommand(logger *zap.Logger) *cli.Command {
+	var formatFlag string
+	var mf mappingFlags
+	return &cli.Command{
+		Name:      "import",
+		Usage:     "Import a dictionary in another format, e.g. import --format stardict dict.ifo",
+		ArgsUsage: "<path>",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:        "format",
+				Aliases:     []string{"f"},
+				Usage:       fmt.Sprintf("Format of the source: %s. A StarDict dictionary is given by its .ifo file, WordNet by its dict directory, Tatoeba by its sentences.tsv with links.tsv next to it. Without --dict, ECDICT is imported into the default bucket, StarDict into a bucket named after its bookname and cedict, wordnet, wiktionary and tatoeba into a bucket named after the format; other tables need --dict", importFormatNames),
+				Value:       "csv",
+				Destination: &formatFlag,
+			},
+			&cli.StringFlag{
+				Name:        "mapping",
+				Usage:       "Column mapping of a csv, tsv, jsonl or sqlite source: a built-in mapping (ecdict, for ECDICT's stardict.db) or a JSON file",
+				Destination: &mf.mapping,
+			},
+			&cli.StringFlag{
+				Name:        "table",
+				Usage:       "Table of an sqlite source (default: its only table)",
+				Destination: &mf.table,
+			},
+			&cli.StringFlag{
+				Name:        "key",
+				Usage:       "Column holding the headword (default: the first column)",
+				Destination: &mf.key,
+			},
+			&cli.StringSliceFlag{
+				Name:        "rename",
+				Usage:       "Store a column under another field name, e.g. --rename gloss=translation (repeatable)",
+				Destination: &mf.rename,
+			},
+			&cli.StringSliceFlag{
+				Name:        "drop",
+				Usage:       "Column not to store (repeatable)",
+				Destination: &mf.drop,
+			},
+			&cli.StringSliceFlag{
+				Name:        "transform",
+				Usage:       fmt.Sprintf("Transform the values of a column, e.g. --transform 'gloss=lines:;' (repeatable; transforms: %s)", strings.Join(bbolthelper.ValueTransformNames(), ", ")),
+				Destination: &mf.transform,
+			},
+			&cli.BoolFlag{
+				Name:        "keep-key-case",
+				Usage:       "Store headwords as they are instead of lowercasing them",
+				Destination: &mf.keepKeyCase,
+			},
+		},
+		Action: func(ctx context.Context, cCtx *cli.Command) error {
+			path := cCtx.Args().First()
+			if path == "" {
+				return fmt.Errorf("no source given; usage: kvbuilder import --format <format> <path>")
+			}
+			format := strings.ToLower(formatFlag)
+			newImporter, ok := importFormats[format]
+			table := slices.Contains(bbolthelper.TableFormats, format)
+			if !ok && !table {
+				return fmt.Errorf("unknown format '%s' (want %s)", formatFlag, importFormatNames)
+			}
+			if mf.set() && !table {
+				return fmt.Errorf("column mapping flags apply to csv, tsv, jsonl and sqlite sources, not %s", format)
+			}
+			if _, err := os.Stat(path); err != nil {
+				return fmt.Errorf("source '%s' not found or not accessible: %w", path, err)
+			}
+			logger.Info("Using source", zap.String("path", path), zap.String("format", formatFlag))
+			bucket := bucketNameFlag
+			if bucket == "" {
+				var err error
+				if bucket, err = defaultBucket(path, format, &mf); err != nil {
+					return err
+				}
+			}
+			// A CSV file without a mapping takes the resumable ECDICT CSV import.
+			if table && (format != "csv" || mf.set()) {
+				mapping, err := mf.build()
+				if err != nil {
+					return err
+				}
+				return buildDictionary(ctx, logger, path, format, bucket, tableImporter(path, format, mapping))
+			}
+			return buildDictionary(ctx, logger, path, format, bucket, newImporter(path))
+		},
+	}
+}
@@ -2,9 +2,14 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"runtime"
+	"strings"
+	"syscall"
 
 	"github.com/urfave/cli/v3"
 	"go.uber.org/zap"
@@ -19,17 +24,51 @@ const (
 	progressReportInterval = 50000
 )
 
+// version identifies this kvbuilder build in the database metadata.
+// It can be overridden at link time with -ldflags "-X main.version=...".
+var version = "dev"
+
+var (
+	csvPathFlag    string
+	dbPathFlag     string
+	bucketNameFlag string
+	codecFlag      string
+	resumeFlag     bool
+	batchSizeFlag  int
+	workersFlag    int
+	indexFlag      []string
+	nameFlag       string
+	priorityFlag   int
+	sourceFlag     string
+)
+
+// importFunc imports a dictionary source into the build database.
+type importFunc func(ctx context.Context, store *bbolthelper.DBStore, opts bbolthelper.ImportOptions) (int, error)
+
 func main() {
 	logger := zap.NewExample()
 	defer logger.Sync() // flushes buffer, if any
 
-	var csvPathFlag string
-	var dbPathFlag string
-	var bucketNameFlag string
+	// Ctrl-C or SIGTERM cancels the context so the import stops at a clean batch boundary.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-	cmd := &cli.Command{
-		Name:  "kvbuilder-importer",
-		Usage: "Imports data from a CSV file into a bbolt key-value store.",
+	if err := newApp(logger).Run(ctx, os.Args); err != nil {
+		// Logger might not be initialized if error is from CLI parsing.
+		fmt.Fprintf(os.Stderr, "Error running kvbuilder-importer: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// newApp returns the kvbuilder command with its flags and subcommands.
+func newApp(logger *zap.Logger) *cli.Command {
+	return &cli.Command{
+		Name:    "kvbuilder-importer",
+		Usage:   "Imports data from a CSV file into a bbolt key-value store.",
+		Version: version,
+		Commands: []*cli.Command{
+			importCommand(logger),
+		},
 		Flags: []cli.Flag{
 			&cli.StringFlag{
 				Name:        "csv",
@@ -45,10 +84,56 @@ func main() {
 			},
 			&cli.StringFlag{
 				Name:        "bucket",
-				Aliases:     []string{"b"},
-				Usage:       fmt.Sprintf("Name of the bucket within the bbolt database. Defaults to '%s'", bbolthelper.DefaultBucketName),
+				Aliases:     []string{"b", "dict"},
+				Usage:       fmt.Sprintf("Bucket of the dictionary to add or replace; other dictionaries in the database are kept. Defaults to '%s' for ECDICT; see `import --help` for other formats", bbolthelper.DefaultBucketName),
 				Destination: &bucketNameFlag,
 			},
+			&cli.StringFlag{
+				Name:        "name",
+				Usage:       "Display name of the dictionary, shown by ne instead of the bucket name",
+				Destination: &nameFlag,
+			},
+			&cli.IntFlag{
+				Name:        "priority",
+				Usage:       "Priority of the dictionary; ne lists results of higher-priority dictionaries first",
+				Destination: &priorityFlag,
+			},
+			&cli.StringFlag{
+				Name:        "source",
+				Usage:       "Where the dictionary comes from, e.g. a URL or team, recorded in its manifest",
+				Destination: &sourceFlag,
+			},
+			&cli.StringFlag{
+				Name:        "codec",
+				Usage:       "Record encoding for stored values: 'binary' (compact, see docs/record_format.md) or 'gob' (legacy)",
+				Value:       bbolthelper.CodecBinary.String(),
+				Destination: &codecFlag,
+			},
+			&cli.BoolFlag{
+				Name:        "resume",
+				Usage:       "Continue an interrupted import from its last committed batch",
+				Destination: &resumeFlag,
+			},
+			&cli.IntFlag{
+				Name:        "batch-size",
+				Usage:       "Number of records committed per transaction",
+				Value:       bbolthelper.DefaultImportBatchSize,
+				Destination: &batchSizeFlag,
+			},
+			&cli.IntFlag{
+				Name:        "workers",
+				Aliases:     []string{"w"},
+				Usage:       "Number of parallel CSV parse/serialize workers (1 disables the pipeline)",
+				Value:       runtime.NumCPU(),
+				Destination: &workersFlag,
+			},
+			&cli.StringSliceFlag{
+				Name:        "index",
+				Aliases:     []string{"i"},
+				Usage:       fmt.Sprintf("Secondary indexes to build (repeatable; 'default' expands to %s, 'none' builds none). Available: %s", strings.Join(bbolthelper.DefaultIndexes, ", "), strings.Join(bbolthelper.AvailableIndexes(), ", ")),
+				Value:       bbolthelper.DefaultIndexes,
+				Destination: &indexFlag,
+			},
 		},
 		Action: func(ctx context.Context, cCtx *cli.Command) error {
 			// Determine actual CSV path
@@ -71,70 +156,138 @@ func main() {
 				}
 			}
 			logger.Info("Using CSV file", zap.String("path", actualCsvPath))
-
-			// Determine DB path and bucket name
-			actualDBPath := dbPathFlag
-			if actualDBPath == "" {
-				resolvedPath, err := resolveDefaultDBPathForKvBuilder(bbolthelper.DefaultDBPath, logger)
-				if err != nil {
-					logger.Error("Failed to resolve or prepare default database path", zap.Error(err))
-					fmt.Fprintf(os.Stderr, "Error resolving DB path: %v\n", err)
-					return err
-				}
-				actualDBPath = resolvedPath
-				logger.Info("Using database path", zap.String("path", actualDBPath))
+			bucket := bucketNameFlag
+			if bucket == "" {
+				bucket = bbolthelper.DefaultBucketName
 			}
+			return buildDictionary(ctx, logger, actualCsvPath, "csv", bucket, importFormats["csv"](actualCsvPath))
+		},
+	}
+}
 
-			actualBucketName := bucketNameFlag
-			if actualBucketName == "" {
-				actualBucketName = bbolthelper.DefaultBucketName
-			}
+// buildDictionary carries over the other dictionaries of an existing database into a build file
+// next to it, imports a source of the given format into bucket, builds the selected indexes and
+// publishes the result. It refuses to replace a dictionary imported from another format.
+func buildDictionary(ctx context.Context, logger *zap.Logger, sourcePath, format, bucket string, importFn importFunc) error {
+	// Determine DB path
+	actualDBPath := dbPathFlag
+	if actualDBPath == "" {
+		resolvedPath, err := resolveDefaultDBPathForKvBuilder(bbolthelper.DefaultDBPath, logger)
+		if err != nil {
+			logger.Error("Failed to resolve or prepare default database path", zap.Error(err))
+			fmt.Fprintf(os.Stderr, "Error resolving DB path: %v\n", err)
+			return err
+		}
+		actualDBPath = resolvedPath
+		logger.Info("Using database path", zap.String("path", actualDBPath))
+	}
 
-			logger.Info("Target database settings",
-				zap.String("dbPath", actualDBPath),
-				zap.String("bucketName", actualBucketName),
-			)
-
-			storeConfig := bbolthelper.Config{
-				DBPath:     actualDBPath,
-				BucketName: actualBucketName,
-				Logger:     logger,
-				// FileMode will use DefaultDBFileMode from bbolthelper
-				// ReadOnly will be false by default
-			}
-			store, err := bbolthelper.NewDBStore(storeConfig)
-			if err != nil {
-				return fmt.Errorf("failed to initialize db store: %w", err)
-			}
+	codec, err := bbolthelper.ParseCodec(codecFlag)
+	if err != nil {
+		return err
+	}
 
-			// NewDBStore already opens the database, so no explicit store.Open() is needed.
-			defer store.Close() // Ensure DB is closed even if subsequent steps fail
+	// The database is built in a sibling file and only swapped in once complete, so that
+	// `ne` never sees a half-built database and is never blocked by the writer's file lock.
+	buildPath := bbolthelper.BuildPath(actualDBPath)
+	if !resumeFlag {
+		if err := os.Remove(buildPath); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove stale build file '%s': %w", buildPath, err)
+		}
+	}
 
-			logger.Info("Starting import process...")
-			recordsProcessed, err := store.ImportFromCSV(actualCsvPath, progressReportInterval)
-			if err != nil {
-				return fmt.Errorf("failed to import data from CSV '%s': %w", actualCsvPath, err)
-			}
-			logger.Info("Import process completed successfully.",
-				zap.Int("recordsProcessed", recordsProcessed),
-				zap.String("outputDB", actualDBPath),
-			)
-
-			logger.Info("Starting database compaction...")
-			if err := store.Compact(bbolthelper.DefaultTempDBPath); err != nil {
-				return fmt.Errorf("failed to compact database: %w", err)
-			}
-			logger.Info("Database compaction completed.")
-			logger.Info("Process completed successfully.")
-			return nil
+	logger.Info("Target database settings",
+		zap.String("dbPath", actualDBPath),
+		zap.String("buildPath", buildPath),
+		zap.String("bucketName", bucket),
+		zap.Stringer("codec", codec),
+	)
+
+	storeConfig := bbolthelper.Config{
+		DBPath:     buildPath,
+		BucketName: bucket,
+		Logger:     logger,
+		Codec:      codec,
+		// BuilderVersion is recorded in the __meta__ bucket.
+		BuilderVersion: "kvbuilder " + version,
+		// FileMode will use DefaultDBFileMode from bbolthelper
+		// ReadOnly will be false by default
+	}
+	store, err := bbolthelper.NewDBStore(storeConfig)
+	if err != nil {
+		return fmt.Errorf("failed to initialize db store: %w", err)
+	}
+
+	// NewDBStore already opens the database, so no explicit store.Open() is needed.
+	defer store.Close() // Ensure DB is closed even if subsequent steps fail
+
+	// The other dictionaries of an existing database are carried over unchanged. They are copied
+	// first so that importers can use them, e.g. to rank examples by ECDICT word frequency.
+	if _, err := os.Stat(actualDBPath); err == nil {
+		if err := store.CheckReplaceable(actualDBPath, format); err != nil {
+			return err
+		}
+		copied, err := store.CopyDictionaries(actualDBPath)
+		if err != nil {
+			return fmt.Errorf("failed to keep the other dictionaries of '%s': %w", actualDBPath, err)
+		}
+		logger.Info("Kept other dictionaries of the existing database.", zap.Strings("buckets", copied))
+	}
+
+	logger.Info("Starting import process...", zap.Bool("resume", resumeFlag))
+	recordsProcessed, err := importFn(ctx, store, bbolthelper.ImportOptions{
+		BatchSize:              batchSizeFlag,
+		ProgressReportInterval: progressReportInterval,
+		Resume:                 resumeFlag,
+		Workers:                workersFlag,
+		Manifest: bbolthelper.Manifest{
+			DisplayName: nameFlag,
+			Priority:    priorityFlag,
+			Source:      sourceFlag,
 		},
+	})
+	if errors.Is(err, context.Canceled) {
+		logger.Warn("Import interrupted; committed batches are kept. Rerun with --resume to continue.",
+			zap.Int("recordsCommitted", recordsProcessed),
+			zap.String("buildPath", buildPath))
+		return err
 	}
+	if err != nil {
+		return fmt.Errorf("failed to import data from '%s': %w", sourcePath, err)
+	}
+	logger.Info("Import process completed successfully.",
+		zap.Int("recordsProcessed", recordsProcessed),
+		zap.String("outputDB", actualDBPath),
+	)
 
-	if err := cmd.Run(context.Background(), os.Args); err != nil {
-		// Logger might not be initialized if error is from CLI parsing.
-		fmt.Fprintf(os.Stderr, "Error running kvbuilder-importer: %v\n", err)
-		os.Exit(1)
+	var indexes []string
+	for _, name := range indexFlag {
+		switch name {
+		case "none":
+		case "default":
+			indexes = append(indexes, bbolthelper.DefaultIndexes...)
+		default:
+			indexes = append(indexes, name)
+		}
+	}
+	if err := store.BuildIndexes(indexes...); err != nil {
+		return fmt.Errorf("failed to build indexes: %w", err)
+	}
+
+	// Verify, compact into a temporary file next to the target and rename it into place.
+	logger.Info("Verifying, compacting and publishing database...")
+	if err := store.PublishTo(actualDBPath); err != nil {
+		return fmt.Errorf("failed to publish database to '%s': %w", actualDBPath, err)
+	}
+	if err := store.Close(); err != nil {
+		return fmt.Errorf("failed to close build database '%s': %w", buildPath, err)
+	}
+	if err := os.Remove(buildPath); err != nil {
+		logger.Warn("Failed to remove build file", zap.String("buildPath", buildPath), zap.Error(err))
 	}
+	logger.Info("Database published.", zap.String("dbPath", actualDBPath))
+	logger.Info("Process completed successfully.")
+	return nil
 }
 
 // resolveDefaultDBPathForKvBuilder searches for the database file in PATH first.
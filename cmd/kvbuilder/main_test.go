@@ -0,0 +1,77 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/suchasplus/ne/internal/bbolthelper"
+)
+
+// writeFile writes lines to a file in dir and returns its path.
+func writeFile(t *testing.T, dir, name string, lines ...string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0644); err != nil {
+		t.Fatalf("Failed to write %s: %v", name, err)
+	}
+	return path
+}
+
+// runKvbuilder runs kvbuilder with the given arguments.
+func runKvbuilder(t *testing.T, args ...string) error {
+	t.Helper()
+	bucketNameFlag = ""
+	return newApp(zap.NewNop()).Run(t.Context(), append([]string{"kvbuilder"}, args...))
+}
+
+func TestImportSupplementKeepsECDICT(t *testing.T) {
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "ne.bbolt")
+	csvPath := writeFile(t, dir, "ecdict.csv",
+		"word,phonetic,definition,translation,pos,collins,oxford,tag,bnc,frq,exchange,detail,audio",
+		`go,gəu,move,v. 去,,5,1,,,10,p:went/d:gone,,`,
+		`went,went,,v. 去(go的过去式),,,,,,500,0:go/1:p,,`,
+	)
+	dumpPath := writeFile(t, dir, "enwiktionary-pages-articles.xml",
+		`<mediawiki><page><title>go</title><ns>0</ns><revision><text>==English==`,
+		`===Etymology===`,
+		`From Old English gān.`,
+		`</text></revision></page></mediawiki>`,
+	)
+	sentencesPath := writeFile(t, dir, "sentences.tsv", "1\teng\tI went.", "2\tcmn\t我去了。")
+	writeFile(t, dir, "links.tsv", "1\t2")
+
+	if err := runKvbuilder(t, "-d", dbPath, "--csv", csvPath); err != nil {
+		t.Fatalf("kvbuilder --csv failed: %v", err)
+	}
+	if err := runKvbuilder(t, "-d", dbPath, "import", "--format", "wiktionary", dumpPath); err != nil {
+		t.Fatalf("kvbuilder import --format wiktionary failed: %v", err)
+	}
+	if err := runKvbuilder(t, "-d", dbPath, "import", "--format", "tatoeba", sentencesPath); err != nil {
+		t.Fatalf("kvbuilder import --format tatoeba failed: %v", err)
+	}
+	// Importing a supplement into the ECDICT bucket explicitly is refused.
+	err := runKvbuilder(t, "-d", dbPath, "--dict", bbolthelper.DefaultBucketName, "import", "--format", "tatoeba", sentencesPath)
+	if err == nil || !strings.Contains(err.Error(), "imported from csv") {
+		t.Errorf("tatoeba import into %s = %v, want a refusal", bbolthelper.DefaultBucketName, err)
+	}
+
+	store, err := bbolthelper.NewDBStore(bbolthelper.Config{DBPath: dbPath, ReadOnly: true})
+	if err != nil {
+		t.Fatalf("NewDBStore() failed: %v", err)
+	}
+	defer store.Close()
+	if entry, found, err := store.GetEntry("go"); err != nil || !found || entry.Translation != "v. 去" {
+		t.Errorf("ECDICT GetEntry(go) = %+v, %v, %v; want the ECDICT entry", entry, found, err)
+	}
+	if entry, found, _ := store.Dictionary("wiktionary").GetEntry("go"); !found || entry.Etymology != "From Old English gān." {
+		t.Errorf("wiktionary GetEntry(go) = %+v, %v; want its etymology", entry, found)
+	}
+	if entry, found, _ := store.Dictionary("tatoeba").GetEntry("go"); !found || len(entry.Examples) != 1 {
+		t.Errorf("tatoeba GetEntry(go) = %+v, %v; want one example", entry, found)
+	}
+}
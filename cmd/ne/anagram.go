@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/suchasplus/ne/internal/bbolthelper"
+	"github.com/urfave/cli/v3"
+)
+
+// AnagramResult is the JSON output of `ne anagram`.
+type AnagramResult struct {
+	Letters string                   `json:"letters"`
+	Subset  bool                     `json:"subset,omitempty"`
+	Tags    []string                 `json:"tags,omitempty"`
+	Words   []bbolthelper.Completion `json:"words"`
+}
+
+// anagramCommand returns the `ne anagram` subcommand, which lists anagrams of a word or words
+// that can be built from a set of letters.
+func anagramCommand() *cli.Command {
+	var (
+		subsetFlag bool
+		tagFlag    []string
+		limitFlag  int
+		plainFlag  bool
+	)
+	return &cli.Command{
+		Name:      "anagram",
+		Usage:     "List anagrams of a word, or with --subset every word made from its letters",
+		ArgsUsage: "<letters>",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:        "subset",
+				Aliases:     []string{"s"},
+				Usage:       "List every word that can be built from the letters, not only exact anagrams",
+				Destination: &subsetFlag,
+			},
+			&cli.StringSliceFlag{
+				Name:        "tag",
+				Aliases:     []string{"t"},
+				Usage:       "Only list words with this ECDICT tag, e.g. cet4 (repeatable; all must match)",
+				Destination: &tagFlag,
+			},
+			&cli.IntFlag{
+				Name:        "limit",
+				Aliases:     []string{"n"},
+				Usage:       "Maximum number of words to list (0 for no limit)",
+				Value:       50,
+				Destination: &limitFlag,
+			},
+			&cli.BoolFlag{
+				Name:        "plain",
+				Aliases:     []string{"p"},
+				Usage:       "Print one word per line",
+				Destination: &plainFlag,
+			},
+		},
+		Action: func(ctx context.Context, cCtx *cli.Command) error {
+			logger := newLogger()
+			defer logger.Sync()
+
+			if cCtx.NArg() != 1 {
+				return cli.Exit("Expected exactly one word or set of letters", 1)
+			}
+			letters := cCtx.Args().First()
+
+			dbStore, err := openStore(logger)
+			if err != nil {
+				return err
+			}
+			defer dbStore.Close()
+
+			stores, err := searchDictionaries(dbStore, bbolthelper.IndexAnagrams)
+			if err != nil {
+				return err
+			}
+			opts := bbolthelper.AnagramOptions{Subset: subsetFlag, Tags: tagFlag, Limit: limitFlag}
+			lists := make([][]bbolthelper.Completion, len(stores))
+			for i, store := range stores {
+				if lists[i], err = store.Anagrams(letters, opts); err != nil {
+					return err
+				}
+			}
+			words := bbolthelper.MergeCompletions(lists, limitFlag, bbolthelper.PrefixOrderFrequency)
+
+			if jsonFlag {
+				if words == nil {
+					words = []bbolthelper.Completion{}
+				}
+				return printJSON(AnagramResult{Letters: letters, Subset: subsetFlag, Tags: tagFlag, Words: words})
+			}
+			printCompletions(words, plainFlag, fmt.Sprintf("No anagrams found for '%s'.", letters))
+			return nil
+		},
+	}
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/suchasplus/ne/internal/bbolthelper"
+	"github.com/urfave/cli/v3"
+)
+
+// CompleteResult is the JSON output of `ne complete`.
+type CompleteResult struct {
+	Prefix      string                   `json:"prefix"`
+	Order       string                   `json:"order"`
+	Completions []bbolthelper.Completion `json:"completions"`
+}
+
+// completeCommand returns the `ne complete` subcommand, which lists dictionary words starting with a prefix.
+func completeCommand() *cli.Command {
+	var (
+		limitFlag int
+		orderFlag string
+		plainFlag bool
+	)
+	return &cli.Command{
+		Name:      "complete",
+		Usage:     "List words starting with a prefix, for autocompletion",
+		ArgsUsage: "<prefix>",
+		Flags: []cli.Flag{
+			&cli.IntFlag{
+				Name:        "limit",
+				Aliases:     []string{"n"},
+				Usage:       "Maximum number of words to list (0 for no limit)",
+				Value:       20,
+				Destination: &limitFlag,
+			},
+			&cli.StringFlag{
+				Name:        "order",
+				Aliases:     []string{"o"},
+				Usage:       "Result order: 'frq' (most frequent first) or 'lex' (alphabetical)",
+				Value:       "frq",
+				Destination: &orderFlag,
+			},
+			&cli.BoolFlag{
+				Name:        "plain",
+				Aliases:     []string{"p"},
+				Usage:       "Print one word per line, for piping into fzf or shell widgets",
+				Destination: &plainFlag,
+			},
+		},
+		Action: func(ctx context.Context, cCtx *cli.Command) error {
+			logger := newLogger()
+			defer logger.Sync()
+
+			if cCtx.NArg() > 1 {
+				return cli.Exit("Expected at most one prefix", 1)
+			}
+			prefix := strings.ToLower(cCtx.Args().First())
+			order, err := bbolthelper.ParsePrefixOrder(orderFlag)
+			if err != nil {
+				return cli.Exit(err.Error(), 1)
+			}
+
+			dbStore, err := openStore(logger)
+			if err != nil {
+				return err
+			}
+			defer dbStore.Close()
+
+			stores, err := searchDictionaries(dbStore, "")
+			if err != nil {
+				return err
+			}
+			lists := make([][]bbolthelper.Completion, len(stores))
+			for i, store := range stores {
+				if lists[i], err = store.Prefix(prefix, limitFlag, order); err != nil {
+					return err
+				}
+			}
+			completions := bbolthelper.MergeCompletions(lists, limitFlag, order)
+
+			if jsonFlag {
+				if completions == nil {
+					completions = []bbolthelper.Completion{}
+				}
+				return printJSON(CompleteResult{Prefix: prefix, Order: order.String(), Completions: completions})
+			}
+			printCompletions(completions, plainFlag, fmt.Sprintf("No words start with '%s'.", prefix))
+			return nil
+		},
+	}
+}
+
+// printCompletions lists words with their frq as a table, or one word per line when plain is set.
+func printCompletions(completions []bbolthelper.Completion, plain bool, emptyMsg string) {
+	switch {
+	case plain:
+		for _, c := range completions {
+			fmt.Println(c.Word)
+		}
+	case len(completions) == 0:
+		fmt.Println(emptyMsg)
+	default:
+		var rowsData [][]string
+		for _, c := range completions {
+			rowsData = append(rowsData, []string{c.Word, formatInt(c.Frq)})
+		}
+		t := newKVTable()
+		t.Rows(rowsData...)
+		fmt.Println(t.Render())
+	}
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/suchasplus/ne/internal/bbolthelper"
+	"github.com/urfave/cli/v3"
+)
+
+// DescribeResult is the JSON output of `ne describe`.
+type DescribeResult struct {
+	Description string                      `json:"description"`
+	Matches     []bbolthelper.DescribeMatch `json:"matches"`
+}
+
+// describeCommand returns the `ne describe` subcommand, a reverse dictionary over English definitions.
+func describeCommand() *cli.Command {
+	var limitFlag int
+	return &cli.Command{
+		Name:      "describe",
+		Usage:     "Find words by what they mean, e.g. ne describe \"fear of heights\"",
+		ArgsUsage: "<description>",
+		Flags: []cli.Flag{
+			&cli.IntFlag{
+				Name:        "limit",
+				Aliases:     []string{"n"},
+				Usage:       "Maximum number of words to list (0 for no limit)",
+				Value:       10,
+				Destination: &limitFlag,
+			},
+		},
+		Action: func(ctx context.Context, cCtx *cli.Command) error {
+			logger := newLogger()
+			defer logger.Sync()
+
+			if cCtx.NArg() == 0 {
+				return cli.Exit("A description is required", 1)
+			}
+			description := strings.Join(cCtx.Args().Slice(), " ")
+
+			dbStore, err := openStore(logger)
+			if err != nil {
+				return err
+			}
+			defer dbStore.Close()
+
+			stores, err := searchDictionaries(dbStore, bbolthelper.IndexDefinition)
+			if err != nil {
+				return err
+			}
+			lists := make([][]bbolthelper.DescribeMatch, len(stores))
+			for i, store := range stores {
+				if lists[i], err = store.Describe(description, limitFlag); err != nil {
+					return err
+				}
+			}
+			matches := bbolthelper.MergeDescribeMatches(lists, limitFlag)
+
+			if jsonFlag {
+				if matches == nil {
+					matches = []bbolthelper.DescribeMatch{}
+				}
+				return printJSON(DescribeResult{Description: description, Matches: matches})
+			}
+			if len(matches) == 0 {
+				fmt.Printf("No words found for '%s'.\n", description)
+				return nil
+			}
+
+			var rowsData [][]string
+			for _, m := range matches {
+				rowsData = append(rowsData, []string{m.Word, m.Snippet})
+			}
+			t := newKVTable()
+			t.Rows(rowsData...)
+			fmt.Println(t.Render())
+			return nil
+		},
+	}
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/suchasplus/ne/internal/bbolthelper"
+)
+
+// DictionaryResult is the entry of a term in another dictionary of the database.
+type DictionaryResult struct {
+	Dictionary string `json:"dictionary"`
+	// Source is where the dictionary comes from, from its manifest.
+	Source string             `json:"source,omitempty"`
+	Data   *bbolthelper.Entry `json:"data"`
+
+	// supplement is set for supplementary dictionaries such as Wiktionary, see mergeSupplements.
+	supplement bool
+}
+
+// lookupOtherDictionaries looks a term up in every dictionary of the database other than the
+// store's, in priority order. With --dict the lookup is restricted to one dictionary, and it
+// returns nothing.
+func lookupOtherDictionaries(dbStore *bbolthelper.DBStore, term string) ([]DictionaryResult, error) {
+	if dictFlag != "" {
+		return nil, nil
+	}
+	dicts, err := dbStore.Dictionaries()
+	if err != nil {
+		return nil, err
+	}
+	var results []DictionaryResult
+	for _, d := range dicts {
+		if d.Bucket == dbStore.BucketName() {
+			continue
+		}
+		entry, found, err := dbStore.Dictionary(d.Bucket).GetEntry(term)
+		if err != nil {
+			return nil, fmt.Errorf("lookup in dictionary '%s' failed: %w", d.Label(), err)
+		}
+		if found {
+			results = append(results, DictionaryResult{Dictionary: d.Label(), Source: d.Source, Data: entry, supplement: d.Supplement})
+		}
+	}
+	return results, nil
+}
+
+// searchDictionaries returns the dictionaries searched beyond the term itself, as for inflections,
+// reverse lookups, suggestions and the subcommands: the store's own and, unless --dict selects one
+// dictionary, every other dictionary that is not a supplement and has the given index (any
+// dictionary, when index is empty), in priority order.
+func searchDictionaries(dbStore *bbolthelper.DBStore, index string) ([]*bbolthelper.DBStore, error) {
+	stores := []*bbolthelper.DBStore{dbStore}
+	if dictFlag != "" {
+		return stores, nil
+	}
+	dicts, err := dbStore.Dictionaries()
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range dicts {
+		if d.Bucket == dbStore.BucketName() || d.Supplement {
+			continue
+		}
+		if dict := dbStore.Dictionary(d.Bucket); index == "" || dict.HasIndex(index) {
+			stores = append(stores, dict)
+		}
+	}
+	return stores, nil
+}
+
+// mergeSupplements fills the empty fields of entry, such as its etymology, from the supplementary
+// dictionaries among others and returns the remaining results. Without an entry, supplements are
+// listed like any other dictionary.
+func mergeSupplements(entry *bbolthelper.Entry, others []DictionaryResult) []DictionaryResult {
+	if entry == nil {
+		return others
+	}
+	var rest []DictionaryResult
+	for _, other := range others {
+		if other.supplement {
+			entry.Supplement(other.Data)
+			continue
+		}
+		rest = append(rest, other)
+	}
+	return rest
+}
+
+// limitExamples keeps the first --examples example sentences of an entry.
+func limitExamples(entry *bbolthelper.Entry) {
+	if entry != nil && len(entry.Examples) > examplesFlag {
+		entry.Examples = entry.Examples[:max(examplesFlag, 0)]
+	}
+}
+
+// dictionaryLabel returns the display name of the store's dictionary.
+func dictionaryLabel(dbStore *bbolthelper.DBStore) string {
+	if meta, found, err := dbStore.Metadata(); err == nil && found {
+		return meta.Label()
+	}
+	return dbStore.BucketName()
+}
+
+// printDictionaryHeading introduces the results of one dictionary when several have results.
+func printDictionaryHeading(label string) {
+	fmt.Printf("[%s]\n", label)
+}
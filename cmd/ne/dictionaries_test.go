@@ -0,0 +1,91 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/suchasplus/ne/internal/bbolthelper"
+)
+
+// newMultiDictionaryDB returns a database holding ECDICT-like entries in the default bucket and a
+// small "medical" glossary ranked above it.
+func newMultiDictionaryDB(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "test.bbolt")
+	for _, dict := range []struct {
+		bucket   string
+		manifest bbolthelper.Manifest
+		rows     []string
+	}{
+		{bbolthelper.DefaultBucketName, bbolthelper.Manifest{}, []string{
+			`abandon,əˈbændən,v. give up completely,v. 放弃,,,,cet4 gre,,700,,,`,
+			`apple,ˈæpl,n. the round fruit of a tree,n. 苹果,,,,zk,,2000,s:apples,,`,
+			`go,gəʊ,v. move,v. 去,,,,cet4,,42,p:went/d:gone,,`,
+			`went,went,,v. 去(go的过去式),,,,,,1200,0:go/1:p,,`,
+		}},
+		{"medical", bbolthelper.Manifest{DisplayName: "Medical", Priority: 5}, []string{
+			`aspirin,,a drug that relieves pain,n. 阿司匹林,,,,,,,,,`,
+			`appendix,,a small organ attached to the intestine,n. 阑尾,,,,,,,,,`,
+		}},
+	} {
+		csvPath := filepath.Join(dir, dict.bucket+".csv")
+		content := "word,phonetic,definition,translation,pos,collins,oxford,tag,bnc,frq,exchange,detail,audio\n" +
+			strings.Join(dict.rows, "\n") + "\n"
+		if err := os.WriteFile(csvPath, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		store, err := bbolthelper.NewDBStore(bbolthelper.Config{DBPath: dbPath, BucketName: dict.bucket})
+		if err != nil {
+			t.Fatalf("NewDBStore(%s) failed: %v", dict.bucket, err)
+		}
+		if _, err := store.ImportCSV(t.Context(), csvPath, bbolthelper.ImportOptions{Manifest: dict.manifest}); err != nil {
+			t.Fatalf("ImportCSV(%s) failed: %v", dict.bucket, err)
+		}
+		if err := store.BuildIndexes(bbolthelper.DefaultIndexes...); err != nil {
+			t.Fatalf("BuildIndexes(%s) failed: %v", dict.bucket, err)
+		}
+		store.Close()
+	}
+	return dbPath
+}
+
+func TestLookupAcrossDictionaries(t *testing.T) {
+	dbPath := newMultiDictionaryDB(t)
+
+	// The inflections index of the lower-priority dictionary still finds the lemma.
+	var result JsonResult
+	if err := json.Unmarshal([]byte(runNe(t, "-d", dbPath, "--json", "went")), &result); err != nil {
+		t.Fatalf("ne --json went: %v", err)
+	}
+	if len(result.InflectionOf) != 1 || result.InflectionOf[0].Lemma != "go" || result.InflectionOf[0].Data == nil {
+		t.Errorf("ne --json went = %+v, want the past tense of go", result)
+	}
+
+	// Without --dict, queries and the other subcommands search both dictionaries.
+	if got := csvWords(runNe(t, "-d", dbPath, "query", "tag:cet4", "--format", "csv")); got != "abandon go" {
+		t.Errorf("ne query tag:cet4 listed %q, want %q", got, "abandon go")
+	}
+	if got := csvWords(runNe(t, "-d", dbPath, "--dict", "medical", "query", "tag:cet4", "--format", "csv")); got != "" {
+		t.Errorf("ne --dict medical query tag:cet4 listed %q, want nothing", got)
+	}
+	if out := runNe(t, "-d", dbPath, "match", "--plain", "ap*"); out != "appendix\napple\n" {
+		t.Errorf("ne match ap* printed %q, want the words of both dictionaries", out)
+	}
+	if out := runNe(t, "-d", dbPath, "describe", "fruit"); !strings.Contains(out, "apple") {
+		t.Errorf("ne describe fruit printed %q, want apple", out)
+	}
+}
+
+// csvWords returns the headwords of `ne query --format csv` output, separated by spaces.
+func csvWords(out string) string {
+	var words []string
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n")[1:] {
+		word, _, _ := strings.Cut(line, ",")
+		words = append(words, word)
+	}
+	return strings.Join(words, " ")
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"slices"
+
+	"github.com/suchasplus/ne/internal/bbolthelper"
+)
+
+// InflectionResult describes one lemma that a looked-up term is an inflected form of.
+type InflectionResult struct {
+	Lemma string `json:"lemma"`
+	// Types are the readable inflection types, e.g. ["past tense", "past participle"].
+	Types []string           `json:"types"`
+	Data  *bbolthelper.Entry `json:"data,omitempty"`
+}
+
+// lookupInflections returns the lemmas of word from the inflections indexes of the dictionaries,
+// grouped by lemma in priority and index order, each with its entry from the first dictionary
+// that has the lemma.
+func lookupInflections(stores []*bbolthelper.DBStore, word string) ([]InflectionResult, error) {
+	var results []InflectionResult
+	byLemma := make(map[string]int)
+	for _, store := range stores {
+		lemmas, err := store.Lemmas(word)
+		if err != nil {
+			return nil, err
+		}
+		for _, l := range lemmas {
+			i, ok := byLemma[l.Lemma]
+			if !ok {
+				i = len(results)
+				byLemma[l.Lemma] = i
+				results = append(results, InflectionResult{Lemma: l.Lemma})
+			}
+			if desc := l.Description(); !slices.Contains(results[i].Types, desc) {
+				results[i].Types = append(results[i].Types, desc)
+			}
+		}
+	}
+
+	for i := range results {
+		for _, store := range stores {
+			entry, found, err := store.GetEntry(results[i].Lemma)
+			if err != nil {
+				return nil, err
+			}
+			if found {
+				results[i].Data = entry
+				break
+			}
+		}
+	}
+	return results, nil
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/suchasplus/ne/internal/bbolthelper"
+	"github.com/urfave/cli/v3"
+)
+
+// InfoResult is the JSON output of `ne info`.
+type InfoResult struct {
+	Metadata *bbolthelper.Metadata `json:"metadata,omitempty"`
+	// Dictionaries lists every dictionary of the database, by descending priority.
+	Dictionaries []*bbolthelper.Metadata `json:"dictionaries,omitempty"`
+	Database     *bbolthelper.DBInfo     `json:"database"`
+}
+
+// infoCommand returns the `ne info` subcommand, which prints build metadata and storage statistics.
+func infoCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "info",
+		Usage: "Show how the database was built and its storage statistics",
+		Action: func(ctx context.Context, cCtx *cli.Command) error {
+			logger := newLogger()
+			defer logger.Sync()
+
+			dbStore, err := openStore(logger)
+			if err != nil {
+				return err
+			}
+			defer dbStore.Close()
+
+			meta, _, err := dbStore.Metadata()
+			if err != nil {
+				return err
+			}
+			dicts, err := dbStore.Dictionaries()
+			if err != nil {
+				return err
+			}
+			dbInfo, err := dbStore.Info()
+			if err != nil {
+				return err
+			}
+
+			if jsonFlag {
+				return printJSON(InfoResult{Metadata: meta, Dictionaries: dicts, Database: dbInfo})
+			}
+
+			var rowsData [][]string
+			if meta == nil {
+				rowsData = append(rowsData, []string{"metadata", "none (database was built without metadata)"})
+			} else {
+				rowsData = append(rowsData,
+					[]string{"dictionary", meta.Label()},
+					[]string{"bucket", meta.Bucket},
+					[]string{"priority", fmt.Sprintf("%d", meta.Priority)},
+					[]string{"origin", meta.Source},
+					[]string{"format", meta.Format},
+					[]string{"source", meta.SourceFile},
+					[]string{"source sha256", meta.SourceSHA256},
+					[]string{"records", fmt.Sprintf("%d", meta.RecordCount)},
+					[]string{"columns", strings.Join(meta.HeaderColumns, ", ")},
+					[]string{"built", meta.BuildTime.Local().Format(time.RFC3339)},
+					[]string{"builder", meta.BuilderVersion},
+					[]string{"schema", fmt.Sprintf("%d", meta.SchemaVersion)},
+					[]string{"codec", meta.Codec},
+				)
+			}
+
+			if len(dicts) > 1 {
+				var lines []string
+				for _, d := range dicts {
+					kind := ""
+					if d.Supplement {
+						kind = ", supplement"
+					}
+					records := fmt.Sprintf("%d records", d.RecordCount)
+					if d.BuildTime.IsZero() {
+						records = "no metadata"
+					}
+					lines = append(lines, fmt.Sprintf("%s (bucket %s, priority %d, %s%s)", d.Label(), d.Bucket, d.Priority, records, kind))
+				}
+				rowsData = append(rowsData, []string{"dictionaries", strings.Join(lines, "\n")})
+			}
+
+			rowsData = append(rowsData,
+				[]string{"path", dbInfo.Path},
+				[]string{"file size", formatBytes(dbInfo.FileSize)},
+				[]string{"page size", fmt.Sprintf("%d", dbInfo.PageSize)},
+				[]string{"free pages", fmt.Sprintf("%d (%s)", dbInfo.Stats.FreePageN, formatBytes(int64(dbInfo.Stats.FreeAlloc)))},
+			)
+			for _, b := range dbInfo.Buckets {
+				st := b.Stats
+				rowsData = append(rowsData, []string{"bucket " + b.Name, fmt.Sprintf(
+					"keys: %d, depth: %d\nbranch pages: %d (+%d overflow), %s of %s used\nleaf pages: %d (+%d overflow), %s of %s used",
+					st.KeyN, st.Depth,
+					st.BranchPageN, st.BranchOverflowN, formatBytes(int64(st.BranchInuse)), formatBytes(int64(st.BranchAlloc)),
+					st.LeafPageN, st.LeafOverflowN, formatBytes(int64(st.LeafInuse)), formatBytes(int64(st.LeafAlloc)),
+				)})
+			}
+
+			t := newKVTable()
+			t.Rows(rowsData...)
+			fmt.Println(t.Render())
+			return nil
+		},
+	}
+}
+
+// formatBytes renders a byte count with a binary unit suffix.
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
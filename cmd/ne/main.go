@@ -6,7 +6,9 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/charmbracelet/lipgloss"
@@ -18,24 +20,54 @@ import (
 
 // JsonResult is used for structuring the JSON output
 type JsonResult struct {
-	Term  string            `json:"term"`
-	Data  map[string]string `json:"data,omitempty"`
-	Error string            `json:"error,omitempty"`
+	Term        string             `json:"term"`
+	Data        *bbolthelper.Entry `json:"data,omitempty"`
+	Suggestions []string           `json:"suggestions,omitempty"`
+	// InflectionOf lists the lemmas the term is an inflected form of, with their entries.
+	InflectionOf []InflectionResult `json:"inflection_of,omitempty"`
+	// Dictionary names the dictionary of Data when OtherDictionaries also have the term.
+	Dictionary        string             `json:"dictionary,omitempty"`
+	OtherDictionaries []DictionaryResult `json:"other_dictionaries,omitempty"`
+	// Synonyms, Antonyms and Related are the thesaurus relations requested with flags.
+	Synonyms []bbolthelper.RelatedWord `json:"synonyms,omitempty"`
+	Antonyms []bbolthelper.RelatedWord `json:"antonyms,omitempty"`
+	Related  []bbolthelper.RelatedWord `json:"related,omitempty"`
+	Error    string                    `json:"error,omitempty"`
 }
 
-func main() {
-	// Logger will be initialized based on the verbose flag inside the Action func
+// Global flags. They are defined on the root command and inherited by every subcommand.
+var (
+	dbPathFlag     string
+	dictFlag       string
+	verboseFlag    bool
+	jsonFlag       bool
+	fullOutputFlag bool
+	debugFlag      bool
+	distanceFlag   int
+	matchesFlag    int
+	examplesFlag   int
+)
 
-	var dbPathFlag string
-	var bucketNameFlag string
-	var verboseFlag bool
-	var jsonFlag bool
-	var fullOutputFlag bool
-	var debugFlag bool
+// lookupCommandName is the subcommand that always looks its argument up, so that headwords such
+// as "info" or "match" can be looked up although they are also subcommand names.
+const lookupCommandName = "lookup"
 
-	cmd := &cli.Command{
+func main() {
+	cmd := newApp()
+	if err := cmd.Run(context.Background(), forceLookupArgs(cmd, os.Args)); err != nil {
+		// The logger might not be initialized if error occurs before Action
+		// or if the error is from cli parsing itself.
+		fmt.Fprintf(os.Stderr, "Error running command: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// newApp returns the root `ne` command. Its flags are inherited by every subcommand.
+func newApp() *cli.Command {
+	return &cli.Command{
 		Name:      "ne",
 		Usage:     "Reads a term from a bbolt key-value store using ecdict.",
+		UsageText: "ne [options] <term>\nne [options] -- <term>  (looks up terms that are also command names, e.g. ne -- match)\nne [options] <command> [command options] [arguments...]",
 		ArgsUsage: "<term>",
 		Flags: []cli.Flag{
 			&cli.BoolFlag{
@@ -61,6 +93,38 @@ func main() {
 				Usage:       "Enable fuzzy search debug statistics",
 				Destination: &debugFlag,
 			},
+			&cli.IntFlag{
+				Name:        "distance",
+				Usage:       fmt.Sprintf("Maximum edit distance of fuzzy suggestions (the symspell index answers up to %d)", bbolthelper.SymSpellMaxDistance),
+				Value:       1,
+				Destination: &distanceFlag,
+			},
+			&cli.IntFlag{
+				Name:        "matches",
+				Usage:       "Maximum number of English words listed for a Chinese term, or of words per thesaurus relation (0 for no limit)",
+				Value:       10,
+				Destination: &matchesFlag,
+			},
+			&cli.IntFlag{
+				Name:        "examples",
+				Usage:       "Show up to `N` example sentences with translations, from an imported Tatoeba export",
+				Destination: &examplesFlag,
+			},
+			&cli.BoolFlag{
+				Name:        "synonyms",
+				Usage:       "Also list synonyms from the WordNet thesaurus, with their translations",
+				Destination: &synonymsFlag,
+			},
+			&cli.BoolFlag{
+				Name:        "antonyms",
+				Usage:       "Also list antonyms from the WordNet thesaurus, with their translations",
+				Destination: &antonymsFlag,
+			},
+			&cli.BoolFlag{
+				Name:        "related",
+				Usage:       "Also list broader and similar terms from the WordNet thesaurus, with their translations",
+				Destination: &relatedFlag,
+			},
 			&cli.StringFlag{
 				Name:        "dbpath",
 				Aliases:     []string{"d"},
@@ -68,224 +132,499 @@ func main() {
 				Destination: &dbPathFlag,
 			},
 			&cli.StringFlag{
-				Name:        "bucket",
-				Aliases:     []string{"b"},
-				Usage:       fmt.Sprintf("Name of the bucket within the bbolt database. Defaults to '%s'", bbolthelper.DefaultBucketName),
-				Destination: &bucketNameFlag,
+				Name:        "dict",
+				Aliases:     []string{"bucket", "b"},
+				Usage:       "Restrict lookups to one dictionary, by bucket or display name. By default every dictionary is searched, in priority order",
+				Destination: &dictFlag,
 			},
 		},
-		Action: func(ctx context.Context, cCtx *cli.Command) error {
-			var logger *zap.Logger
-			if verboseFlag {
-				logger = zap.NewExample()
-			} else {
-				logger = zap.NewNop()
+		Commands: []*cli.Command{
+			lookupCommand(),
+			infoCommand(),
+			anagramCommand(),
+			completeCommand(),
+			describeCommand(),
+			matchCommand(),
+			queryCommand(),
+			rhymeCommand(),
+			soundsLikeCommand(),
+		},
+		Action: lookupAction,
+	}
+}
+
+// lookupAction looks up the first argument: the term itself, inflected forms, the other
+// dictionaries and, failing those, similar terms.
+func lookupAction(ctx context.Context, cCtx *cli.Command) error {
+	logger := newLogger()
+	defer logger.Sync()
+
+	if cCtx.NArg() == 0 {
+		cli.ShowAppHelpAndExit(cCtx, 1)
+		return fmt.Errorf("error: search key argument is required")
+	}
+	searchKey := strings.ToLower(cCtx.Args().First())
+
+	logger.Info("Attempting to read key from bbolt database", zap.String("key", searchKey))
+
+	dbStore, err := openStore(logger)
+	if err != nil {
+		return err
+	}
+	defer dbStore.Close()
+
+	entry, found, err := dbStore.GetEntry(searchKey)
+	if err != nil {
+		msg := "Error retrieving key"
+		if jsonFlag {
+			jsonResult := JsonResult{Term: searchKey, Error: fmt.Sprintf("%s: %v", msg, err)}
+			jsonValue, _ := json.Marshal(jsonResult)
+			fmt.Println(string(jsonValue))
+		} else {
+			fmt.Printf("%s '%s': %v\n", msg, searchKey, err)
+		}
+		logger.Error(msg, zap.String("key", searchKey), zap.Error(err))
+		return err
+	}
+
+	// An inflected form ("went") is shown together with its lemma ("go"), from any dictionary
+	// with an inflections index.
+	inflectionStores, err := searchDictionaries(dbStore, bbolthelper.IndexInflections)
+	var inflections []InflectionResult
+	if err == nil {
+		inflections, err = lookupInflections(inflectionStores, searchKey)
+	}
+	if err != nil {
+		logger.Warn("Inflection lookup failed", zap.String("key", searchKey), zap.Error(err))
+	}
+
+	// The other dictionaries of the database are listed after the selected one.
+	others, err := lookupOtherDictionaries(dbStore, searchKey)
+	if err != nil {
+		logger.Warn("Lookup in other dictionaries failed", zap.String("key", searchKey), zap.Error(err))
+	}
+	if found {
+		others = mergeSupplements(entry, others)
+	}
+	limitExamples(entry)
+	for _, other := range others {
+		limitExamples(other.Data)
+	}
+
+	// Chinese input that is not a headword of any dictionary, such as CC-CEDICT, is looked
+	// up in the translations instead.
+	if !found && len(others) == 0 && bbolthelper.ContainsCJK(searchKey) {
+		return printReverseLookup(dbStore, strings.Join(cCtx.Args().Slice(), " "), logger)
+	}
+
+	if !found && len(inflections) == 0 && len(others) == 0 {
+		// Exact match failed, try to find similar words.
+		if !jsonFlag {
+			fmt.Printf("Term '%s' not found. Searching for similar terms...\n", searchKey)
+		}
+
+		suggestions, err := findSimilar(dbStore, searchKey)
+		if err != nil {
+			// Handle error from FindSimilar itself
+			logger.Error("Fuzzy search failed", zap.Error(err))
+			fmt.Fprintf(os.Stderr, "Error during fuzzy search: %v\n", err)
+			return err
+		}
+
+		// Phonetic misspellings ("fonetik") are usually several edits away.
+		if len(suggestions) == 0 {
+			suggestions, err = soundsLikeSuggestions(dbStore, searchKey)
+			if err != nil {
+				logger.Warn("Sounds-like search failed", zap.String("key", searchKey), zap.Error(err))
 			}
-			defer logger.Sync()
+		}
 
-			if cCtx.NArg() == 0 {
-				cli.ShowAppHelpAndExit(cCtx, 1)
-				return fmt.Errorf("error: search key argument is required")
+		if len(suggestions) == 0 {
+			msg := "term not found"
+			if jsonFlag {
+				jsonResult := JsonResult{Term: searchKey, Error: msg}
+				jsonValue, _ := json.Marshal(jsonResult)
+				fmt.Println(string(jsonValue))
+			} else {
+				fmt.Printf("No similar terms found for '%s'.\n", searchKey)
 			}
-			searchKey := strings.ToLower(cCtx.Args().First())
-
-			actualDBPath := dbPathFlag
-			if actualDBPath == "" {
-				resolvedPath, err := resolveDefaultDBPathForNe(bbolthelper.DefaultDBPath)
-				if err != nil {
-					logger.Error("Failed to find database file", zap.Error(err))
-					fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-					return err // Or cli.Exit for cleaner exit code handling
+			return nil
+		}
+
+		// If we have multiple suggestions, list them and exit.
+		if len(suggestions) > 1 {
+			if jsonFlag {
+				// For JSON, we can just list the suggestions.
+				jsonResult := JsonResult{Term: searchKey, Suggestions: suggestions}
+				jsonValue, _ := json.MarshalIndent(jsonResult, "", "  ")
+				fmt.Println(string(jsonValue))
+			} else {
+				fmt.Println("Did you mean one of these?")
+				for _, s := range suggestions {
+					fmt.Printf(" - %s\n", s)
 				}
-				actualDBPath = resolvedPath
-				logger.Info("Using resolved database path", zap.String("path", actualDBPath))
 			}
+			return nil
+		}
 
-			actualBucketName := bucketNameFlag
-			if actualBucketName == "" {
-				actualBucketName = bbolthelper.DefaultBucketName
+		// If we have exactly one suggestion, proceed with it.
+		bestMatch := suggestions[0]
+		if !jsonFlag {
+			fmt.Printf("Did you mean '%s'?\n\n", bestMatch)
+		}
+
+		// Perform a lookup for the best match, which may come from another dictionary.
+		entry, found, err = dbStore.GetEntry(bestMatch)
+		if err == nil {
+			others, err = lookupOtherDictionaries(dbStore, bestMatch)
+		}
+		if found {
+			others = mergeSupplements(entry, others)
+		}
+		if err != nil || (!found && len(others) == 0) {
+			// This should be rare if FindSimilar returned it, but handle it.
+			msg := "could not retrieve suggestion"
+			if jsonFlag {
+				jsonResult := JsonResult{Term: bestMatch, Error: msg}
+				jsonValue, _ := json.Marshal(jsonResult)
+				fmt.Println(string(jsonValue))
+			} else {
+				fmt.Fprintf(os.Stderr, "Error: could not retrieve suggestion '%s'.\n", bestMatch)
 			}
+			return err
+		}
+		// Update searchKey to the one we actually found for display purposes.
+		searchKey = bestMatch
+	}
+
+	// A failed thesaurus lookup, such as in a database without WordNet, does not hide the entry.
+	relationsWord, relations, relErr := lookupRelations(dbStore, searchKey, inflections)
+	if relErr != nil {
+		logger.Warn("Thesaurus lookup failed", zap.String("key", searchKey), zap.Error(relErr))
+	}
 
-			logger.Info("Attempting to read key from bbolt database",
-				zap.String("key", searchKey),
-				zap.String("dbPath", actualDBPath),
-				zap.String("bucketName", actualBucketName),
-			)
-
-			storeConfig := bbolthelper.Config{
-				DBPath:     actualDBPath,
-				BucketName: actualBucketName,
-				FileMode:   bbolthelper.DefaultDBFileMode, // Ensure correct file mode
-				ReadOnly:   true,
-				Logger:     logger,
+	if jsonFlag {
+		jsonResult := JsonResult{Term: searchKey, Data: entry, InflectionOf: inflections, OtherDictionaries: others,
+			Synonyms: relations[bbolthelper.RelationSynonyms],
+			Antonyms: relations[bbolthelper.RelationAntonyms],
+			Related:  relations[bbolthelper.RelationRelated],
+		}
+		if found && len(others) > 0 {
+			jsonResult.Dictionary = dictionaryLabel(dbStore)
+		}
+		jsonValue, jErr := json.MarshalIndent(jsonResult, "", "  ")
+		if jErr != nil {
+			// This error is about JSON marshaling, not finding the key
+			logger.Error("Failed to marshal JSON output", zap.Error(jErr))
+			fmt.Fprintf(os.Stderr, "Error generating JSON: %v\n", jErr)
+			return jErr
+		}
+		fmt.Println(string(jsonValue))
+		if relErr != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", relErr)
+		}
+	} else {
+		if found {
+			if len(others) > 0 {
+				printDictionaryHeading(dictionaryLabel(dbStore))
 			}
-			dbStore, err := bbolthelper.NewDBStore(storeConfig)
-			if err != nil {
-				logger.Error("Failed to open database store", zap.Error(err))
-				fmt.Fprintf(os.Stderr, "Error opening database: %v\n", err)
-				return err
+			printEntryTable(searchKey, entry)
+		}
+		for _, inf := range inflections {
+			fmt.Printf("%s: %s of %s\n", searchKey, strings.Join(inf.Types, ", "), inf.Lemma)
+			if inf.Data != nil {
+				printEntryTable(inf.Lemma, inf.Data)
+			}
+		}
+		for _, other := range others {
+			printDictionaryHeading(other.Dictionary)
+			printEntryTable(searchKey, other.Data)
+		}
+		printRelations(relationsWord, relations, relErr)
+	}
+	return nil
+}
+
+// findSimilar returns the words within --distance edits of word in the dictionaries searched by
+// searchDictionaries, in priority order.
+func findSimilar(dbStore *bbolthelper.DBStore, word string) ([]string, error) {
+	stores, err := searchDictionaries(dbStore, "")
+	if err != nil {
+		return nil, err
+	}
+	var suggestions []string
+	for _, store := range stores {
+		similar, err := store.FindSimilar(word, distanceFlag)
+		if err != nil {
+			return nil, err
+		}
+		for _, w := range similar {
+			if !slices.Contains(suggestions, w) {
+				suggestions = append(suggestions, w)
 			}
-			defer dbStore.Close()
+		}
+	}
+	return suggestions, nil
+}
 
-			valueMap, found, err := dbStore.Get(searchKey)
-			if err != nil {
-				msg := "Error retrieving key"
-				if jsonFlag {
-					jsonResult := JsonResult{Term: searchKey, Error: fmt.Sprintf("%s: %v", msg, err)}
-					jsonValue, _ := json.Marshal(jsonResult)
-					fmt.Println(string(jsonValue))
-				} else {
-					fmt.Printf("%s '%s': %v\n", msg, searchKey, err)
-				}
-				logger.Error(msg, zap.String("key", searchKey), zap.Error(err))
-				return err
+// lookupCommand returns the `ne lookup` subcommand, an explicit form of `ne <term>`.
+func lookupCommand() *cli.Command {
+	return &cli.Command{
+		Name:      lookupCommandName,
+		Usage:     "Look up a term, also one that is a command name, e.g. ne lookup match",
+		ArgsUsage: "<term>",
+		Action:    lookupAction,
+	}
+}
+
+// forceLookupArgs makes "--" before any term or command name force a lookup: `ne -- match` looks
+// up "match" instead of running `ne match`. It inserts the lookup subcommand before the "--", after
+// the global flags and their values.
+func forceLookupArgs(cmd *cli.Command, args []string) []string {
+	valueFlags := make(map[string]bool)
+	for _, f := range cmd.Flags {
+		if _, ok := f.(*cli.BoolFlag); ok {
+			continue
+		}
+		for _, name := range f.Names() {
+			valueFlags[name] = true
+		}
+	}
+	for i := 1; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--":
+			forced := append(slices.Clone(args[:i]), lookupCommandName)
+			return append(forced, args[i:]...)
+		case len(arg) > 1 && arg[0] == '-':
+			name := strings.TrimLeft(arg, "-")
+			if !strings.Contains(name, "=") && valueFlags[name] {
+				i++ // Skip the flag's value.
 			}
+		default:
+			return args // A term or a command name.
+		}
+	}
+	return args
+}
 
-			if !found {
-				// Exact match failed, try to find similar words.
-				if !jsonFlag {
-					fmt.Printf("Term '%s' not found. Searching for similar terms...\n", searchKey)
-				}
+// newLogger returns a development logger when --verbose is set and a no-op logger otherwise.
+func newLogger() *zap.Logger {
+	if verboseFlag {
+		return zap.NewExample()
+	}
+	return zap.NewNop()
+}
 
-				// With the new logic, we only care about distance 1 and the callback is no longer needed.
-				suggestions, err := dbStore.FindSimilar(searchKey, 1)
-				if err != nil {
-					// Handle error from FindSimilar itself
-					logger.Error("Fuzzy search failed", zap.Error(err))
-					fmt.Fprintf(os.Stderr, "Error during fuzzy search: %v\n", err)
-					return err
-				}
+// openStore resolves the database path and bucket from the global flags and opens the store read-only.
+// Errors are reported to stderr before being returned.
+func openStore(logger *zap.Logger) (*bbolthelper.DBStore, error) {
+	actualDBPath := dbPathFlag
+	if actualDBPath == "" {
+		resolvedPath, err := resolveDefaultDBPathForNe(bbolthelper.DefaultDBPath)
+		if err != nil {
+			logger.Error("Failed to find database file", zap.Error(err))
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return nil, err // Or cli.Exit for cleaner exit code handling
+		}
+		actualDBPath = resolvedPath
+		logger.Info("Using resolved database path", zap.String("path", actualDBPath))
+	}
 
-				if len(suggestions) == 0 {
-					msg := "term not found"
-					if jsonFlag {
-						jsonResult := JsonResult{Term: searchKey, Error: msg}
-						jsonValue, _ := json.Marshal(jsonResult)
-						fmt.Println(string(jsonValue))
-					} else {
-						fmt.Printf("No similar terms found for '%s'.\n", searchKey)
-					}
-					return nil
-				}
+	actualBucketName := dictFlag
+	if actualBucketName == "" {
+		actualBucketName = bbolthelper.DefaultBucketName
+	}
 
-				// If we have multiple suggestions, list them and exit.
-				if len(suggestions) > 1 {
-					if jsonFlag {
-						// For JSON, we can just list the suggestions.
-						jsonResult := JsonResult{Term: searchKey, Data: map[string]string{"suggestions": strings.Join(suggestions, ", ")}}
-						jsonValue, _ := json.MarshalIndent(jsonResult, "", "  ")
-						fmt.Println(string(jsonValue))
-					} else {
-						fmt.Println("Did you mean one of these?")
-						for _, s := range suggestions {
-							fmt.Printf(" - %s\n", s)
-						}
-					}
-					return nil
-				}
+	logger.Info("Opening bbolt database",
+		zap.String("dbPath", actualDBPath),
+		zap.String("bucketName", actualBucketName),
+	)
 
-				// If we have exactly one suggestion, proceed with it.
-				bestMatch := suggestions[0]
-				if !jsonFlag {
-					fmt.Printf("Did you mean '%s'?\n\n", bestMatch)
-				}
+	storeConfig := bbolthelper.Config{
+		DBPath:     actualDBPath,
+		BucketName: actualBucketName,
+		FileMode:   bbolthelper.DefaultDBFileMode, // Ensure correct file mode
+		ReadOnly:   true,
+		Logger:     logger,
+	}
+	dbStore, err := bbolthelper.NewDBStore(storeConfig)
+	if err != nil {
+		logger.Error("Failed to open database store", zap.Error(err))
+		fmt.Fprintf(os.Stderr, "Error opening database: %v\n", err)
+		return nil, err
+	}
 
-				// Perform a lookup for the best match.
-				valueMap, found, err = dbStore.Get(bestMatch)
-				if err != nil || !found {
-					// This should be rare if FindSimilar returned it, but handle it.
-					msg := "could not retrieve suggestion"
-					if jsonFlag {
-						jsonResult := JsonResult{Term: bestMatch, Error: msg}
-						jsonValue, _ := json.Marshal(jsonResult)
-						fmt.Println(string(jsonValue))
-					} else {
-						fmt.Fprintf(os.Stderr, "Error: could not retrieve suggestion '%s'.\n", bestMatch)
-					}
-					return err
-				}
-				// Update searchKey to the one we actually found for display purposes.
-				searchKey = bestMatch
+	// --dict also accepts display names; without it the highest-priority dictionary that is not a
+	// supplement is the primary one, searched first (see searchDictionaries). Buckets built without
+	// metadata count as priority 0, behind the default bucket.
+	var meta *bbolthelper.Metadata
+	if dictFlag != "" {
+		meta, _, err = dbStore.FindDictionary(dictFlag)
+	} else if dicts, dErr := dbStore.Dictionaries(); dErr != nil {
+		err = dErr
+	} else if len(dicts) > 0 {
+		meta = dicts[0]
+		for _, d := range dicts {
+			if !d.Supplement {
+				meta = d
+				break
 			}
+		}
+	}
+	if err != nil {
+		dbStore.Close()
+		logger.Error("Failed to read dictionary manifests", zap.Error(err))
+		fmt.Fprintf(os.Stderr, "Error reading dictionaries: %v\n", err)
+		return nil, err
+	}
+	if meta != nil && meta.Bucket != dbStore.BucketName() {
+		logger.Info("Using dictionary", zap.String("bucketName", meta.Bucket), zap.String("name", meta.Label()))
+		dbStore = dbStore.Dictionary(meta.Bucket)
+	}
+	return dbStore, nil
+}
 
-			if jsonFlag {
-				jsonResult := JsonResult{Term: searchKey, Data: valueMap}
-				jsonValue, jErr := json.MarshalIndent(jsonResult, "", "  ")
-				if jErr != nil {
-					// This error is about JSON marshaling, not finding the key
-					logger.Error("Failed to marshal JSON output", zap.Error(jErr))
-					fmt.Fprintf(os.Stderr, "Error generating JSON: %v\n", jErr)
-					return jErr
-				}
-				fmt.Println(string(jsonValue))
-			} else {
-				// 2-column table output using lipgloss/table
-				const keyColumnWidth = 15
-				const valueColumnWidth = 60 // Adjusted for table borders/padding
-
-				t := table.New().
-					BorderBottom(true).
-					BorderRow(true).
-					Width(keyColumnWidth + valueColumnWidth + 3). // Total width approx
-					Border(lipgloss.NormalBorder()).              // Use double-line border
-					StyleFunc(func(row, col int) lipgloss.Style {
-						// Basic padding for cells
-						style := lipgloss.NewStyle().Padding(0, 1)
-						// The table's Border will handle line drawing.
-						// We can apply specific styles for headers or other special cells if needed.
-						if col == 0 { // Key column
-							return style.Width(keyColumnWidth)
-						}
-						return style.Width(valueColumnWidth) // Value column
-					})
-
-				var rowsData [][]string
-				// Prepare data for table
-				rowsData = append(rowsData, []string{"term", searchKey})
-
-				displayFields := []string{"translation", "definition", "exchange"}
-				if fullOutputFlag {
-					// Collect all keys from valueMap and sort them for consistent order
-					allKeys := make([]string, 0, len(valueMap))
-					for k := range valueMap {
-						if k != "term" { // Exclude term if already added, though it's not typically in valueMap here
-							allKeys = append(allKeys, k)
-						}
-					}
-					sort.Strings(allKeys) // Sort for consistent output
-					displayFields = allKeys
-				}
+// newKVTable returns the 2-column lipgloss table used for key/value output.
+func newKVTable() *table.Table {
+	const keyColumnWidth = 15
+	const valueColumnWidth = 60 // Adjusted for table borders/padding
 
-				for _, fieldKey := range displayFields {
-					if val, ok := valueMap[fieldKey]; ok {
-						processedVal := strings.ReplaceAll(val, "\\n", "\n")
-						processedVal = strings.ReplaceAll(processedVal, "\\r", "\r") // Ensure \r is also processed
-						processedVal = strings.ReplaceAll(processedVal, "\\t", "\t")
-						// Only add to rowsData if the processed value is not empty after trimming whitespace
-						if strings.TrimSpace(processedVal) != "" {
-							rowsData = append(rowsData, []string{fieldKey, processedVal})
-						}
-					}
-				}
+	return table.New().
+		BorderBottom(true).
+		BorderRow(true).
+		Width(keyColumnWidth + valueColumnWidth + 3). // Total width approx
+		Border(lipgloss.NormalBorder()).              // Use double-line border
+		StyleFunc(func(row, col int) lipgloss.Style {
+			// Basic padding for cells
+			style := lipgloss.NewStyle().Padding(0, 1)
+			// The table's Border will handle line drawing.
+			// We can apply specific styles for headers or other special cells if needed.
+			if col == 0 { // Key column
+				return style.Width(keyColumnWidth)
+			}
+			return style.Width(valueColumnWidth) // Value column
+		})
+}
 
-				t.Rows(rowsData...)
+// printJSON writes v as indented JSON to stdout.
+func printJSON(v any) error {
+	jsonValue, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error generating JSON: %v\n", err)
+		return err
+	}
+	fmt.Println(string(jsonValue))
+	return nil
+}
 
-				if len(rowsData) > 0 {
-					fmt.Println(t.Render())
-				} else {
-					fmt.Println("No data to display for term after filtering.")
-				}
+// printEntryTable renders an entry as a 2-column table.
+func printEntryTable(term string, entry *bbolthelper.Entry) {
+	t := newKVTable()
+
+	var rowsData [][]string
+	// Prepare data for table
+	rowsData = append(rowsData, []string{"term", term})
+
+	rowsData = append(rowsData, entryRows(entry, fullOutputFlag)...)
+
+	t.Rows(rowsData...)
+
+	if len(rowsData) > 0 {
+		fmt.Println(t.Render())
+	} else {
+		fmt.Println("No data to display for term after filtering.")
+	}
+}
+
+// entryRows builds the field rows of the table output for an entry.
+// By default only pinyin, translation, definition and exchange are shown; full mode shows every
+// non-empty field sorted by name.
+func entryRows(entry *bbolthelper.Entry, full bool) [][]string {
+	fields := map[string]string{
+		bbolthelper.FieldPinyin:      entry.Pinyin,
+		bbolthelper.FieldTranslation: entry.Translation,
+		bbolthelper.FieldDefinition:  entry.Definition,
+		bbolthelper.FieldExchange:    formatExchange(entry.Exchange),
+	}
+	displayFields := []string{bbolthelper.FieldPinyin, bbolthelper.FieldTranslation, bbolthelper.FieldDefinition, bbolthelper.FieldExchange}
+
+	if full {
+		fields[bbolthelper.FieldPhonetic] = entry.Phonetic
+		fields[bbolthelper.FieldPOS] = formatPOS(entry.POS)
+		fields[bbolthelper.FieldCollins] = formatInt(entry.Collins)
+		fields[bbolthelper.FieldTag] = strings.Join(entry.Tags, " ")
+		fields[bbolthelper.FieldBNC] = formatInt(entry.BNC)
+		fields[bbolthelper.FieldFrq] = formatInt(entry.Frq)
+		fields[bbolthelper.FieldDetail] = entry.Detail
+		fields[bbolthelper.FieldAudio] = entry.Audio
+		fields[bbolthelper.FieldEtymology] = entry.Etymology
+		if entry.Oxford {
+			fields[bbolthelper.FieldOxford] = "yes"
+		}
+		for k, v := range entry.Extra {
+			fields[k] = v
+		}
+
+		// Collect all keys and sort them for consistent order
+		displayFields = make([]string, 0, len(fields))
+		for k := range fields {
+			displayFields = append(displayFields, k)
+		}
+		sort.Strings(displayFields)
+	}
+
+	var rows [][]string
+	for _, fieldKey := range displayFields {
+		// Only add rows whose value is not empty after trimming whitespace
+		if val := fields[fieldKey]; strings.TrimSpace(val) != "" {
+			rows = append(rows, []string{fieldKey, val})
+		}
+	}
+	// Examples are limited by --examples and shown in both modes.
+	for _, ex := range entry.Examples {
+		rows = append(rows, []string{"example", strings.TrimSpace(ex.Sentence + "\n" + ex.Translation)})
+	}
+	return rows
+}
+
+// formatExchange renders an exchange map as one "type: form" line per inflection, and the lemma
+// of an inflected form as one line such as "past tense, past participle of leave".
+func formatExchange(ex map[string]string) string {
+	var lines []string
+	for _, part := range strings.Split(bbolthelper.FormatExchange(ex), "/") {
+		code, form, ok := strings.Cut(part, ":")
+		switch {
+		case !ok || code == "1":
+			// Code 1 lists the inflections of the lemma under code 0.
+		case code == "0":
+			if lemma, types := bbolthelper.ExchangeLemma(ex); len(types) > 0 {
+				lines = append(lines, fmt.Sprintf("%s of %s", strings.Join(types, ", "), lemma))
+			} else {
+				lines = append(lines, "lemma: "+lemma)
 			}
-			return nil
-		},
+		default:
+			lines = append(lines, fmt.Sprintf("%s: %s", bbolthelper.ExchangeTypeName(code), form))
+		}
 	}
+	return strings.Join(lines, "\n")
+}
 
-	if err := cmd.Run(context.Background(), os.Args); err != nil {
-		// The logger might not be initialized if error occurs before Action
-		// or if the error is from cli parsing itself.
-		fmt.Fprintf(os.Stderr, "Error running command: %v\n", err)
-		os.Exit(1)
+// formatPOS renders a POS distribution as "v 54%, n 46%".
+func formatPOS(shares []bbolthelper.POSShare) string {
+	parts := make([]string, len(shares))
+	for i, p := range shares {
+		parts[i] = fmt.Sprintf("%s %d%%", p.Tag, p.Percent)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// formatInt renders a numeric field, treating 0 as empty.
+func formatInt(n int) string {
+	if n == 0 {
+		return ""
 	}
+	return strconv.Itoa(n)
 }
 
 // resolveDefaultDBPathForNe searches for the database file in standard locations.
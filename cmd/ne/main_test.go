@@ -0,0 +1,119 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/suchasplus/ne/internal/bbolthelper"
+)
+
+func TestForceLookupArgs(t *testing.T) {
+	cmd := newApp()
+	tests := []struct {
+		args, want []string
+	}{
+		{[]string{"ne", "--", "info"}, []string{"ne", "lookup", "--", "info"}},
+		{[]string{"ne", "-d", "x.bbolt", "--json", "--", "match"}, []string{"ne", "-d", "x.bbolt", "--json", "lookup", "--", "match"}},
+		{[]string{"ne", "--dbpath=x.bbolt", "--", "rhyme"}, []string{"ne", "--dbpath=x.bbolt", "lookup", "--", "rhyme"}},
+		{[]string{"ne", "info"}, []string{"ne", "info"}},
+		{[]string{"ne", "go"}, []string{"ne", "go"}},
+		{[]string{"ne", "match", "--", "-x*"}, []string{"ne", "match", "--", "-x*"}},
+		{[]string{"ne"}, []string{"ne"}},
+	}
+	for _, tc := range tests {
+		if got := forceLookupArgs(cmd, tc.args); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("forceLookupArgs(%q) = %q, want %q", tc.args, got, tc.want)
+		}
+	}
+}
+
+// runNe runs ne with the given arguments and returns what it printed to stdout.
+func runNe(t *testing.T, args ...string) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	cmd := newApp()
+	runErr := cmd.Run(t.Context(), forceLookupArgs(cmd, append([]string{"ne"}, args...)))
+	w.Close()
+	out, _ := io.ReadAll(r)
+	if runErr != nil {
+		t.Fatalf("ne %q failed: %v", args, runErr)
+	}
+	return string(out)
+}
+
+func TestLookupOfCommandNames(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "test.bbolt")
+	store, err := bbolthelper.NewDBStore(bbolthelper.Config{DBPath: dbPath})
+	if err != nil {
+		t.Fatalf("NewDBStore() failed: %v", err)
+	}
+	for _, word := range []string{"info", "match", "lookup"} {
+		if err := store.PutEntry(&bbolthelper.Entry{Word: word, Translation: "n. " + word}); err != nil {
+			t.Fatalf("PutEntry(%q) failed: %v", word, err)
+		}
+	}
+	store.Close()
+
+	for _, args := range [][]string{
+		{"--json", "-d", dbPath, "--", "info"},
+		{"--json", "-d", dbPath, "--", "match"},
+		{"--json", "-d", dbPath, "--", "lookup"},
+		{"--json", "-d", dbPath, "lookup", "match"},
+	} {
+		var result JsonResult
+		out := runNe(t, args...)
+		if err := json.Unmarshal([]byte(out), &result); err != nil {
+			t.Errorf("ne %q printed %q, want a JSON lookup result", args, out)
+			continue
+		}
+		word := args[len(args)-1]
+		if result.Term != word || result.Data == nil || result.Data.Translation != "n. "+word {
+			t.Errorf("ne %q = %+v, want the entry of %q", args, result, word)
+		}
+	}
+}
+
+func TestLookupWithoutThesaurus(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "test.bbolt")
+	store, err := bbolthelper.NewDBStore(bbolthelper.Config{DBPath: dbPath})
+	if err != nil {
+		t.Fatalf("NewDBStore() failed: %v", err)
+	}
+	if err := store.PutEntry(&bbolthelper.Entry{Word: "go", Translation: "v. 去"}); err != nil {
+		t.Fatalf("PutEntry(go) failed: %v", err)
+	}
+	store.Close()
+
+	out := runNe(t, "-d", dbPath, "--synonyms", "go")
+	if !strings.Contains(out, "v. 去") || !strings.Contains(out, "Warning: the database has no thesaurus") {
+		t.Errorf("ne --synonyms go printed %q, want the entry and a warning", out)
+	}
+}
+
+func TestFormatExchange(t *testing.T) {
+	tests := []struct {
+		exchange, want string
+	}{
+		{"p:went/d:gone/3:goes", "past tense: went\npast participle: gone\nthird person singular: goes"},
+		{"0:go/1:p", "past tense of go"},
+		{"0:leave/1:pd", "past tense, past participle of leave"},
+		{"0:data", "lemma: data"},
+	}
+	for _, tc := range tests {
+		if got := formatExchange(bbolthelper.ParseExchange(tc.exchange)); got != tc.want {
+			t.Errorf("formatExchange(%q) = %q, want %q", tc.exchange, got, tc.want)
+		}
+	}
+}
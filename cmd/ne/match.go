@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/suchasplus/ne/internal/bbolthelper"
+	"github.com/urfave/cli/v3"
+)
+
+// MatchResult is the JSON output of `ne match`.
+type MatchResult struct {
+	Pattern string                   `json:"pattern"`
+	Regex   bool                     `json:"regex,omitempty"`
+	Order   string                   `json:"order"`
+	Matches []bbolthelper.Completion `json:"matches"`
+}
+
+// matchCommand returns the `ne match` subcommand, which lists words matching a wildcard or regex pattern.
+func matchCommand() *cli.Command {
+	var (
+		regexFlag bool
+		lenFlag   int
+		limitFlag int
+		orderFlag string
+		plainFlag bool
+	)
+	return &cli.Command{
+		Name:      "match",
+		Usage:     "List words matching a pattern: '?' is one letter, '*' any letters (e.g. 'c?t', 'pre*tion')",
+		ArgsUsage: "<pattern>",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:        "regex",
+				Aliases:     []string{"r"},
+				Usage:       "Treat the pattern as a regular expression, e.g. '^un.+able$'",
+				Destination: &regexFlag,
+			},
+			&cli.IntFlag{
+				Name:        "len",
+				Aliases:     []string{"l"},
+				Usage:       "Only list words of exactly this many letters",
+				Destination: &lenFlag,
+			},
+			&cli.IntFlag{
+				Name:        "limit",
+				Aliases:     []string{"n"},
+				Usage:       "Maximum number of words to list (0 for no limit)",
+				Value:       50,
+				Destination: &limitFlag,
+			},
+			&cli.StringFlag{
+				Name:        "order",
+				Aliases:     []string{"o"},
+				Usage:       "Result order: 'lex' (alphabetical) or 'frq' (most frequent first)",
+				Value:       "lex",
+				Destination: &orderFlag,
+			},
+			&cli.BoolFlag{
+				Name:        "plain",
+				Aliases:     []string{"p"},
+				Usage:       "Print one word per line",
+				Destination: &plainFlag,
+			},
+		},
+		Action: func(ctx context.Context, cCtx *cli.Command) error {
+			logger := newLogger()
+			defer logger.Sync()
+
+			if cCtx.NArg() != 1 {
+				return cli.Exit("Expected exactly one pattern", 1)
+			}
+			pattern := cCtx.Args().First()
+			order, err := bbolthelper.ParsePrefixOrder(orderFlag)
+			if err != nil {
+				return cli.Exit(err.Error(), 1)
+			}
+
+			dbStore, err := openStore(logger)
+			if err != nil {
+				return err
+			}
+			defer dbStore.Close()
+
+			stores, err := searchDictionaries(dbStore, "")
+			if err != nil {
+				return err
+			}
+			opts := bbolthelper.MatchOptions{Regex: regexFlag, Length: lenFlag, Limit: limitFlag, Order: order}
+			lists := make([][]bbolthelper.Completion, len(stores))
+			for i, store := range stores {
+				if lists[i], err = store.Match(pattern, opts); err != nil {
+					return err
+				}
+			}
+			matches := bbolthelper.MergeCompletions(lists, limitFlag, order)
+
+			if jsonFlag {
+				if matches == nil {
+					matches = []bbolthelper.Completion{}
+				}
+				return printJSON(MatchResult{Pattern: pattern, Regex: regexFlag, Order: order.String(), Matches: matches})
+			}
+			printCompletions(matches, plainFlag, fmt.Sprintf("No words match '%s'.", pattern))
+			return nil
+		},
+	}
+}
@@ -0,0 +1,254 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/lipgloss/table"
+	"github.com/suchasplus/ne/internal/bbolthelper"
+	"github.com/urfave/cli/v3"
+)
+
+// queryCSVColumns are the columns of `ne query --format csv`, in ECDICT order, so that the
+// output can be imported again with kvbuilder.
+var queryCSVColumns = []string{
+	"word",
+	bbolthelper.FieldPhonetic,
+	bbolthelper.FieldDefinition,
+	bbolthelper.FieldTranslation,
+	bbolthelper.FieldPOS,
+	bbolthelper.FieldCollins,
+	bbolthelper.FieldOxford,
+	bbolthelper.FieldTag,
+	bbolthelper.FieldBNC,
+	bbolthelper.FieldFrq,
+	bbolthelper.FieldExchange,
+	bbolthelper.FieldDetail,
+	bbolthelper.FieldAudio,
+}
+
+// queryTranslationMaxRunes bounds the translation column of the table output.
+const queryTranslationMaxRunes = 30
+
+// QueryOutput is the JSON output of `ne query`.
+type QueryOutput struct {
+	Query   string               `json:"query"`
+	Plan    string               `json:"plan"`
+	Count   int                  `json:"count"`
+	Entries []*bbolthelper.Entry `json:"entries"`
+}
+
+// queryCommand returns the `ne query` subcommand, which lists the words matching a structured query.
+func queryCommand() *cli.Command {
+	var (
+		formatFlag  string
+		explainFlag bool
+	)
+	return &cli.Command{
+		Name:      "query",
+		Usage:     "List words matching a query, e.g. 'tag:gre collins>=3 len:6..8 pos:v sort:frq limit:100'",
+		ArgsUsage: "<query>",
+		Description: `A query is a list of space-separated terms, all of which must hold:
+
+   word:<pattern>            headword wildcard pattern, '?' for one letter and '*' for any
+   tag:<tag>                 ECDICT tag: zk gk cet4 cet6 ky toefl ielts gre
+   pos:<pos>                 part of speech: n v adj adv prep conj pron ...
+   len, collins, bnc, frq    field:n, field:lo..hi (either bound optional), or >=, <=, >, <
+   oxford:<true|false>       Oxford 3000 membership
+   sort:<field>              order by word (default), len, collins, oxford, bnc or frq; sort:-field descends
+   limit:<n>                 maximum number of words
+
+A leading '-' negates a term, e.g. -tag:cet4. Words without a bnc or frq rank never match a
+condition on it. Options may come before or after the terms.`,
+		// Flags are parsed by parseQueryArgs, so that negated terms are not taken for flags.
+		SkipFlagParsing: true,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:        "format",
+				Aliases:     []string{"f"},
+				Usage:       "Output format: table, json or csv (--json implies json)",
+				Value:       "table",
+				Destination: &formatFlag,
+			},
+			&cli.BoolFlag{
+				Name:        "explain",
+				Usage:       "Print how the query is answered (index or scan) to stderr",
+				Destination: &explainFlag,
+			},
+		},
+		Action: func(ctx context.Context, cCtx *cli.Command) error {
+			logger := newLogger()
+			defer logger.Sync()
+
+			terms, help, err := parseQueryArgs(cCtx, cCtx.Args().Slice())
+			if err != nil {
+				return cli.Exit(err.Error(), 1)
+			}
+			if help {
+				return cli.ShowCommandHelp(ctx, cCtx.Lineage()[1], cCtx.Name)
+			}
+			if len(terms) == 0 {
+				return cli.Exit("Expected a query", 1)
+			}
+			input := strings.Join(terms, " ")
+			if jsonFlag {
+				formatFlag = "json"
+			}
+			if formatFlag != "table" && formatFlag != "json" && formatFlag != "csv" {
+				return cli.Exit(fmt.Sprintf("Unknown format '%s': expected table, json or csv", formatFlag), 1)
+			}
+			q, err := bbolthelper.ParseQuery(input)
+			if err != nil {
+				return cli.Exit(err.Error(), 1)
+			}
+
+			dbStore, err := openStore(logger)
+			if err != nil {
+				return err
+			}
+			defer dbStore.Close()
+
+			stores, err := searchDictionaries(dbStore, bbolthelper.IndexAttributes)
+			if err != nil {
+				return err
+			}
+			results := make([]*bbolthelper.QueryResult, len(stores))
+			for i, store := range stores {
+				if results[i], err = store.Query(q); err != nil {
+					return err
+				}
+				if len(stores) > 1 {
+					results[i].Plan = dictionaryLabel(store) + ": " + results[i].Plan
+				}
+			}
+			res := bbolthelper.MergeQueryResults(q, results)
+			if explainFlag {
+				fmt.Fprintf(os.Stderr, "Plan: %s\n", res.Plan)
+			}
+
+			switch formatFlag {
+			case "json":
+				entries := res.Entries
+				if entries == nil {
+					entries = []*bbolthelper.Entry{}
+				}
+				return printJSON(QueryOutput{Query: input, Plan: res.Plan, Count: len(entries), Entries: entries})
+			case "csv":
+				return printQueryCSV(res.Entries)
+			}
+			if len(res.Entries) == 0 {
+				fmt.Printf("No words match '%s'.\n", input)
+				return nil
+			}
+			printQueryTable(res.Entries)
+			return nil
+		},
+	}
+}
+
+// parseQueryArgs separates the flags in the arguments of `ne query`, which it sets, from the
+// query terms. Query terms have a field name followed by ':' or a comparison, so a negated term
+// such as -tag:cet4 is never the name of a flag. Arguments after "--" are all terms.
+func parseQueryArgs(cmd *cli.Command, args []string) (terms []string, help bool, err error) {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			return append(terms, args[i+1:]...), false, nil
+		}
+		if len(arg) < 2 || arg[0] != '-' {
+			terms = append(terms, arg)
+			continue
+		}
+		name, value, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if name == "h" || name == "help" {
+			return nil, true, nil
+		}
+		flag := lookupFlag(cmd, name)
+		if flag == nil {
+			terms = append(terms, arg)
+			continue
+		}
+		if _, isBool := flag.(*cli.BoolFlag); isBool && !hasValue {
+			value = "true"
+		} else if !hasValue {
+			if i+1 == len(args) {
+				return nil, false, fmt.Errorf("flag needs an argument: %s", arg)
+			}
+			i++
+			value = args[i]
+		}
+		if err := cmd.Set(name, value); err != nil {
+			return nil, false, fmt.Errorf("invalid value '%s' for flag %s: %w", value, arg, err)
+		}
+	}
+	return terms, false, nil
+}
+
+// lookupFlag returns the flag of cmd or of one of its parents with the given name, or nil.
+func lookupFlag(cmd *cli.Command, name string) cli.Flag {
+	for _, c := range cmd.Lineage() {
+		for _, f := range c.Flags {
+			if slices.Contains(f.Names(), name) {
+				return f
+			}
+		}
+	}
+	return nil
+}
+
+// printQueryTable renders one row per entry with the fields a query filters on.
+func printQueryTable(entries []*bbolthelper.Entry) {
+	var rowsData [][]string
+	for _, e := range entries {
+		oxford := ""
+		if e.Oxford {
+			oxford = "yes"
+		}
+		translation, _, _ := strings.Cut(e.Translation, "\n")
+		if runes := []rune(translation); len(runes) > queryTranslationMaxRunes {
+			translation = string(runes[:queryTranslationMaxRunes-1]) + "…"
+		}
+		rowsData = append(rowsData, []string{
+			e.Word, formatPOS(e.POS), formatInt(e.Collins), oxford, strings.Join(e.Tags, " "), formatInt(e.Frq), translation,
+		})
+	}
+	t := table.New().
+		Border(lipgloss.NormalBorder()).
+		Headers("word", "pos", "collins", "oxford", "tags", "frq", "translation").
+		StyleFunc(func(row, col int) lipgloss.Style {
+			return lipgloss.NewStyle().Padding(0, 1)
+		}).
+		Rows(rowsData...)
+	fmt.Println(t.Render())
+	if len(entries) == 1 {
+		fmt.Println("1 word")
+	} else {
+		fmt.Printf("%d words\n", len(entries))
+	}
+}
+
+// printQueryCSV writes entries to stdout as ECDICT CSV, with a header row.
+func printQueryCSV(entries []*bbolthelper.Entry) error {
+	w := csv.NewWriter(os.Stdout)
+	if err := w.Write(queryCSVColumns); err != nil {
+		return err
+	}
+	for _, e := range entries {
+		fields := e.Map()
+		record := make([]string, len(queryCSVColumns))
+		record[0] = e.Word
+		for i, col := range queryCSVColumns[1:] {
+			record[i+1] = fields[col]
+		}
+		if err := w.Write(record); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/suchasplus/ne/internal/bbolthelper"
+)
+
+func TestQueryCommandNegation(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "test.bbolt")
+	store, err := bbolthelper.NewDBStore(bbolthelper.Config{DBPath: dbPath})
+	if err != nil {
+		t.Fatalf("NewDBStore() failed: %v", err)
+	}
+	for _, e := range []*bbolthelper.Entry{
+		{Word: "abandon", Tags: []string{"cet4", "gre"}, Frq: 700},
+		{Word: "abate", Tags: []string{"gre"}, Frq: 900},
+	} {
+		if err := store.PutEntry(e); err != nil {
+			t.Fatalf("PutEntry(%q) failed: %v", e.Word, err)
+		}
+	}
+	store.Close()
+
+	// Negated terms are not flags, and flags may come before or after the terms.
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"-tag:cet4", "--format", "csv"}, "abate"},
+		{[]string{"--format=csv", "-tag:cet4", "tag:gre"}, "abate"},
+		{[]string{"-f", "csv", "--", "-tag:gre"}, ""},
+	}
+	for _, tc := range tests {
+		args := append([]string{"-d", dbPath, "query"}, tc.args...)
+		out := runNe(t, args...)
+		want := "word,phonetic,definition,translation,pos,collins,oxford,tag,bnc,frq,exchange,detail,audio\n"
+		if tc.want != "" {
+			want += tc.want + ",,,,,,,gre,,900,,,\n"
+		}
+		if out != want {
+			t.Errorf("ne %q printed %q, want %q", args, out, want)
+		}
+	}
+	if out := runNe(t, "-d", dbPath, "query", "-tag:cet4"); !strings.HasSuffix(out, "\n1 word\n") {
+		t.Errorf("ne query -tag:cet4 printed %q, want a count of 1 word", out)
+	}
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/suchasplus/ne/internal/bbolthelper"
+)
+
+// Thesaurus flags of the root command.
+var (
+	synonymsFlag bool
+	antonymsFlag bool
+	relatedFlag  bool
+)
+
+// relationLabels names the thesaurus relations in table output.
+var relationLabels = map[string]string{
+	bbolthelper.RelationSynonyms: "synonyms",
+	bbolthelper.RelationAntonyms: "antonyms",
+	bbolthelper.RelationRelated:  "related terms",
+}
+
+// requestedRelations returns the thesaurus relations selected by --synonyms, --antonyms and --related.
+func requestedRelations() []string {
+	var relations []string
+	for i, on := range []bool{synonymsFlag, antonymsFlag, relatedFlag} {
+		if on {
+			relations = append(relations, bbolthelper.ThesaurusRelations[i])
+		}
+	}
+	return relations
+}
+
+// lookupRelations returns the requested thesaurus relations of a term, each word cross-linked to
+// its translation in dbStore. A term without relations falls back to its lemma, so that "went"
+// lists the synonyms of "go". It returns the word whose relations were found.
+func lookupRelations(dbStore *bbolthelper.DBStore, term string, inflections []InflectionResult) (string, map[string][]bbolthelper.RelatedWord, error) {
+	relations := requestedRelations()
+	if len(relations) == 0 {
+		return term, nil, nil
+	}
+	thesaurus, found, err := dbStore.Thesaurus()
+	if err != nil {
+		return term, nil, err
+	}
+	if !found {
+		return term, nil, fmt.Errorf("the database has no thesaurus; import WordNet with `kvbuilder import --format wordnet --dict wordnet <dir>`")
+	}
+
+	words := []string{term}
+	for _, inf := range inflections {
+		words = append(words, inf.Lemma)
+	}
+	for _, word := range words {
+		results := make(map[string][]bbolthelper.RelatedWord)
+		for _, rel := range relations {
+			related, err := thesaurus.Related(word, rel, dbStore, matchesFlag)
+			if err != nil {
+				return word, nil, err
+			}
+			if len(related) > 0 {
+				results[rel] = related
+			}
+		}
+		if len(results) > 0 {
+			return word, results, nil
+		}
+	}
+	return term, nil, nil
+}
+
+// printRelations prints one table per requested relation, listing each word with its translation,
+// or a warning when the relations could not be looked up.
+func printRelations(word string, results map[string][]bbolthelper.RelatedWord, err error) {
+	if err != nil {
+		fmt.Printf("Warning: %v\n", err)
+		return
+	}
+	for _, rel := range requestedRelations() {
+		related := results[rel]
+		if len(related) == 0 {
+			fmt.Printf("No %s found for '%s'.\n", relationLabels[rel], word)
+			continue
+		}
+		fmt.Printf("%s of %s:\n", relationLabels[rel], word)
+		t := newKVTable()
+		for _, r := range related {
+			t.Row(r.Word, r.Translation)
+		}
+		fmt.Println(t.Render())
+	}
+}
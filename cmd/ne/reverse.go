@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/suchasplus/ne/internal/bbolthelper"
+	"go.uber.org/zap"
+)
+
+// ReverseResult is the JSON output of a Chinese-to-English lookup.
+type ReverseResult struct {
+	Term    string                     `json:"term"`
+	Matches []bbolthelper.ReverseMatch `json:"matches"`
+	Error   string                     `json:"error,omitempty"`
+}
+
+// printReverseLookup lists the English headwords whose translation matches a Chinese term, in the
+// dictionaries searched by searchDictionaries.
+func printReverseLookup(dbStore *bbolthelper.DBStore, term string, logger *zap.Logger) error {
+	if !dbStore.HasIndex(bbolthelper.IndexTranslation) {
+		logger.Warn("Translation index not built; scanning every translation", zap.String("index", bbolthelper.IndexTranslation))
+	}
+	matches, err := reverseLookup(dbStore, term)
+	if err != nil {
+		logger.Error("Reverse lookup failed", zap.String("term", term), zap.Error(err))
+		if jsonFlag {
+			return printJSON(ReverseResult{Term: term, Matches: []bbolthelper.ReverseMatch{}, Error: err.Error()})
+		}
+		fmt.Printf("Error looking up '%s': %v\n", term, err)
+		return err
+	}
+
+	if jsonFlag {
+		if matches == nil {
+			matches = []bbolthelper.ReverseMatch{}
+		}
+		return printJSON(ReverseResult{Term: term, Matches: matches})
+	}
+	if len(matches) == 0 {
+		fmt.Printf("No English words found for '%s'.\n", term)
+		return nil
+	}
+
+	var rowsData [][]string
+	for _, m := range matches {
+		translation := m.Translation
+		if !fullOutputFlag {
+			// Keep the list compact: the first line of the translation is usually enough.
+			translation, _, _ = strings.Cut(translation, "\n")
+		}
+		rowsData = append(rowsData, []string{m.Word, translation})
+	}
+	t := newKVTable()
+	t.Rows(rowsData...)
+	fmt.Println(t.Render())
+	return nil
+}
+
+// reverseLookup runs a reverse lookup in every searched dictionary with a translation index, and
+// in the store's own, and merges the matches.
+func reverseLookup(dbStore *bbolthelper.DBStore, term string) ([]bbolthelper.ReverseMatch, error) {
+	stores, err := searchDictionaries(dbStore, bbolthelper.IndexTranslation)
+	if err != nil {
+		return nil, err
+	}
+	lists := make([][]bbolthelper.ReverseMatch, len(stores))
+	for i, store := range stores {
+		if lists[i], err = store.ReverseLookup(term, matchesFlag); err != nil {
+			return nil, err
+		}
+	}
+	return bbolthelper.MergeReverseMatches(lists, matchesFlag), nil
+}
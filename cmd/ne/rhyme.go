@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/suchasplus/ne/internal/bbolthelper"
+	"github.com/urfave/cli/v3"
+)
+
+// RhymeResult is the JSON output of `ne rhyme`.
+type RhymeResult struct {
+	Word   string                   `json:"word"`
+	Rhymes []bbolthelper.RhymeMatch `json:"rhymes"`
+}
+
+// rhymeCommand returns the `ne rhyme` subcommand, which lists perfect and near rhymes of a word.
+func rhymeCommand() *cli.Command {
+	var (
+		perfectFlag bool
+		limitFlag   int
+		plainFlag   bool
+	)
+	return &cli.Command{
+		Name:      "rhyme",
+		Usage:     "List words that rhyme with a word, grouped by syllable count",
+		ArgsUsage: "<word>",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:        "perfect",
+				Usage:       "Only list perfect rhymes, not near rhymes",
+				Destination: &perfectFlag,
+			},
+			&cli.IntFlag{
+				Name:        "limit",
+				Aliases:     []string{"n"},
+				Usage:       "Maximum number of words to list (0 for no limit)",
+				Value:       100,
+				Destination: &limitFlag,
+			},
+			&cli.BoolFlag{
+				Name:        "plain",
+				Aliases:     []string{"p"},
+				Usage:       "Print one word per line",
+				Destination: &plainFlag,
+			},
+		},
+		Action: func(ctx context.Context, cCtx *cli.Command) error {
+			logger := newLogger()
+			defer logger.Sync()
+
+			if cCtx.NArg() != 1 {
+				return cli.Exit("Expected exactly one word", 1)
+			}
+			word := cCtx.Args().First()
+
+			dbStore, err := openStore(logger)
+			if err != nil {
+				return err
+			}
+			defer dbStore.Close()
+
+			stores, err := searchDictionaries(dbStore, bbolthelper.IndexRhymes)
+			if err != nil {
+				return err
+			}
+			opts := bbolthelper.RhymeOptions{Near: !perfectFlag, Limit: limitFlag}
+			lists := make([][]bbolthelper.RhymeMatch, len(stores))
+			for i, store := range stores {
+				if lists[i], err = store.Rhymes(word, opts); err != nil {
+					return err
+				}
+			}
+			rhymes := bbolthelper.MergeRhymes(lists, limitFlag)
+
+			if jsonFlag {
+				if rhymes == nil {
+					rhymes = []bbolthelper.RhymeMatch{}
+				}
+				return printJSON(RhymeResult{Word: word, Rhymes: rhymes})
+			}
+			printRhymes(word, rhymes, plainFlag)
+			return nil
+		},
+	}
+}
+
+// printRhymes prints one row per rhyme kind and syllable count, listing its words by frequency.
+func printRhymes(word string, rhymes []bbolthelper.RhymeMatch, plain bool) {
+	switch {
+	case plain:
+		for _, r := range rhymes {
+			fmt.Println(r.Word)
+		}
+	case len(rhymes) == 0:
+		fmt.Printf("No rhymes found for '%s' (rhymes need a phonetic transcription).\n", word)
+	default:
+		var rowsData [][]string
+		var words []string
+		for i, r := range rhymes {
+			words = append(words, r.Word)
+			if i+1 < len(rhymes) && rhymes[i+1].Perfect == r.Perfect && rhymes[i+1].Syllables == r.Syllables {
+				continue
+			}
+			kind := "near"
+			if r.Perfect {
+				kind = "perfect"
+			}
+			rowsData = append(rowsData, []string{fmt.Sprintf("%d syl %s", r.Syllables, kind), strings.Join(words, ", ")})
+			words = nil
+		}
+		t := newKVTable()
+		t.Rows(rowsData...)
+		fmt.Println(t.Render())
+	}
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/suchasplus/ne/internal/bbolthelper"
+	"github.com/urfave/cli/v3"
+)
+
+// SoundsLikeResult is the JSON output of `ne soundslike`.
+type SoundsLikeResult struct {
+	Word    string                   `json:"word"`
+	Matches []bbolthelper.SoundMatch `json:"matches"`
+}
+
+// soundsLikeCommand returns the `ne soundslike` subcommand, which lists words pronounced like a
+// (possibly misspelled) word.
+func soundsLikeCommand() *cli.Command {
+	var (
+		limitFlag int
+		plainFlag bool
+	)
+	return &cli.Command{
+		Name:      "soundslike",
+		Usage:     "List words that sound like a spelling, e.g. \"fonetik\" or \"nolege\"",
+		ArgsUsage: "<spelling>",
+		Flags: []cli.Flag{
+			&cli.IntFlag{
+				Name:        "limit",
+				Aliases:     []string{"n"},
+				Usage:       "Maximum number of words to list (0 for no limit)",
+				Value:       10,
+				Destination: &limitFlag,
+			},
+			&cli.BoolFlag{
+				Name:        "plain",
+				Aliases:     []string{"p"},
+				Usage:       "Print one word per line",
+				Destination: &plainFlag,
+			},
+		},
+		Action: func(ctx context.Context, cCtx *cli.Command) error {
+			logger := newLogger()
+			defer logger.Sync()
+
+			if cCtx.NArg() != 1 {
+				return cli.Exit("Expected exactly one word", 1)
+			}
+			word := cCtx.Args().First()
+
+			dbStore, err := openStore(logger)
+			if err != nil {
+				return err
+			}
+			defer dbStore.Close()
+
+			matches, err := soundsLike(dbStore, word, limitFlag)
+			if err != nil {
+				return err
+			}
+
+			if jsonFlag {
+				if matches == nil {
+					matches = []bbolthelper.SoundMatch{}
+				}
+				return printJSON(SoundsLikeResult{Word: word, Matches: matches})
+			}
+			completions := make([]bbolthelper.Completion, len(matches))
+			for i, m := range matches {
+				completions[i] = bbolthelper.Completion{Word: m.Word, Frq: m.Frq}
+			}
+			printCompletions(completions, plainFlag, fmt.Sprintf("No words sound like '%s'.", word))
+			return nil
+		},
+	}
+}
+
+// soundsLike returns the words sounding like word in every searched dictionary with a metaphone
+// index, and in the store's own.
+func soundsLike(dbStore *bbolthelper.DBStore, word string, limit int) ([]bbolthelper.SoundMatch, error) {
+	stores, err := searchDictionaries(dbStore, bbolthelper.IndexMetaphone)
+	if err != nil {
+		return nil, err
+	}
+	lists := make([][]bbolthelper.SoundMatch, len(stores))
+	for i, store := range stores {
+		if lists[i], err = store.SoundsLike(word, limit); err != nil {
+			return nil, err
+		}
+	}
+	return bbolthelper.MergeSoundMatches(lists, limit), nil
+}
+
+// soundsLikeSuggestions returns the three best words sounding like word, the suggestions shown
+// when no spelling is within the Levenshtein distance of a lookup.
+func soundsLikeSuggestions(dbStore *bbolthelper.DBStore, word string) ([]string, error) {
+	matches, err := soundsLike(dbStore, word, 3)
+	if err != nil {
+		return nil, err
+	}
+	suggestions := make([]string, len(matches))
+	for i, m := range matches {
+		suggestions[i] = m.Word
+	}
+	return suggestions, nil
+}
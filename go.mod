@@ -3,6 +3,7 @@ module github.com/suchasplus/ne
 go 1.24.2
 
 require (
+	github.com/agnivade/levenshtein v1.2.1
 	github.com/charmbracelet/lipgloss v1.1.0
 	github.com/edsrzf/mmap-go v1.1.0
 	github.com/olekukonko/tablewriter v0.0.5
@@ -12,7 +13,6 @@ require (
 )
 
 require (
-	github.com/agnivade/levenshtein v1.2.1 // indirect
 	github.com/aymanbagabas/go-osc52/v2 v2.0.1 // indirect
 	github.com/charmbracelet/colorprofile v0.3.1 // indirect
 	github.com/charmbracelet/x/ansi v0.9.2 // indirect
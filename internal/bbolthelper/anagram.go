@@ -0,0 +1,212 @@
+package bbolthelper
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+
+	bolt "go.etcd.io/bbolt"
+	"go.uber.org/zap"
+)
+
+// IndexAnagrams is the name of the index mapping sorted-letter signatures to words, used by Anagrams.
+const IndexAnagrams = "anagrams"
+
+// anagramMaxSubsets bounds the number of signature lookups of a subset query; beyond it the
+// index bucket is scanned instead.
+const anagramMaxSubsets = 1 << 16
+
+// AnagramOptions controls Anagrams.
+type AnagramOptions struct {
+	// Subset lists every word that can be built from the letters, instead of exact anagrams.
+	Subset bool
+	// Tags keeps only words carrying all of these ECDICT tags (e.g. "cet4").
+	Tags []string
+	// Limit is the maximum number of words returned; <= 0 returns all.
+	Limit int
+}
+
+// anagramSignature returns the letters of word, lowercased and sorted; other characters such
+// as spaces and hyphens are ignored, so phrases are anagrams of their letters.
+func anagramSignature(word string) string {
+	var letters []rune
+	for _, r := range strings.ToLower(word) {
+		if unicode.IsLetter(r) {
+			letters = append(letters, r)
+		}
+	}
+	slices.Sort(letters)
+	return string(letters)
+}
+
+// anagramIndexBuilder groups the dictionary keys by signature.
+type anagramIndexBuilder struct {
+	words map[string][]string
+}
+
+func newAnagramIndexBuilder() indexBuilder {
+	return &anagramIndexBuilder{words: make(map[string][]string)}
+}
+
+func (ab *anagramIndexBuilder) add(word string, fields map[string]string) {
+	if sig := anagramSignature(word); sig != "" {
+		ab.words[sig] = append(ab.words[sig], word)
+	}
+}
+
+func (ab *anagramIndexBuilder) emit(put func(key, value []byte) error) error {
+	return emitSortedLists(ab.words, "\n", put)
+}
+
+// subSignatures returns the signatures of every non-empty sub-multiset of the letters of sig,
+// or false when there are more than anagramMaxSubsets of them.
+func subSignatures(sig string) ([]string, bool) {
+	type letterCount struct {
+		r rune
+		n int
+	}
+	var counts []letterCount
+	total := 1
+	for _, r := range sig {
+		if len(counts) > 0 && counts[len(counts)-1].r == r {
+			counts[len(counts)-1].n++
+		} else {
+			counts = append(counts, letterCount{r: r, n: 1})
+		}
+	}
+	for _, c := range counts {
+		if total *= c.n + 1; total > anagramMaxSubsets {
+			return nil, false
+		}
+	}
+
+	var out []string
+	var build func(i int, prefix []rune)
+	build = func(i int, prefix []rune) {
+		if i == len(counts) {
+			if len(prefix) > 0 {
+				out = append(out, string(prefix))
+			}
+			return
+		}
+		for n := 0; n <= counts[i].n; n++ {
+			build(i+1, prefix)
+			prefix = append(prefix, counts[i].r)
+		}
+	}
+	build(0, nil)
+	return out, true
+}
+
+// isSubSignature reports whether the sorted letters of sub all occur in the sorted letters of sig.
+func isSubSignature(sub, sig string) bool {
+	a, b := []rune(sub), []rune(sig)
+	j := 0
+	for _, r := range a {
+		for j < len(b) && b[j] < r {
+			j++
+		}
+		if j == len(b) || b[j] != r {
+			return false
+		}
+		j++
+	}
+	return true
+}
+
+// Anagrams returns the words made of exactly the letters of word (other than word itself), or
+// with opts.Subset every word that can be built from its letters, including word, most frequent first.
+// Exact anagrams are a single lookup in the anagrams index; without the index all keys are scanned.
+func (s *DBStore) Anagrams(word string, opts AnagramOptions) ([]Completion, error) {
+	sig := anagramSignature(word)
+	if sig == "" {
+		return nil, nil
+	}
+	self := strings.ToLower(word)
+
+	var completions []Completion
+	err := s.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(s.bucketName))
+		if b == nil {
+			return fmt.Errorf("bucket '%s' not found during Anagrams operation", s.bucketName)
+		}
+
+		matches := func(candidate string) bool {
+			if opts.Subset {
+				return isSubSignature(candidate, sig)
+			}
+			return candidate == sig
+		}
+		var words []string
+		if ib := tx.Bucket(s.indexBucketName(IndexAnagrams)); ib != nil {
+			signatures, ok := []string{sig}, true
+			if opts.Subset {
+				signatures, ok = subSignatures(sig)
+			}
+			if ok {
+				for _, sub := range signatures {
+					if v := ib.Get([]byte(sub)); v != nil {
+						words = append(words, strings.Split(string(v), "\n")...)
+					}
+				}
+			} else {
+				s.logger.Debug("Too many letters for subset lookups, scanning the anagrams index", zap.Int("letters", len(sig)))
+				err := ib.ForEach(func(k, v []byte) error {
+					if matches(string(k)) {
+						words = append(words, strings.Split(string(v), "\n")...)
+					}
+					return nil
+				})
+				if err != nil {
+					return err
+				}
+			}
+		} else {
+			s.logger.Debug("Anagrams index not built, scanning all keys")
+			c := b.Cursor()
+			for k, _ := c.First(); k != nil; k, _ = c.Next() {
+				if matches(anagramSignature(string(k))) {
+					words = append(words, string(k))
+				}
+			}
+		}
+
+		sort.Strings(words)
+		for _, w := range words {
+			if w == self && !opts.Subset {
+				continue
+			}
+			v := b.Get([]byte(w))
+			if v == nil {
+				continue // Index is stale
+			}
+			if len(opts.Tags) > 0 && !s.hasTags(w, v, opts.Tags) {
+				continue
+			}
+			completions = append(completions, s.completion([]byte(w), v))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return orderCompletions(completions, opts.Limit, PrefixOrderFrequency), nil
+}
+
+// hasTags reports whether the serialized record v of word carries all of the given tags.
+func (s *DBStore) hasTags(word string, v []byte, tags []string) bool {
+	tagField, _, err := DeserializeField(v, FieldTag)
+	if err != nil {
+		s.logger.Warn("Failed to deserialize value for tag filter, skipping.", zap.String("word", word), zap.Error(err))
+		return false
+	}
+	entry := ParseEntry(word, map[string]string{FieldTag: tagField})
+	for _, t := range tags {
+		if !entry.HasTag(t) {
+			return false
+		}
+	}
+	return true
+}
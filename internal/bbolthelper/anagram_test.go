@@ -0,0 +1,76 @@
+package bbolthelper
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// anagramTestRows extends the fixture's "listen" with its anagrams and a few shorter words.
+var anagramTestRows = append([]string{
+	`silent,,,,,,,zk gk,,900,,,`,
+	`enlist,,,,,,,cet4,,9500,,,`,
+	`tinsel,,,,,,,,,21000,,,`,
+	`inlets,,,,,,,,,,,,`,
+	`lens,,,,,,,gk,,3000,,,`,
+	`nest,,,,,,,zk,,2500,,,`,
+	`tees,,,,,,,,,8000,,,`,
+	`in-let,,,,,,,,,,,,`,
+}, testCSVRows...)
+
+func TestSubSignatures(t *testing.T) {
+	got, ok := subSignatures(anagramSignature("Noon"))
+	sort.Strings(got)
+	if want := []string{"n", "nn", "nno", "nnoo", "no", "noo", "o", "oo"}; !ok || !reflect.DeepEqual(got, want) {
+		t.Errorf("subSignatures(nnoo) = %v, %v; want %v", got, ok, want)
+	}
+	if _, ok := subSignatures("abcdefghijklmnopq"); ok {
+		t.Error("subSignatures() of 17 distinct letters = ok, want too many")
+	}
+	if !isSubSignature("eilnst", "eeilnnstt") || isSubSignature("eet", "eilnst") {
+		t.Error("isSubSignature() misclassified signatures")
+	}
+}
+
+func TestDBStore_Anagrams(t *testing.T) {
+	store := newTestStore(t, Config{BucketName: "TestBucket"})
+	if _, err := store.ImportFromCSV(writeTestCSV(t, t.TempDir(), anagramTestRows), 0); err != nil {
+		t.Fatalf("ImportFromCSV() failed: %v", err)
+	}
+
+	tests := []struct {
+		word string
+		opts AnagramOptions
+		want []string
+	}{
+		// Most frequent first; words without a frq last, alphabetically.
+		{"listen", AnagramOptions{}, []string{"silent", "enlist", "tinsel", "inlets"}},
+		{"LISTEN", AnagramOptions{Limit: 2}, []string{"silent", "enlist"}},
+		{"listen", AnagramOptions{Tags: []string{"gk"}}, []string{"silent"}},
+		{"listen", AnagramOptions{Tags: []string{"gk", "cet4"}}, nil},
+		{"inlet", AnagramOptions{}, []string{"in-let"}},
+		{"listen", AnagramOptions{Subset: true}, []string{"listen", "silent", "nest", "lens", "enlist", "tinsel", "in-let", "inlets"}},
+		{"listen", AnagramOptions{Subset: true, Tags: []string{"zk"}}, []string{"listen", "silent", "nest"}},
+		{"xyz", AnagramOptions{}, nil},
+	}
+	run := func(label string) {
+		for _, tt := range tests {
+			got, err := store.Anagrams(tt.word, tt.opts)
+			if err != nil {
+				t.Fatalf("%s: Anagrams(%q) failed: %v", label, tt.word, err)
+			}
+			var words []string
+			for _, c := range got {
+				words = append(words, c.Word)
+			}
+			if !reflect.DeepEqual(words, tt.want) {
+				t.Errorf("%s: Anagrams(%q, %+v) = %v, want %v", label, tt.word, tt.opts, words, tt.want)
+			}
+		}
+	}
+	run("scan")
+	if err := store.BuildIndexes(IndexAnagrams); err != nil {
+		t.Fatalf("BuildIndexes() failed: %v", err)
+	}
+	run("index")
+}
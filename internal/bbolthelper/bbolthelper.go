@@ -2,14 +2,11 @@ package bbolthelper
 
 import (
 	"bytes"
-	"encoding/csv"
 	"encoding/gob"
 	"fmt"
-	"io"
 	"os"
+	"path/filepath"
 	"sort"
-	"strconv"
-	"strings"
 
 	"github.com/agnivade/levenshtein"
 	bolt "go.etcd.io/bbolt"
@@ -18,7 +15,7 @@ import (
 
 const (
 	DefaultDBPath     = "ecdict.bbolt"
-	DefaultTempDBPath = "ecdict.bbolt.tmp" // For compaction
+	DefaultTempDBPath = "ecdict.bbolt.tmp" // For compaction; created next to the database
 	DefaultBucketName = "EcdictBucket"
 	DefaultDBFileMode = os.FileMode(0644)
 )
@@ -30,6 +27,9 @@ type DBStore struct {
 	dbPath     string
 	bucketName string
 	dbFileMode os.FileMode
+	readOnly   bool
+	codec      Codec
+	builder    string
 }
 
 // Config holds configuration for the DBStore.
@@ -39,6 +39,10 @@ type Config struct {
 	FileMode   os.FileMode
 	ReadOnly   bool
 	Logger     *zap.Logger
+	// Codec selects the encoding for values written by this store. Reads auto-detect the encoding.
+	Codec Codec
+	// BuilderVersion identifies the tool that writes the database. It is recorded in the build metadata.
+	BuilderVersion string
 }
 
 // NewDBStore creates or opens a BoltDB database and returns a DBStore instance.
@@ -58,40 +62,50 @@ func NewDBStore(cfg Config) (*DBStore, error) {
 		cfg.FileMode = DefaultDBFileMode
 	}
 
-	opts := &bolt.Options{ReadOnly: cfg.ReadOnly}
-	// Ensure Timeout is set if necessary, e.g., for NFS mounts, though not typically needed for local files.
-	// opts.Timeout = 1 * time.Second
-
-	db, err := bolt.Open(cfg.DBPath, cfg.FileMode, opts)
-	if err != nil {
-		return nil, fmt.Errorf("failed to open bbolt database '%s': %w", cfg.DBPath, err)
-	}
-
 	store := &DBStore{
-		db:         db,
 		logger:     cfg.Logger,
 		dbPath:     cfg.DBPath,
 		bucketName: cfg.BucketName,
 		dbFileMode: cfg.FileMode,
+		readOnly:   cfg.ReadOnly,
+		codec:      cfg.Codec,
+		builder:    cfg.BuilderVersion,
+	}
+	if err := store.open(); err != nil {
+		return nil, err
+	}
+
+	store.logger.Debug("DBStore initialized", zap.String("dbPath", store.dbPath), zap.String("bucketName", store.bucketName), zap.Bool("readOnly", cfg.ReadOnly), zap.Stringer("codec", store.codec))
+	return store, nil
+}
+
+// open opens the database file at s.dbPath and, unless read-only, ensures the bucket exists.
+func (s *DBStore) open() error {
+	opts := &bolt.Options{ReadOnly: s.readOnly}
+	// Ensure Timeout is set if necessary, e.g., for NFS mounts, though not typically needed for local files.
+	// opts.Timeout = 1 * time.Second
+
+	db, err := bolt.Open(s.dbPath, s.dbFileMode, opts)
+	if err != nil {
+		return fmt.Errorf("failed to open bbolt database '%s': %w", s.dbPath, err)
 	}
 
 	// Ensure the bucket exists if not in read-only mode
-	if !cfg.ReadOnly {
+	if !s.readOnly {
 		err = db.Update(func(tx *bolt.Tx) error {
-			_, err := tx.CreateBucketIfNotExists([]byte(cfg.BucketName))
+			_, err := tx.CreateBucketIfNotExists([]byte(s.bucketName))
 			if err != nil {
-				return fmt.Errorf("failed to create bucket '%s': %w", cfg.BucketName, err)
+				return fmt.Errorf("failed to create bucket '%s': %w", s.bucketName, err)
 			}
 			return nil
 		})
 		if err != nil {
 			db.Close() // Close DB if bucket creation fails
-			return nil, fmt.Errorf("failed to ensure bucket '%s' exists: %w", cfg.BucketName, err)
+			return fmt.Errorf("failed to ensure bucket '%s' exists: %w", s.bucketName, err)
 		}
 	}
-
-	store.logger.Debug("DBStore initialized", zap.String("dbPath", store.dbPath), zap.String("bucketName", store.bucketName), zap.Bool("readOnly", cfg.ReadOnly))
-	return store, nil
+	s.db = db
+	return nil
 }
 
 // Close closes the BoltDB database.
@@ -101,7 +115,9 @@ func (s *DBStore) Close() error {
 		return nil
 	}
 	s.logger.Debug("Closing DBStore", zap.String("dbPath", s.dbPath))
-	return s.db.Close()
+	err := s.db.Close()
+	s.db = nil // Closing twice is a no-op
+	return err
 }
 
 // Serialize converts a map[string]string to a byte slice using gob.
@@ -114,8 +130,17 @@ func Serialize(data map[string]string) ([]byte, error) {
 	return buf.Bytes(), nil
 }
 
-// Deserialize converts a byte slice back to a map[string]string using gob.
+// Deserialize converts a byte slice back to a map[string]string.
+// It detects whether the value was written with gob or with the binary record format.
 func Deserialize(data []byte) (map[string]string, error) {
+	if DetectCodec(data) == CodecBinary {
+		result, err := decodeRecord(data, "")
+		if err != nil {
+			return nil, fmt.Errorf("failed to deserialize data: %w", err)
+		}
+		return result, nil
+	}
+
 	var result map[string]string
 	decoder := gob.NewDecoder(bytes.NewReader(data))
 	if err := decoder.Decode(&result); err != nil {
@@ -156,13 +181,24 @@ func (s *DBStore) Get(key string) (map[string]string, bool, error) {
 	return valueMap, found, nil
 }
 
+// GetEntry retrieves a typed Entry by key from the database.
+// It is the typed counterpart of Get; the returned Entry is nil when the key is not found.
+func (s *DBStore) GetEntry(key string) (*Entry, bool, error) {
+	valueMap, found, err := s.Get(key)
+	if err != nil || !found {
+		return nil, found, err
+	}
+	return ParseEntry(key, valueMap), true, nil
+}
+
 // FindSimilar searches for words with a similar spelling to the input word.
 // It uses the Levenshtein distance to measure similarity and includes performance optimizations.
 // The logic is as follows:
-// 1. Find all words with a Levenshtein distance of 1.
-// 2. Stop searching if more than 10 suggestions are found.
-// 3. Sort suggestions: primarily by frequency (desc), secondarily by length (desc).
-// 4. If more than 3 suggestions are found, return the top 3. Otherwise, return all.
+//  1. Find all words within maxDistance. If the symspell (IndexSymSpell) or radix (IndexRadix) index
+//     was built, it is used to find them; otherwise every key is scanned with a cursor.
+//  2. When scanning, stop searching if more than 10 suggestions are found.
+//  3. Sort suggestions: primarily by frequency (desc), secondarily by length (desc).
+//  4. If more than 3 suggestions are found, return the top 3. Otherwise, return all.
 func (s *DBStore) FindSimilar(word string, maxDistance int) ([]string, error) {
 	// suggestion struct holds data for sorting candidates.
 	type suggestion struct {
@@ -178,6 +214,32 @@ func (s *DBStore) FindSimilar(word string, maxDistance int) ([]string, error) {
 			return fmt.Errorf("bucket '%s' not found during FindSimilar operation", s.bucketName)
 		}
 
+		// frqOf decodes only the frequency field; binary records skip building the full map.
+		frqOf := func(dbWord string, v []byte) (int, bool) {
+			freqStr, _, err := DeserializeField(v, FieldFrq)
+			if err != nil {
+				s.logger.Warn("Failed to deserialize value for suggestion, skipping.", zap.String("word", dbWord), zap.Error(err))
+				return 0, false
+			}
+			return ParseEntry(dbWord, map[string]string{FieldFrq: freqStr}).Frq, true
+		}
+
+		if candidates, ok := s.similarFromIndexes(tx, word, maxDistance); ok {
+			for _, dbWord := range candidates {
+				if dbWord == word {
+					continue
+				}
+				v := b.Get([]byte(dbWord))
+				if v == nil {
+					continue // Index is stale
+				}
+				if freq, ok := frqOf(dbWord, v); ok {
+					suggestions = append(suggestions, suggestion{word: dbWord, freq: freq, len: len(dbWord)})
+				}
+			}
+			return nil
+		}
+
 		c := b.Cursor()
 		inputLen := len(word)
 
@@ -198,21 +260,13 @@ func (s *DBStore) FindSimilar(word string, maxDistance int) ([]string, error) {
 			dist := levenshtein.ComputeDistance(word, dbWord)
 
 			if dist > 0 && dist <= maxDistance {
-				// Deserialize to get frequency.
-				valueMap, err := Deserialize(v)
-				if err != nil {
-					s.logger.Warn("Failed to deserialize value for suggestion, skipping.", zap.String("word", dbWord), zap.Error(err))
-					continue
+				if freq, ok := frqOf(dbWord, v); ok {
+					suggestions = append(suggestions, suggestion{
+						word: dbWord,
+						freq: freq,
+						len:  len(dbWord),
+					})
 				}
-
-				freqStr, _ := valueMap["frq"]
-				freq, _ := strconv.Atoi(freqStr) // Atoi returns 0 on error, which is acceptable here.
-
-				suggestions = append(suggestions, suggestion{
-					word: dbWord,
-					freq: freq,
-					len:  len(dbWord),
-				})
 			}
 		}
 		return nil
@@ -244,6 +298,50 @@ func (s *DBStore) FindSimilar(word string, maxDistance int) ([]string, error) {
 	return resultWords, nil
 }
 
+// similarFromIndexes returns all keys within maxDistance of word using the fastest index that
+// was built: the symspell index answers with a few point lookups, the radix index by walking only
+// the tree branches within maxDistance. It reports false when no index can answer, in which case
+// the caller scans the bucket. Unusable indexes are logged and skipped.
+func (s *DBStore) similarFromIndexes(tx *bolt.Tx, word string, maxDistance int) ([]string, bool) {
+	if ib := tx.Bucket(s.indexBucketName(IndexSymSpell)); ib != nil {
+		candidates, ok, err := symSpellLookup(ib, word, maxDistance)
+		if err != nil {
+			s.logger.Warn("Symspell index unusable, skipping it", zap.Error(err))
+		} else if ok {
+			s.logger.Debug("Fuzzy search used symspell index", zap.String("word", word), zap.Int("candidates", len(candidates)))
+			return candidates, true
+		}
+	}
+
+	if ib := tx.Bucket(s.indexBucketName(IndexRadix)); ib != nil {
+		var candidates []string
+		tree, err := openRadixTree(ib.Get([]byte(radixTreeKey)))
+		if err == nil {
+			err = tree.fuzzySearch(word, maxDistance, func(key string, dist int) {
+				candidates = append(candidates, key)
+			})
+		}
+		if err != nil {
+			s.logger.Warn("Radix index unusable, skipping it", zap.Error(err))
+		} else {
+			s.logger.Debug("Fuzzy search used radix index", zap.String("word", word), zap.Int("candidates", len(candidates)))
+			return candidates, true
+		}
+	}
+	return nil, false
+}
+
+// countKeys counts the keys of a bucket with a cursor.
+// Unlike Bucket.Stats it also sees uncommitted writes of the current transaction.
+func countKeys(b *bolt.Bucket) int {
+	n := 0
+	c := b.Cursor()
+	for k, _ := c.First(); k != nil; k, _ = c.Next() {
+		n++
+	}
+	return n
+}
+
 // abs returns the absolute value of an integer.
 func abs(x int) int {
 	if x < 0 {
@@ -268,7 +366,7 @@ func (s *DBStore) putCore(tx *bolt.Tx, key string, serializedValue []byte) error
 
 // Put stores a key-value (map[string]string) pair into the database.
 func (s *DBStore) Put(key string, valueMap map[string]string) error {
-	serializedValue, err := Serialize(valueMap)
+	serializedValue, err := SerializeWith(s.codec, valueMap)
 	if err != nil {
 		return fmt.Errorf("failed to serialize value for key '%s' before Put: %w", key, err)
 	}
@@ -278,190 +376,100 @@ func (s *DBStore) Put(key string, valueMap map[string]string) error {
 	})
 }
 
-// ImportFromCSV reads records from a CSV file and stores them in the BoltDB database.
-// It returns the number of records processed and an error if any occurred.
-func (s *DBStore) ImportFromCSV(csvFilePath string, progressReportInterval int) (int, error) {
-	s.logger.Info("Starting CSV import...", zap.String("sourceCsv", csvFilePath))
-
-	csvFile, err := os.Open(csvFilePath)
-	if err != nil {
-		return 0, fmt.Errorf("failed to open CSV file '%s': %w", csvFilePath, err)
+// PutEntry stores a typed Entry under its Word.
+// It is the typed counterpart of Put.
+func (s *DBStore) PutEntry(entry *Entry) error {
+	if entry == nil || entry.Word == "" {
+		return fmt.Errorf("cannot put an entry without a word")
 	}
-	defer csvFile.Close()
+	return s.Put(entry.Word, entry.Map())
+}
 
-	reader := csv.NewReader(csvFile)
-	header, err := reader.Read() // Read the header row
-	if err != nil {
-		if err == io.EOF {
-			return 0, fmt.Errorf("CSV file '%s' is empty or has no header", csvFilePath)
-		}
-		return 0, fmt.Errorf("failed to read header from CSV '%s': %w", csvFilePath, err)
-	}
+// CompactStats reports the effect of a compaction.
+type CompactStats struct {
+	BeforeSize int64
+	AfterSize  int64
+}
 
-	if len(header) < 1 {
-		return 0, fmt.Errorf("CSV file '%s' header is invalid (too few columns)", csvFilePath)
+// Compact compacts the BoltDB database in place and reopens the store on the compacted file.
+// The compacted copy is written to tempDBPath, fsynced and renamed over the original, which is
+// never deleted first: after a crash at any point the database path holds either the original or
+// the compacted file. tempDBPath must be on the same filesystem as the database; a bare file name
+// is placed next to the database, and an empty tempDBPath picks a unique name there.
+// Read-only stores cannot be compacted.
+func (s *DBStore) Compact(tempDBPath string) (*CompactStats, error) {
+	if s.db == nil {
+		return nil, fmt.Errorf("cannot compact a closed or uninitialized DBStore")
+	}
+	if s.readOnly {
+		return nil, fmt.Errorf("cannot compact read-only DBStore '%s'", s.dbPath)
 	}
 
-	s.logger.Info("Processing CSV records...", zap.String("csvPath", csvFilePath))
-	var recordsProcessed int
-
-	err = s.db.Update(func(tx *bolt.Tx) error {
-		b := tx.Bucket([]byte(s.bucketName))
-		if b == nil {
-			// This should ideally not happen if NewDBStore correctly created the bucket.
-			return fmt.Errorf("bucket '%s' unexpectedly not found during CSV import", s.bucketName)
+	var err error
+	switch {
+	case tempDBPath == "":
+		if tempDBPath, err = createSiblingTemp(s.dbPath, s.dbFileMode); err != nil {
+			return nil, err
 		}
-
-		for {
-			record, err := reader.Read()
-			if err == io.EOF {
-				break // End of file
-			}
-			if err != nil {
-				s.logger.Warn("Error reading record from CSV, skipping record.", zap.String("csvPath", csvFilePath), zap.Error(err))
-				continue
-			}
-
-			if len(record) < 1 {
-				s.logger.Warn("Empty record found in CSV, skipping.", zap.String("csvPath", csvFilePath))
-				continue
-			}
-
-			key := strings.ToLower(record[0])
-			valueMap := make(map[string]string)
-
-			for i := 1; i < len(record); i++ {
-				if i < len(header) {
-					valueMap[header[i]] = record[i]
-				} else {
-					s.logger.Warn("Record has more columns than header, extra columns ignored.", zap.String("key", key), zap.String("csvPath", csvFilePath))
-				}
-			}
-
-			// Serialize the valueMap for the current record
-			serializedRecordValue, serErr := Serialize(valueMap)
-			if serErr != nil {
-				s.logger.Error("Failed to serialize record, skipping", zap.String("key", key), zap.Error(serErr))
-				continue // Skip this record
-			}
-
-			// Use the DBStore's putCore method with the existing transaction
-			if err := s.putCore(tx, key, serializedRecordValue); err != nil {
-				// Log the error and decide whether to continue or stop the import.
-				// For robustness, we'll log and skip the problematic record.
-				// A more critical error (like transaction failure) would be returned by db.Update's main error.
-				s.logger.Error("Failed to put record into DB using putCore, record skipped", zap.String("key", key), zap.Error(err))
-				continue
-			}
-			recordsProcessed++
-			if progressReportInterval > 0 && recordsProcessed%progressReportInterval == 0 {
-				s.logger.Info("Processed records milestone", zap.Int("count", recordsProcessed))
-			}
+	case filepath.Base(tempDBPath) == tempDBPath:
+		// A bare name would land in the CWD, possibly on another filesystem, where rename cannot be atomic.
+		tempDBPath = filepath.Join(filepath.Dir(s.dbPath), tempDBPath)
+		fallthrough
+	default:
+		// A leftover file from an earlier attempt would otherwise be merged into.
+		if err := os.Remove(tempDBPath); err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to remove stale temporary file '%s': %w", tempDBPath, err)
 		}
-		return nil // Return nil for the transaction func if loop completes without critical error
-	})
-
-	if err != nil {
-		// This error comes from db.Update if the transaction itself failed (e.g., disk full, permissions)
-		return recordsProcessed, fmt.Errorf("failed during bbolt transaction for CSV import: %w", err)
 	}
+	defer func() {
+		if _, statErr := os.Stat(tempDBPath); statErr == nil { // If tempDB still exists (rename failed/not reached)
+			s.logger.Info("Removing temporary database file after compaction attempt.", zap.String("tempDB", tempDBPath))
+			os.Remove(tempDBPath)
+		}
+	}()
 
-	s.logger.Info("Successfully imported records from CSV.",
-		zap.Int("totalRecords", recordsProcessed),
-		zap.String("dbPath", s.dbPath),
-		zap.String("bucketName", s.bucketName),
-	)
-	return recordsProcessed, nil
-}
-
-// Compact compacts the BoltDB database.
-// It requires the DBStore to be re-initialized by the caller after compaction if it was not read-only,
-// as this method closes the current DB instance and replaces the file.
-// For a read-only DBStore, this operation is not directly applicable as it modifies the DB.
-func (s *DBStore) Compact(tempDBPath string) error {
-	if s.db == nil {
-		return fmt.Errorf("cannot compact a closed or uninitialized DBStore")
-	}
-	if tempDBPath == "" {
-		tempDBPath = DefaultTempDBPath
+	stats := &CompactStats{}
+	if fi, err := os.Stat(s.dbPath); err == nil {
+		stats.BeforeSize = fi.Size()
 	}
 	s.logger.Info("Starting database compaction",
 		zap.String("originalDB", s.dbPath),
 		zap.String("tempDB", tempDBPath),
 	)
 
-	// Store current configuration to potentially re-open/re-initialize later
-	// However, the caller should generally handle re-initialization after compaction.
-	originalPath := s.dbPath
-	originalFileMode := s.dbFileMode
-	// originalBucketName := s.bucketName
-	// originalLogger := s.logger
-
-	// 1. Close the current database instance managed by this DBStore.
-	// This is crucial because compaction typically involves replacing the database file.
-	err := s.db.Close()
-	s.db = nil // Mark as closed to prevent further use of the old instance
-	if err != nil {
-		return fmt.Errorf("failed to close current database instance ('%s') before compaction: %w", originalPath, err)
+	// 1. Copy the live database into the temporary file. The original stays open and untouched.
+	if err := s.compactInto(tempDBPath); err != nil {
+		return nil, err
 	}
-	s.logger.Info("Current database instance closed for compaction.", zap.String("dbPath", originalPath))
 
-	// 2. Open the original database in read-only mode for copying its contents.
-	originalDBReadOnly, err := bolt.Open(originalPath, originalFileMode, &bolt.Options{ReadOnly: true})
-	if err != nil {
-		// Attempt to reopen the original DB for the store if compaction setup fails
-		// This part is tricky, as the state might be inconsistent. Best to return error.
-		return fmt.Errorf("failed to open original DB '%s' as read-only for compaction: %w. The DBStore is now closed.", originalPath, err)
+	// 2. Close the current instance so the file can be replaced (required on Windows).
+	if err := s.db.Close(); err != nil {
+		s.db = nil
+		return nil, fmt.Errorf("failed to close database '%s' before replacing it with the compacted copy: %w", s.dbPath, err)
 	}
-	defer originalDBReadOnly.Close()
+	s.db = nil
 
-	// 3. Create/Open the temporary database for writing the compacted data.
-	tempDB, err := bolt.Open(tempDBPath, originalFileMode, nil) // Default options for new DB
-	if err != nil {
-		return fmt.Errorf("failed to open temp DB '%s' for compaction: %w. The DBStore is now closed.", tempDBPath, err)
-	}
-	defer func() {
-		tempDB.Close()                                                  // Ensure tempDB is closed.
-		if _, statErr := os.Stat(tempDBPath); !os.IsNotExist(statErr) { // If tempDB still exists (rename failed/not reached)
-			s.logger.Info("Removing temporary database file after compaction attempt.", zap.String("tempDB", tempDBPath))
-			os.Remove(tempDBPath)
-		}
-	}()
-
-	// 4. Copy data from the read-only original to the new temporary database.
-	s.logger.Info("Copying data to temporary database for compaction...", zap.String("from", originalPath), zap.String("to", tempDBPath))
-	err = originalDBReadOnly.View(func(tx *bolt.Tx) error {
-		// tx.CopyFile is bbolt's recommended way to compact.
-		// It efficiently copies the database content to the file descriptor of an open bolt.DB.
-		return tx.CopyFile(tempDB.Path(), originalFileMode)
-	})
-	if err != nil {
-		return fmt.Errorf("failed to copy data from '%s' to '%s' during compaction: %w. The DBStore is now closed.", originalPath, tempDBPath, err)
-	}
-	s.logger.Info("Data copy for compaction successful.")
+	// 3. Atomically replace the original. On failure the original is still in place and is reopened.
+	replaceErr := replaceFile(tempDBPath, s.dbPath)
 
-	// 5. Critical: Close both databases before rename/remove operations.
-	if err := tempDB.Close(); err != nil { // Close tempDB after successful copy.
-		return fmt.Errorf("failed to close temp DB '%s' after copy: %w. The DBStore is now closed.", tempDBPath, err)
-	}
-	if err := originalDBReadOnly.Close(); err != nil { // Close original read-only DB.
-		s.logger.Warn("Failed to close original read-only DB after copy (this is usually a defer, but checking explicitly)", zap.Error(err))
+	// 4. Reopen the store on whichever file is now at the database path.
+	if err := s.open(); err != nil {
+		if replaceErr != nil {
+			return nil, fmt.Errorf("%w; additionally failed to reopen '%s': %v", replaceErr, s.dbPath, err)
+		}
+		return nil, err
 	}
-
-	// 6. Remove the original (now old) database file.
-	s.logger.Info("Removing original database file before replacing with compacted version.", zap.String("originalDB", originalPath))
-	if err := os.Remove(originalPath); err != nil {
-		return fmt.Errorf("failed to remove original DB '%s' to replace with compacted version: %w. The DBStore is now closed.", originalPath, err)
+	if replaceErr != nil {
+		return nil, replaceErr
 	}
 
-	// 7. Rename the temporary (now compacted) database to the original database name.
-	s.logger.Info("Renaming temporary (compacted) database to original name.", zap.String("tempDB", tempDBPath), zap.String("targetDB", originalPath))
-	if err := os.Rename(tempDBPath, originalPath); err != nil {
-		return fmt.Errorf("failed to rename temp DB '%s' to '%s': %w. The DBStore is now closed; manual recovery of '%s' might be needed.", tempDBPath, originalPath, err, tempDBPath)
+	if fi, err := os.Stat(s.dbPath); err == nil {
+		stats.AfterSize = fi.Size()
 	}
-
-	s.logger.Info("Database compaction completed successfully. The underlying file has been replaced.", zap.String("dbPath", originalPath))
-	s.logger.Info("The DBStore instance is now closed. Please re-initialize a new DBStore instance to use the compacted database.")
-	// s.db remains nil. The caller is responsible for creating a new DBStore instance.
-	return nil
+	s.logger.Info("Database compaction completed successfully.",
+		zap.String("dbPath", s.dbPath),
+		zap.Int64("beforeSize", stats.BeforeSize),
+		zap.Int64("afterSize", stats.AfterSize),
+	)
+	return stats, nil
 }
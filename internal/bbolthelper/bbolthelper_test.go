@@ -1,6 +1,7 @@
 package bbolthelper
 
 import (
+	"fmt"
 	"reflect"
 	"testing"
 	"os"
@@ -368,3 +369,56 @@ func TestDBStore_FindSimilar(t *testing.T) {
 		})
 	}
 }
+
+func TestDBStore_Compact(t *testing.T) {
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "compact.db")
+	store := newTestStore(t, Config{DBPath: dbPath})
+
+	// Grow the file with large values, then shrink them so compaction has space to reclaim.
+	big := string(make([]byte, 4096))
+	for i := 0; i < 200; i++ {
+		if err := store.Put(fmt.Sprintf("key%03d", i), map[string]string{"v": big}); err != nil {
+			t.Fatalf("Put() failed: %v", err)
+		}
+	}
+	for i := 0; i < 200; i++ {
+		if err := store.Put(fmt.Sprintf("key%03d", i), map[string]string{"v": "small"}); err != nil {
+			t.Fatalf("Put() failed: %v", err)
+		}
+	}
+
+	stats, err := store.Compact(DefaultTempDBPath)
+	if err != nil {
+		t.Fatalf("Compact() error = %v", err)
+	}
+	if stats.AfterSize >= stats.BeforeSize {
+		t.Errorf("Compact() sizes before = %d, after = %d; want the file to shrink", stats.BeforeSize, stats.AfterSize)
+	}
+
+	// The store is reopened on the compacted file and remains writable.
+	if got, found, err := store.Get("key123"); err != nil || !found || got["v"] != "small" {
+		t.Errorf("Get() after Compact() = %v, %v, %v", got, found, err)
+	}
+	if err := store.Put("after", map[string]string{"v": "1"}); err != nil {
+		t.Errorf("Put() after Compact() error = %v", err)
+	}
+
+	// The temporary file lives next to the database and is gone afterwards.
+	if _, err := os.Stat(filepath.Join(dir, DefaultTempDBPath)); !os.IsNotExist(err) {
+		t.Errorf("temporary file left next to the database: %v", err)
+	}
+	if _, err := os.Stat(DefaultTempDBPath); !os.IsNotExist(err) {
+		t.Errorf("temporary file created in the working directory: %v", err)
+	}
+
+	store.Close()
+	readOnly, err := NewDBStore(Config{DBPath: dbPath, ReadOnly: true, Logger: zap.NewNop()})
+	if err != nil {
+		t.Fatalf("NewDBStore() read-only failed: %v", err)
+	}
+	defer readOnly.Close()
+	if _, err := readOnly.Compact(""); err == nil {
+		t.Errorf("Compact() on a read-only store should fail")
+	}
+}
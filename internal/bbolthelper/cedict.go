@@ -0,0 +1,188 @@
+package bbolthelper
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+
+	"go.uber.org/zap"
+)
+
+// cedictLine matches an entry line of CC-CEDICT: "Traditional Simplified [pin1 yin1] /gloss/gloss/".
+var cedictLine = regexp.MustCompile(`^(\S+) (\S+) \[([^\]]*)\] /(.*)/$`)
+
+// cedictReading is one pronunciation of a headword with its glosses.
+type cedictReading struct {
+	pinyin  string
+	glosses []string
+}
+
+// cedictEntry converts the readings of a headword into a record: the tone-marked readings become
+// pinyin, and the glosses of each reading one line of translation, prefixed by the reading when
+// there are several.
+func cedictEntry(word string, readings []cedictReading) *Entry {
+	e := &Entry{Word: word}
+	var pinyin, lines []string
+	for _, r := range readings {
+		marked := pinyinToneMarks(r.pinyin)
+		pinyin = append(pinyin, marked)
+		line := strings.Join(r.glosses, "; ")
+		if len(readings) > 1 {
+			line = "[" + marked + "] " + line
+		}
+		lines = append(lines, line)
+	}
+	e.Pinyin = strings.Join(pinyin, ", ")
+	e.Translation = strings.Join(lines, "\n")
+	return e
+}
+
+// pinyinTones lists the four tone-marked forms of each vowel that can carry a mark.
+var pinyinTones = map[rune][]rune{
+	'a': []rune("āáǎà"), 'e': []rune("ēéěè"), 'i': []rune("īíǐì"), 'o': []rune("ōóǒò"),
+	'u': []rune("ūúǔù"), 'ü': []rune("ǖǘǚǜ"),
+}
+
+// pinyinToneMarks converts numbered pinyin, as written by CC-CEDICT ("zhong1 guo2", "lu:4"), to
+// tone marks ("zhōng guó", "lǜ"). The neutral tone 5 is dropped; other syllables are unchanged.
+func pinyinToneMarks(s string) string {
+	syllables := strings.Fields(s)
+	for i, syl := range syllables {
+		syllables[i] = markSyllable(syl)
+	}
+	return strings.Join(syllables, " ")
+}
+
+// markSyllable puts the tone mark of a numbered syllable on a or e, on the o of ou, and otherwise
+// on the last vowel.
+func markSyllable(syl string) string {
+	syl = strings.NewReplacer("u:", "ü", "U:", "Ü").Replace(syl)
+	if len(syl) < 2 || syl[len(syl)-1] < '1' || syl[len(syl)-1] > '5' {
+		return syl
+	}
+	tone := int(syl[len(syl)-1] - '1')
+	base := []rune(syl[:len(syl)-1])
+	if tone == 4 {
+		return string(base)
+	}
+
+	mark := -1
+	lower := []rune(strings.ToLower(string(base)))
+	if i := slices.IndexFunc(lower, func(r rune) bool { return r == 'a' || r == 'e' }); i >= 0 {
+		mark = i
+	} else if i := strings.Index(string(lower), "ou"); i >= 0 {
+		mark = len([]rune(string(lower)[:i]))
+	} else {
+		for i, r := range lower {
+			if strings.ContainsRune("iouü", r) {
+				mark = i
+			}
+		}
+	}
+	if mark < 0 {
+		return syl // A syllable without a vowel, such as "m2" or "r5"
+	}
+	if forms, ok := pinyinTones[base[mark]]; ok {
+		base[mark] = forms[tone]
+	} else if forms, ok := pinyinTones[unicode.ToLower(base[mark])]; ok {
+		base[mark] = unicode.ToUpper(forms[tone])
+	}
+	return string(base)
+}
+
+// ImportCEDICT imports a CC-CEDICT dictionary file. Every entry is stored under both its
+// simplified and its traditional headword (once when they are the same), with the pinyin readings
+// and English glosses of all entries sharing a headword merged into one record. Comment lines
+// starting with '#' are skipped. The dictionary is named CC-CEDICT unless opts.Manifest sets
+// another display name. It returns the number of records stored.
+func (s *DBStore) ImportCEDICT(ctx context.Context, path string, opts ImportOptions) (int, error) {
+	s.logger.Info("Starting CC-CEDICT import...", zap.String("source", path))
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open CC-CEDICT file '%s': %w", path, err)
+	}
+	defer f.Close()
+
+	// Headwords are collected first, since the simplified and traditional forms of an entry are
+	// keys of different records and entries of one headword are not adjacent in the file.
+	hasher := sha256.New()
+	sc := bufio.NewScanner(io.TeeReader(f, hasher))
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	headwords := make(map[string][]cedictReading)
+	lineNo := 0
+	for sc.Scan() {
+		lineNo++
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		m := cedictLine.FindStringSubmatch(line)
+		if m == nil {
+			s.logger.Warn("Malformed CC-CEDICT line, skipping.", zap.Int("line", lineNo), zap.String("text", line))
+			continue
+		}
+		var glosses []string
+		for _, g := range strings.Split(m[4], "/") {
+			if g = strings.TrimSpace(g); g != "" {
+				glosses = append(glosses, g)
+			}
+		}
+		for _, word := range dedupe([]string{strings.ToLower(m[2]), strings.ToLower(m[1])}) {
+			headwords[word] = addCedictReading(headwords[word], m[3], glosses)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return 0, fmt.Errorf("failed to read CC-CEDICT file '%s': %w", path, err)
+	}
+
+	words := make([]string, 0, len(headwords))
+	for w := range headwords {
+		words = append(words, w)
+	}
+	sort.Strings(words)
+	next := func() (string, map[string]string, error) {
+		if len(words) == 0 {
+			return "", nil, io.EOF
+		}
+		w := words[0]
+		words = words[1:]
+		return w, cedictEntry(w, headwords[w]).Map(), nil
+	}
+
+	if opts.Manifest.DisplayName == "" {
+		opts.Manifest.DisplayName = "CC-CEDICT"
+	}
+	meta := &Metadata{
+		Format:        "cedict",
+		SourceFile:    filepath.Base(path),
+		SourceSHA256:  hex.EncodeToString(hasher.Sum(nil)),
+		HeaderColumns: []string{"word", FieldPinyin, FieldTranslation},
+	}
+	records, err := s.importRecords(ctx, next, meta, opts)
+	if err != nil {
+		return records, fmt.Errorf("failed to import CC-CEDICT file '%s': %w", path, err)
+	}
+	return records, nil
+}
+
+// addCedictReading adds the glosses of a reading to a headword, merging them into an existing
+// reading with the same pinyin.
+func addCedictReading(readings []cedictReading, pinyin string, glosses []string) []cedictReading {
+	for i := range readings {
+		if readings[i].pinyin == pinyin {
+			readings[i].glosses = dedupe(append(readings[i].glosses, glosses...))
+			return readings
+		}
+	}
+	return append(readings, cedictReading{pinyin: pinyin, glosses: glosses})
+}
@@ -0,0 +1,86 @@
+package bbolthelper
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// testCEDICT is a CC-CEDICT fixture with a comment header, a headword whose traditional and
+// simplified forms differ, one with two readings and a malformed line.
+const testCEDICT = `# CC-CEDICT
+#! version=1
+傳統 传统 [chuan2 tong3] /tradition/traditional/
+中國 中国 [Zhong1 guo2] /China/
+行 行 [xing2] /to walk/to go/
+行 行 [hang2] /row/line/
+女 女 [nu:3] /female/woman/
+this line is malformed
+`
+
+func TestPinyinToneMarks(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"chuan2 tong3", "chuán tǒng"},
+		{"Zhong1 guo2", "Zhōng guó"},
+		{"nu:3", "nǚ"},
+		{"Lu:4", "Lǜ"},
+		{"gou3", "gǒu"},
+		{"gui4", "guì"},
+		{"liu2", "liú"},
+		{"Ou1 zhou1", "Ōu zhōu"},
+		{"men5", "men"},
+		{"m2", "m2"},
+		{"A A zhi4", "A A zhì"},
+	}
+	for _, tc := range tests {
+		if got := pinyinToneMarks(tc.in); got != tc.want {
+			t.Errorf("pinyinToneMarks(%q) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestDBStore_ImportCEDICT(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cedict_ts.u8")
+	if err := os.WriteFile(path, []byte(testCEDICT), 0644); err != nil {
+		t.Fatalf("Failed to write CC-CEDICT fixture: %v", err)
+	}
+
+	store := newTestStore(t, Config{BucketName: "cedict"})
+	n, err := store.ImportCEDICT(t.Context(), path, ImportOptions{})
+	if err != nil {
+		t.Fatalf("ImportCEDICT() failed: %v", err)
+	}
+	if n != 6 {
+		t.Errorf("ImportCEDICT() stored %d records, want 6", n)
+	}
+
+	tests := []struct {
+		word, pinyin, translation string
+	}{
+		{"传统", "chuán tǒng", "tradition; traditional"},
+		{"傳統", "chuán tǒng", "tradition; traditional"},
+		{"中国", "Zhōng guó", "China"},
+		{"行", "xíng, háng", "[xíng] to walk; to go\n[háng] row; line"},
+		{"女", "nǚ", "female; woman"},
+	}
+	for _, tc := range tests {
+		entry, found, err := store.GetEntry(tc.word)
+		if err != nil || !found {
+			t.Errorf("GetEntry(%q) = found %v, %v; want an entry", tc.word, found, err)
+			continue
+		}
+		if entry.Pinyin != tc.pinyin || entry.Translation != tc.translation {
+			t.Errorf("GetEntry(%q) = %q, %q; want %q, %q", tc.word, entry.Pinyin, entry.Translation, tc.pinyin, tc.translation)
+		}
+	}
+
+	meta, _, err := store.Metadata()
+	if err != nil {
+		t.Fatalf("Metadata() failed: %v", err)
+	}
+	if meta.Label() != "CC-CEDICT" || meta.RecordCount != 6 || meta.SourceFile != "cedict_ts.u8" || meta.SourceSHA256 == "" {
+		t.Errorf("Metadata() = %+v, want CC-CEDICT with 6 records from cedict_ts.u8", meta)
+	}
+}
@@ -0,0 +1,226 @@
+package bbolthelper
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Codec selects the on-disk encoding used for record values.
+// Deserialize detects the encoding of each value, so a database may mix codecs
+// and existing gob-encoded databases remain readable whichever codec is configured.
+type Codec int
+
+const (
+	// CodecGob encodes the value map with encoding/gob. This is the original format.
+	CodecGob Codec = iota
+	// CodecBinary encodes the value map with the compact record format described in docs/record_format.md.
+	CodecBinary
+)
+
+// String returns the name of the codec as accepted by ParseCodec.
+func (c Codec) String() string {
+	switch c {
+	case CodecGob:
+		return "gob"
+	case CodecBinary:
+		return "binary"
+	default:
+		return fmt.Sprintf("Codec(%d)", int(c))
+	}
+}
+
+// ParseCodec returns the Codec with the given name ("gob" or "binary").
+func ParseCodec(name string) (Codec, error) {
+	switch strings.ToLower(name) {
+	case "gob":
+		return CodecGob, nil
+	case "binary", "bin":
+		return CodecBinary, nil
+	default:
+		return 0, fmt.Errorf("unknown codec '%s' (want gob or binary)", name)
+	}
+}
+
+const (
+	// recordMagic is the first byte of every binary record. It can never start a gob stream:
+	// gob messages begin with a length that is either < 0x80 or a negated byte count >= 0xF8.
+	recordMagic byte = 0xEC
+	// RecordFormatVersion is the current version of the binary record format.
+	RecordFormatVersion byte = 1
+	// namedFieldID marks a field whose name is stored inline instead of being implied by its ID.
+	namedFieldID = 0
+)
+
+// recordFieldIDs assigns the stable numeric IDs used by the binary record format to the ECDICT fields.
+// IDs are contiguous from 1 and must never be reused or renumbered; new fields get the next ID.
+var recordFieldIDs = map[string]uint64{
+	FieldPhonetic:    1,
+	FieldDefinition:  2,
+	FieldTranslation: 3,
+	FieldPOS:         4,
+	FieldCollins:     5,
+	FieldOxford:      6,
+	FieldTag:         7,
+	FieldBNC:         8,
+	FieldFrq:         9,
+	FieldExchange:    10,
+	FieldDetail:      11,
+	FieldAudio:       12,
+	FieldPinyin:      13,
+	FieldEtymology:   14,
+	FieldExamples:    15,
+}
+
+// recordFieldNames is the inverse of recordFieldIDs.
+var recordFieldNames = func() map[uint64]string {
+	names := make(map[uint64]string, len(recordFieldIDs))
+	for name, id := range recordFieldIDs {
+		names[id] = name
+	}
+	return names
+}()
+
+var errTruncatedRecord = errors.New("truncated binary record")
+
+// SerializeWith converts a map[string]string to a byte slice using the given codec.
+func SerializeWith(codec Codec, data map[string]string) ([]byte, error) {
+	switch codec {
+	case CodecGob:
+		return Serialize(data)
+	case CodecBinary:
+		return encodeRecord(data), nil
+	default:
+		return nil, fmt.Errorf("failed to serialize data: unsupported codec %v", codec)
+	}
+}
+
+// DetectCodec reports which codec produced a serialized value.
+func DetectCodec(data []byte) Codec {
+	if len(data) > 0 && data[0] == recordMagic {
+		return CodecBinary
+	}
+	return CodecGob
+}
+
+// encodeRecord writes the binary record format: the magic byte, the format version, and then
+// one entry per non-empty field. Known fields are written in ID order as
+// uvarint(id) uvarint(len) value; unknown fields as uvarint(0) uvarint(len) name uvarint(len) value.
+// Empty values are omitted, so they decode as absent keys.
+func encodeRecord(data map[string]string) []byte {
+	size := 2
+	for k, v := range data {
+		size += len(k) + len(v) + 2*binary.MaxVarintLen16
+	}
+	buf := make([]byte, 0, size)
+	buf = append(buf, recordMagic, RecordFormatVersion)
+
+	for id := uint64(1); id <= uint64(len(recordFieldNames)); id++ {
+		v := data[recordFieldNames[id]]
+		if v == "" {
+			continue
+		}
+		buf = binary.AppendUvarint(buf, id)
+		buf = binary.AppendUvarint(buf, uint64(len(v)))
+		buf = append(buf, v...)
+	}
+	for _, k := range sortedKeys(data) {
+		v := data[k]
+		if _, known := recordFieldIDs[k]; known || v == "" {
+			continue
+		}
+		buf = binary.AppendUvarint(buf, namedFieldID)
+		buf = binary.AppendUvarint(buf, uint64(len(k)))
+		buf = append(buf, k...)
+		buf = binary.AppendUvarint(buf, uint64(len(v)))
+		buf = append(buf, v...)
+	}
+	return buf
+}
+
+// decodeRecord parses a binary record. If only is non-empty, decoding stops as soon as
+// that field has been found and the returned map contains just that field.
+func decodeRecord(data []byte, only string) (map[string]string, error) {
+	if len(data) < 2 || data[0] != recordMagic {
+		return nil, fmt.Errorf("not a binary record")
+	}
+	if data[1] != RecordFormatVersion {
+		return nil, fmt.Errorf("unsupported binary record format version %d", data[1])
+	}
+	result := make(map[string]string)
+	p := data[2:]
+	for len(p) > 0 {
+		id, n := binary.Uvarint(p)
+		if n <= 0 {
+			return nil, errTruncatedRecord
+		}
+		p = p[n:]
+
+		var name string
+		if id == namedFieldID {
+			raw, rest, err := readBytes(p)
+			if err != nil {
+				return nil, err
+			}
+			name, p = string(raw), rest
+		} else {
+			var ok bool
+			if name, ok = recordFieldNames[id]; !ok {
+				name = fmt.Sprintf("field%d", id)
+			}
+		}
+
+		raw, rest, err := readBytes(p)
+		if err != nil {
+			return nil, err
+		}
+		p = rest
+		if only != "" {
+			if name == only {
+				result[name] = string(raw)
+				return result, nil
+			}
+			continue
+		}
+		result[name] = string(raw)
+	}
+	return result, nil
+}
+
+// readBytes reads a uvarint length followed by that many bytes.
+func readBytes(p []byte) ([]byte, []byte, error) {
+	l, n := binary.Uvarint(p)
+	if n <= 0 || uint64(len(p)-n) < l {
+		return nil, nil, errTruncatedRecord
+	}
+	return p[n : n+int(l)], p[n+int(l):], nil
+}
+
+// DeserializeField returns a single field of a serialized value.
+// For binary records it avoids building the full map, which keeps cursor scans cheap.
+func DeserializeField(data []byte, field string) (string, bool, error) {
+	var m map[string]string
+	var err error
+	if DetectCodec(data) == CodecBinary {
+		m, err = decodeRecord(data, field)
+	} else {
+		m, err = Deserialize(data)
+	}
+	if err != nil {
+		return "", false, err
+	}
+	v, ok := m[field]
+	return v, ok, nil
+}
+
+// sortedKeys returns the keys of m in sorted order, so encoded records are deterministic.
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,137 @@
+package bbolthelper
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestSerializeWith_Binary(t *testing.T) {
+	input := map[string]string{
+		"phonetic":    "ˈæpl",
+		"translation": `n. 苹果\nn. 苹果树`,
+		"frq":         "2000",
+		"collins":     "",
+		"custom":      "extra field",
+	}
+
+	data, err := SerializeWith(CodecBinary, input)
+	if err != nil {
+		t.Fatalf("SerializeWith() error = %v", err)
+	}
+	if data[0] != recordMagic || data[1] != RecordFormatVersion {
+		t.Fatalf("binary record header = %x, want %x %x", data[:2], recordMagic, RecordFormatVersion)
+	}
+	if DetectCodec(data) != CodecBinary {
+		t.Errorf("DetectCodec() = %v, want binary", DetectCodec(data))
+	}
+
+	got, err := Deserialize(data)
+	if err != nil {
+		t.Fatalf("Deserialize() error = %v", err)
+	}
+	// Empty values are not stored by the binary codec.
+	want := map[string]string{
+		"phonetic":    "ˈæpl",
+		"translation": `n. 苹果\nn. 苹果树`,
+		"frq":         "2000",
+		"custom":      "extra field",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Deserialize() got = %v, want %v", got, want)
+	}
+
+	gobData, err := Serialize(want)
+	if err != nil {
+		t.Fatalf("Serialize() error = %v", err)
+	}
+	if len(data) >= len(gobData) {
+		t.Errorf("binary record (%d bytes) should be smaller than gob (%d bytes)", len(data), len(gobData))
+	}
+}
+
+func TestDeserializeField(t *testing.T) {
+	input := map[string]string{"frq": "42", "translation": "v. 去", "custom": "x"}
+	for _, codec := range []Codec{CodecGob, CodecBinary} {
+		t.Run(codec.String(), func(t *testing.T) {
+			data, err := SerializeWith(codec, input)
+			if err != nil {
+				t.Fatalf("SerializeWith() error = %v", err)
+			}
+			for field, want := range input {
+				got, ok, err := DeserializeField(data, field)
+				if err != nil || !ok || got != want {
+					t.Errorf("DeserializeField(%q) = %q, %v, %v; want %q", field, got, ok, err, want)
+				}
+			}
+			if _, ok, err := DeserializeField(data, "missing"); ok || err != nil {
+				t.Errorf("DeserializeField(missing) ok = %v, err = %v", ok, err)
+			}
+		})
+	}
+}
+
+func TestDeserialize_CorruptBinary(t *testing.T) {
+	tests := map[string][]byte{
+		"unknown version": {recordMagic, 99},
+		"truncated value": {recordMagic, RecordFormatVersion, 9, 5, '4'},
+		"truncated name":  {recordMagic, RecordFormatVersion, 0, 10, 'a'},
+	}
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := Deserialize(data); err == nil {
+				t.Errorf("Deserialize(%x) expected an error", data)
+			}
+		})
+	}
+}
+
+func TestParseCodec(t *testing.T) {
+	for _, c := range []Codec{CodecGob, CodecBinary} {
+		got, err := ParseCodec(c.String())
+		if err != nil || got != c {
+			t.Errorf("ParseCodec(%q) = %v, %v", c.String(), got, err)
+		}
+	}
+	if _, err := ParseCodec("protobuf"); err == nil {
+		t.Errorf("ParseCodec(protobuf) expected an error")
+	}
+}
+
+func TestDBStore_MixedCodecs(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "bbolthelper_codec_test_*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+	dbPath := filepath.Join(tempDir, "codec.db")
+
+	// Write one record with the legacy codec, then reopen with the binary codec and write another.
+	gobStore, err := NewDBStore(Config{DBPath: dbPath, Logger: zap.NewNop()})
+	if err != nil {
+		t.Fatalf("NewDBStore() failed: %v", err)
+	}
+	if err := gobStore.Put("old", map[string]string{"frq": "1"}); err != nil {
+		t.Fatalf("Put() failed: %v", err)
+	}
+	gobStore.Close()
+
+	binStore, err := NewDBStore(Config{DBPath: dbPath, Logger: zap.NewNop(), Codec: CodecBinary})
+	if err != nil {
+		t.Fatalf("NewDBStore() failed: %v", err)
+	}
+	defer binStore.Close()
+	if err := binStore.Put("new", map[string]string{"frq": "2"}); err != nil {
+		t.Fatalf("Put() failed: %v", err)
+	}
+
+	for key, want := range map[string]string{"old": "1", "new": "2"} {
+		got, found, err := binStore.Get(key)
+		if err != nil || !found || got["frq"] != want {
+			t.Errorf("Get(%s) = %v, %v, %v; want frq %s", key, got, found, err, want)
+		}
+	}
+}
@@ -0,0 +1,384 @@
+package bbolthelper
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+
+	bolt "go.etcd.io/bbolt"
+	"go.uber.org/zap"
+)
+
+// IndexDefinition is the name of the BM25 full-text index over the English definition field,
+// used by Describe to find words from their meaning.
+const IndexDefinition = "definition"
+
+// The definition index bucket holds three key spaces:
+//
+//	"t:" + term          -> postings: per document, uvarint id delta and uvarint term frequency
+//	"d:" + uint32 BE id  -> uvarint document length in terms, followed by the headword
+//	"\x00stats"          -> JSON-encoded definitionStats
+//
+// Ids are positions in the sorted key list of the words that have a definition.
+const (
+	definitionTermPrefix = "t:"
+	definitionDocPrefix  = "d:"
+	definitionStatsKey   = "\x00stats"
+)
+
+// BM25 parameters, using the usual defaults.
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// snippetMaxRunes bounds the length of DescribeMatch.Snippet.
+const snippetMaxRunes = 100
+
+// definitionStats holds the corpus statistics BM25 needs.
+type definitionStats struct {
+	Documents    int     `json:"documents"`
+	AvgDocLength float64 `json:"avg_doc_length"`
+}
+
+// DescribeMatch is a headword whose definition matches a description.
+type DescribeMatch struct {
+	Word  string  `json:"word"`
+	Score float64 `json:"score"`
+	// Snippet is the line of the definition sharing the most terms with the description.
+	Snippet string `json:"snippet"`
+}
+
+// stopWords are common English words that carry no meaning for definition search.
+var stopWords = map[string]bool{}
+
+func init() {
+	for _, w := range strings.Fields(`a about above after again against all am an and any are as at be because been
+		before being below between both but by can could did do does doing down during each few for from further
+		had has have having he her here hers herself him himself his how i if in into is it its itself just me
+		more most my myself no nor not now of off on once only or other our ours ourselves out over own same she
+		should so some such than that the their theirs them themselves then there these they this those through
+		to too under until up very was we were what when where which while who whom why will with would you your
+		yours yourself yourselves esp etc sb sth someone something one`) {
+		stopWords[w] = true
+	}
+}
+
+// tokenizeDefinition splits English text into lowercase, stemmed terms, dropping stop words,
+// single letters (such as part-of-speech labels) and numbers.
+func tokenizeDefinition(text string) []string {
+	var terms []string
+	for _, w := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool { return !unicode.IsLetter(r) }) {
+		if len([]rune(w)) < 2 || stopWords[w] {
+			continue
+		}
+		terms = append(terms, porterStem(w))
+	}
+	return terms
+}
+
+// definitionPosting is one document of a term's posting list.
+type definitionPosting struct {
+	id uint32
+	tf uint32
+}
+
+// definitionIndexBuilder collects term postings and document lengths of all definitions.
+type definitionIndexBuilder struct {
+	words    []string
+	lengths  []uint32
+	totalLen int
+	postings map[string][]definitionPosting
+}
+
+func newDefinitionIndexBuilder() indexBuilder {
+	return &definitionIndexBuilder{postings: make(map[string][]definitionPosting)}
+}
+
+func (db *definitionIndexBuilder) add(word string, fields map[string]string) {
+	terms := tokenizeDefinition(decodeEscapes(fields[FieldDefinition]))
+	if len(terms) == 0 {
+		return
+	}
+	id := uint32(len(db.words))
+	db.words = append(db.words, word)
+	db.lengths = append(db.lengths, uint32(len(terms)))
+	db.totalLen += len(terms)
+
+	tf := make(map[string]uint32, len(terms))
+	for _, t := range terms {
+		tf[t]++
+	}
+	for t, n := range tf {
+		db.postings[t] = append(db.postings[t], definitionPosting{id: id, tf: n})
+	}
+}
+
+func (db *definitionIndexBuilder) emit(put func(key, value []byte) error) error {
+	stats := definitionStats{Documents: len(db.words)}
+	if len(db.words) > 0 {
+		stats.AvgDocLength = float64(db.totalLen) / float64(len(db.words))
+	}
+	data, err := json.Marshal(stats)
+	if err != nil {
+		return err
+	}
+	if err := put([]byte(definitionStatsKey), data); err != nil {
+		return err
+	}
+
+	terms := make([]string, 0, len(db.postings))
+	for t := range db.postings {
+		terms = append(terms, t)
+	}
+	sort.Strings(terms)
+	var tmp [binary.MaxVarintLen32]byte
+	for _, t := range terms {
+		var buf bytes.Buffer
+		prev := uint32(0)
+		for _, p := range db.postings[t] {
+			buf.Write(tmp[:binary.PutUvarint(tmp[:], uint64(p.id-prev))])
+			buf.Write(tmp[:binary.PutUvarint(tmp[:], uint64(p.tf))])
+			prev = p.id
+		}
+		if err := put([]byte(definitionTermPrefix+t), buf.Bytes()); err != nil {
+			return err
+		}
+	}
+
+	for id, word := range db.words {
+		value := binary.AppendUvarint(nil, uint64(db.lengths[id]))
+		if err := put(definitionDocKey(uint32(id)), append(value, word...)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// definitionDocKey returns the key of a document id.
+func definitionDocKey(id uint32) []byte {
+	key := make([]byte, len(definitionDocPrefix)+4)
+	copy(key, definitionDocPrefix)
+	binary.BigEndian.PutUint32(key[len(definitionDocPrefix):], id)
+	return key
+}
+
+// decodeDefinitionPostings is the inverse of the posting encoding in emit.
+func decodeDefinitionPostings(data []byte) ([]definitionPosting, error) {
+	var postings []definitionPosting
+	prev := uint32(0)
+	for len(data) > 0 {
+		delta, n := binary.Uvarint(data)
+		if n <= 0 {
+			return nil, fmt.Errorf("corrupt posting list")
+		}
+		data = data[n:]
+		tf, n := binary.Uvarint(data)
+		if n <= 0 {
+			return nil, fmt.Errorf("corrupt posting list")
+		}
+		data = data[n:]
+		prev += uint32(delta)
+		postings = append(postings, definitionPosting{id: prev, tf: uint32(tf)})
+	}
+	return postings, nil
+}
+
+// bm25 scores one term of one document.
+func bm25(tf, docLen, df, documents int, avgDocLen float64) float64 {
+	idf := math.Log(1 + (float64(documents-df)+0.5)/(float64(df)+0.5))
+	norm := float64(tf) + bm25K1*(1-bm25B+bm25B*float64(docLen)/avgDocLen)
+	return idf * float64(tf) * (bm25K1 + 1) / norm
+}
+
+// Describe finds headwords whose English definition matches a description, ranked by BM25
+// (e.g. "fear of heights"). It uses the definition index when it was built and scans every
+// definition otherwise. A limit <= 0 returns all matches.
+func (s *DBStore) Describe(description string, limit int) ([]DescribeMatch, error) {
+	queryTerms := dedupe(tokenizeDefinition(description))
+	if len(queryTerms) == 0 {
+		return nil, nil
+	}
+
+	var matches []DescribeMatch
+	err := s.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(s.bucketName))
+		if b == nil {
+			return fmt.Errorf("bucket '%s' not found during Describe operation", s.bucketName)
+		}
+
+		var err error
+		if ib := tx.Bucket(s.indexBucketName(IndexDefinition)); ib != nil {
+			s.logger.Debug("Describe used definition index", zap.Strings("terms", queryTerms))
+			matches, err = describeFromIndex(ib, queryTerms)
+		} else {
+			s.logger.Debug("Definition index not built, scanning all definitions", zap.Strings("terms", queryTerms))
+			matches, err = s.describeByScan(b, queryTerms)
+		}
+		if err != nil {
+			return err
+		}
+
+		matches = orderDescribeMatches(matches, limit)
+
+		// Snippets are only needed for the results that are returned.
+		for i := range matches {
+			definition, _, err := DeserializeField(b.Get([]byte(matches[i].Word)), FieldDefinition)
+			if err != nil {
+				s.logger.Warn("Failed to deserialize value for snippet, skipping.", zap.String("word", matches[i].Word), zap.Error(err))
+				continue
+			}
+			matches[i].Snippet = definitionSnippet(decodeEscapes(definition), queryTerms)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return matches, nil
+}
+
+// MergeDescribeMatches merges the matches of several dictionaries, given in priority order, by
+// score. Scores are relative to each dictionary's definitions, so a small glossary's matches can
+// outrank a large dictionary's. A word found in several dictionaries is listed once.
+func MergeDescribeMatches(lists [][]DescribeMatch, limit int) []DescribeMatch {
+	return orderDescribeMatches(mergeResults(lists, func(m DescribeMatch) string { return m.Word }), limit)
+}
+
+// orderDescribeMatches sorts matches by descending score and applies limit.
+func orderDescribeMatches(matches []DescribeMatch, limit int) []DescribeMatch {
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		return matches[i].Word < matches[j].Word
+	})
+	if limit > 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches
+}
+
+// describeFromIndex scores the documents in the posting lists of the query terms.
+func describeFromIndex(ib *bolt.Bucket, queryTerms []string) ([]DescribeMatch, error) {
+	var stats definitionStats
+	if err := json.Unmarshal(ib.Get([]byte(definitionStatsKey)), &stats); err != nil {
+		return nil, fmt.Errorf("failed to decode definition index statistics: %w", err)
+	}
+
+	scores := make(map[uint32]float64)
+	docLens := make(map[uint32]int)
+	for _, term := range queryTerms {
+		postings, err := decodeDefinitionPostings(ib.Get([]byte(definitionTermPrefix + term)))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read definition index term '%s': %w", term, err)
+		}
+		for _, p := range postings {
+			docLen, ok := docLens[p.id]
+			if !ok {
+				doc := ib.Get(definitionDocKey(p.id))
+				n, size := binary.Uvarint(doc)
+				if size <= 0 {
+					return nil, fmt.Errorf("definition index document %d is corrupt", p.id)
+				}
+				docLen = int(n)
+				docLens[p.id] = docLen
+			}
+			scores[p.id] += bm25(int(p.tf), docLen, len(postings), stats.Documents, stats.AvgDocLength)
+		}
+	}
+
+	matches := make([]DescribeMatch, 0, len(scores))
+	for id, score := range scores {
+		doc := ib.Get(definitionDocKey(id))
+		_, size := binary.Uvarint(doc)
+		matches = append(matches, DescribeMatch{Word: string(doc[size:]), Score: score})
+	}
+	return matches, nil
+}
+
+// describeByScan computes the same BM25 scores as describeFromIndex in one pass over the bucket.
+func (s *DBStore) describeByScan(b *bolt.Bucket, queryTerms []string) ([]DescribeMatch, error) {
+	type candidate struct {
+		word   string
+		docLen int
+		tf     map[string]int
+	}
+	var candidates []candidate
+	df := make(map[string]int)
+	documents, totalLen := 0, 0
+
+	err := b.ForEach(func(k, v []byte) error {
+		definition, _, err := DeserializeField(v, FieldDefinition)
+		if err != nil {
+			s.logger.Warn("Failed to deserialize value for describe, skipping.", zap.ByteString("word", k), zap.Error(err))
+			return nil
+		}
+		terms := tokenizeDefinition(decodeEscapes(definition))
+		if len(terms) == 0 {
+			return nil
+		}
+		documents++
+		totalLen += len(terms)
+
+		var tf map[string]int
+		for _, t := range terms {
+			for _, q := range queryTerms {
+				if t == q {
+					if tf == nil {
+						tf = make(map[string]int)
+					}
+					tf[q]++
+				}
+			}
+		}
+		for q := range tf {
+			df[q]++
+		}
+		if tf != nil {
+			candidates = append(candidates, candidate{word: string(k), docLen: len(terms), tf: tf})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	matches := make([]DescribeMatch, 0, len(candidates))
+	for _, c := range candidates {
+		score := 0.0
+		for q, tf := range c.tf {
+			score += bm25(tf, c.docLen, df[q], documents, float64(totalLen)/float64(documents))
+		}
+		matches = append(matches, DescribeMatch{Word: c.word, Score: score})
+	}
+	return matches, nil
+}
+
+// definitionSnippet returns the definition line that contains the most query terms,
+// shortened to snippetMaxRunes.
+func definitionSnippet(definition string, queryTerms []string) string {
+	best, bestHits := "", -1
+	for _, line := range strings.Split(definition, "\n") {
+		hits := 0
+		for _, t := range tokenizeDefinition(line) {
+			for _, q := range queryTerms {
+				if t == q {
+					hits++
+				}
+			}
+		}
+		if hits > bestHits {
+			best, bestHits = strings.TrimSpace(line), hits
+		}
+	}
+	if runes := []rune(best); len(runes) > snippetMaxRunes {
+		best = string(runes[:snippetMaxRunes-1]) + "…"
+	}
+	return best
+}
@@ -0,0 +1,82 @@
+package bbolthelper
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+// describeTestRows adds definitions that share words with each other.
+var describeTestRows = append([]string{
+	`acrophobia,,n. an abnormal fear of heights,,,,,,,,,,`,
+	`claustrophobia,,n. an abnormal fear of closed spaces,,,,,,,,,,`,
+	`height,,"n. the vertical dimension of extension\nn. the highest level or degree attainable",,,,,,,,,,`,
+	`tirade,,n. a speech of violent denunciation,,,,,,,,,,`,
+	`filibuster,,"n. a tactic for delaying legislation by making long speeches\nv. obstruct deliberately",,,,,,,,,,`,
+}, testCSVRows...)
+
+func TestTokenizeDefinition(t *testing.T) {
+	got := tokenizeDefinition("n. An abnormal FEAR of heights; 2 words")
+	if want := []string{"abnorm", "fear", "height", "word"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("tokenizeDefinition() = %v, want %v", got, want)
+	}
+}
+
+func TestDBStore_Describe(t *testing.T) {
+	store := newTestStore(t, Config{BucketName: "TestBucket"})
+	if _, err := store.ImportFromCSV(writeTestCSV(t, t.TempDir(), describeTestRows), 0); err != nil {
+		t.Fatalf("ImportFromCSV() failed: %v", err)
+	}
+
+	tests := []struct {
+		description string
+		want        []string
+	}{
+		{"fear of heights", []string{"acrophobia", "claustrophobia"}},
+		{"a word for a long speech", []string{"filibuster", "tirade"}},
+		{"the of a", nil},
+		{"zebra", nil},
+	}
+	run := func() map[string][]DescribeMatch {
+		results := make(map[string][]DescribeMatch)
+		for _, tt := range tests {
+			got, err := store.Describe(tt.description, 0)
+			if err != nil {
+				t.Fatalf("Describe(%q) failed: %v", tt.description, err)
+			}
+			var words []string
+			for _, m := range got {
+				words = append(words, m.Word)
+			}
+			if !reflect.DeepEqual(words, tt.want) {
+				t.Errorf("Describe(%q) = %v, want %v", tt.description, words, tt.want)
+			}
+			results[tt.description] = got
+		}
+		return results
+	}
+
+	scan := run()
+	if err := store.BuildIndexes(IndexDefinition); err != nil {
+		t.Fatalf("BuildIndexes() failed: %v", err)
+	}
+	indexed := run()
+
+	// The index must reproduce the scores of the scan.
+	for description, matches := range scan {
+		for i, m := range matches {
+			if got := indexed[description][i]; got.Word != m.Word || math.Abs(got.Score-m.Score) > 1e-9 || got.Snippet != m.Snippet {
+				t.Errorf("Describe(%q)[%d] indexed = %+v, scanned = %+v", description, i, got, m)
+			}
+		}
+	}
+
+	got, _ := store.Describe("fear of heights", 1)
+	if len(got) != 1 || got[0].Snippet != "n. an abnormal fear of heights" {
+		t.Errorf("Describe(fear of heights, 1) = %+v, want acrophobia with its definition as snippet", got)
+	}
+	got, _ = store.Describe("highest level", 1)
+	if len(got) != 1 || got[0].Snippet != "n. the highest level or degree attainable" {
+		t.Errorf("Describe(highest level, 1) snippet = %+v, want the matching line", got)
+	}
+}
@@ -0,0 +1,328 @@
+package bbolthelper
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+	"go.uber.org/zap"
+)
+
+// Manifest describes a dictionary to its readers. A database can hold several dictionaries, one
+// per bucket; lookups across them list results by descending Priority.
+type Manifest struct {
+	// DisplayName is shown instead of the bucket name, e.g. "Engineering glossary".
+	DisplayName string `json:"display_name,omitempty"`
+	Priority    int    `json:"priority,omitempty"`
+	// Source says where the data comes from, e.g. a URL or an owning team.
+	Source string `json:"source,omitempty"`
+	// Supplement marks a dictionary whose fields fill in the entries of the other dictionaries,
+	// such as Wiktionary etymologies, rather than being listed on their own.
+	Supplement bool `json:"supplement,omitempty"`
+}
+
+// Label returns the display name of the dictionary, or its bucket name when it has none.
+func (m *Metadata) Label() string {
+	if m.DisplayName != "" {
+		return m.DisplayName
+	}
+	return m.Bucket
+}
+
+// copyOpenTimeout bounds how long CopyDictionaries and CheckReplaceable wait for the lock of the
+// source database.
+const copyOpenTimeout = 5 * time.Second
+
+// BucketName returns the name of the dictionary bucket the store reads and writes.
+func (s *DBStore) BucketName() string {
+	return s.bucketName
+}
+
+// Dictionaries returns the metadata of every dictionary in the database, by descending priority,
+// then with the default bucket first and then by bucket name. Dictionary buckets built without
+// metadata, as by kvbuilder before it recorded any, are listed with only their bucket name set.
+func (s *DBStore) Dictionaries() ([]*Metadata, error) {
+	var dicts []*Metadata
+	err := s.db.View(func(tx *bolt.Tx) error {
+		described := make(map[string]bool)
+		if b := tx.Bucket([]byte(MetaBucketName)); b != nil {
+			err := b.ForEach(func(k, v []byte) error {
+				if bytes.HasPrefix(k, []byte(checkpointKeyPrefix)) || tx.Bucket(k) == nil {
+					return nil
+				}
+				meta := &Metadata{}
+				if err := json.Unmarshal(v, meta); err != nil {
+					return fmt.Errorf("failed to decode metadata for bucket '%s': %w", k, err)
+				}
+				dicts = append(dicts, meta)
+				described[string(k)] = true
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return tx.ForEach(func(name []byte, b *bolt.Bucket) error {
+			if described[string(name)] || !isDictionaryBucket(tx, name) {
+				return nil
+			}
+			if k, _ := b.Cursor().First(); k != nil {
+				dicts = append(dicts, &Metadata{Bucket: string(name)})
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(dicts, func(i, j int) bool {
+		if dicts[i].Priority != dicts[j].Priority {
+			return dicts[i].Priority > dicts[j].Priority
+		}
+		if (dicts[i].Bucket == DefaultBucketName) != (dicts[j].Bucket == DefaultBucketName) {
+			return dicts[i].Bucket == DefaultBucketName
+		}
+		return dicts[i].Bucket < dicts[j].Bucket
+	})
+	return dicts, nil
+}
+
+// isDictionaryBucket reports whether a top-level bucket holds a dictionary rather than metadata or
+// the index or relation bucket "<dictionary>:<name>" of another one.
+func isDictionaryBucket(tx *bolt.Tx, name []byte) bool {
+	if string(name) == MetaBucketName {
+		return false
+	}
+	for i, c := range name {
+		if c == ':' && tx.Bucket(name[:i]) != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// FindDictionary returns the dictionary whose bucket name or display name is name, ignoring case.
+// The boolean is false when there is none.
+func (s *DBStore) FindDictionary(name string) (*Metadata, bool, error) {
+	dicts, err := s.Dictionaries()
+	if err != nil {
+		return nil, false, err
+	}
+	for _, d := range dicts {
+		if strings.EqualFold(d.Bucket, name) || strings.EqualFold(d.DisplayName, name) {
+			return d, true, nil
+		}
+	}
+	return nil, false, nil
+}
+
+// Dictionary returns a store for another dictionary bucket of the same database. Both stores share
+// the open database: closing either one closes it for both.
+func (s *DBStore) Dictionary(bucket string) *DBStore {
+	view := *s
+	view.bucketName = bucket
+	return &view
+}
+
+// ownsBucket reports whether a top-level bucket belongs to the store's dictionary: the dictionary
+// bucket itself or one of its index buckets.
+func (s *DBStore) ownsBucket(name []byte) bool {
+	return string(name) == s.bucketName || bytes.HasPrefix(name, []byte(s.bucketName+":"))
+}
+
+// CopyDictionaries copies every bucket of the database at srcPath that does not belong to the
+// store's dictionary into the store, together with the other dictionaries' metadata, replacing
+// buckets of the same name. kvbuilder uses it to rebuild one dictionary of a database without
+// touching the others. It returns the names of the copied top-level buckets.
+func (s *DBStore) CopyDictionaries(srcPath string) ([]string, error) {
+	src, err := bolt.Open(srcPath, s.dbFileMode, &bolt.Options{ReadOnly: true, Timeout: copyOpenTimeout})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open '%s' to copy its dictionaries: %w", srcPath, err)
+	}
+	defer src.Close()
+
+	var copied []string
+	err = src.View(func(srcTx *bolt.Tx) error {
+		return srcTx.ForEach(func(name []byte, b *bolt.Bucket) error {
+			if s.ownsBucket(name) {
+				return nil
+			}
+			if string(name) == MetaBucketName {
+				return s.copyForeignMetadata(b)
+			}
+			if err := s.db.Update(func(tx *bolt.Tx) error {
+				if err := tx.DeleteBucket(name); err != nil && err != bolt.ErrBucketNotFound {
+					return err
+				}
+				return nil
+			}); err != nil {
+				return fmt.Errorf("failed to drop bucket '%s' before copying: %w", name, err)
+			}
+			if err := s.copyBucket([][]byte{name}, b); err != nil {
+				return fmt.Errorf("failed to copy bucket '%s': %w", name, err)
+			}
+			copied = append(copied, string(name))
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.logger.Info("Copied other dictionaries", zap.String("from", srcPath), zap.Strings("buckets", copied))
+	return copied, nil
+}
+
+// CheckReplaceable returns an error if the database at srcPath holds a dictionary in the store's
+// bucket that was imported from another kind of source than format, so that a Tatoeba import
+// cannot silently replace ECDICT. Dictionaries without a recorded format count as "csv", and the
+// TableFormats may replace one another.
+func (s *DBStore) CheckReplaceable(srcPath, format string) error {
+	src, err := bolt.Open(srcPath, s.dbFileMode, &bolt.Options{ReadOnly: true, Timeout: copyOpenTimeout})
+	if err != nil {
+		return fmt.Errorf("failed to open '%s' to check bucket '%s': %w", srcPath, s.bucketName, err)
+	}
+	defer src.Close()
+
+	existing := ""
+	err = src.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(s.bucketName))
+		if b == nil {
+			return nil
+		}
+		if k, _ := b.Cursor().First(); k == nil {
+			return nil
+		}
+		existing = "csv"
+		meta := tx.Bucket([]byte(MetaBucketName))
+		if meta == nil {
+			return nil
+		}
+		data := meta.Get([]byte(s.bucketName))
+		if data == nil {
+			return nil
+		}
+		var m Metadata
+		if err := json.Unmarshal(data, &m); err != nil {
+			return fmt.Errorf("failed to decode metadata of bucket '%s': %w", s.bucketName, err)
+		}
+		if m.Format != "" {
+			existing = m.Format
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if existing == "" || existing == format ||
+		(slices.Contains(TableFormats, existing) && slices.Contains(TableFormats, format)) {
+		return nil
+	}
+	return fmt.Errorf("bucket '%s' of '%s' holds a dictionary imported from %s, not %s; choose another bucket with --dict",
+		s.bucketName, srcPath, existing, format)
+}
+
+// copyForeignMetadata copies the metadata entries of other dictionaries from a __meta__ bucket.
+func (s *DBStore) copyForeignMetadata(src *bolt.Bucket) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		dst, err := tx.CreateBucketIfNotExists([]byte(MetaBucketName))
+		if err != nil {
+			return fmt.Errorf("failed to create bucket '%s': %w", MetaBucketName, err)
+		}
+		return src.ForEach(func(k, v []byte) error {
+			if string(k) == s.bucketName || string(k) == checkpointKeyPrefix+s.bucketName {
+				return nil
+			}
+			return dst.Put(bytes.Clone(k), bytes.Clone(v))
+		})
+	})
+}
+
+// copyBucket copies src, including nested buckets, to the bucket at path in the store. Writes are
+// committed every compactTxMaxSize bytes so that large dictionaries are not held in memory.
+func (s *DBStore) copyBucket(path [][]byte, src *bolt.Bucket) error {
+	tx, err := s.db.Begin(true)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if tx != nil {
+			tx.Rollback()
+		}
+	}()
+
+	// bucketAt creates or opens the bucket at path within the current transaction.
+	bucketAt := func(path [][]byte) (*bolt.Bucket, error) {
+		b, err := tx.CreateBucketIfNotExists(path[0])
+		for _, name := range path[1:] {
+			if err != nil {
+				break
+			}
+			b, err = b.CreateBucketIfNotExists(name)
+		}
+		return b, err
+	}
+
+	size := 0
+	var walk func(path [][]byte, src *bolt.Bucket) error
+	walk = func(path [][]byte, src *bolt.Bucket) error {
+		dst, err := bucketAt(path)
+		if err != nil {
+			return err
+		}
+		c := src.Cursor()
+		for k, v := c.First(); k != nil; k, v = c.Next() {
+			if v == nil {
+				if err := walk(append(path[:len(path):len(path)], bytes.Clone(k)), src.Bucket(k)); err != nil {
+					return err
+				}
+				if dst, err = bucketAt(path); err != nil {
+					return err
+				}
+				continue
+			}
+			if size += len(k) + len(v); size > compactTxMaxSize {
+				if err := tx.Commit(); err != nil {
+					return err
+				}
+				if tx, err = s.db.Begin(true); err != nil {
+					return err
+				}
+				if dst, err = bucketAt(path); err != nil {
+					return err
+				}
+				size = 0
+			}
+			if err := dst.Put(bytes.Clone(k), bytes.Clone(v)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := walk(path, src); err != nil {
+		return err
+	}
+	err = tx.Commit()
+	tx = nil
+	return err
+}
+
+// mergeResults concatenates the results of several dictionaries, given in priority order, keeping
+// the first result of each word. Callers then sort the merged results as a single dictionary's.
+func mergeResults[T any](lists [][]T, word func(T) string) []T {
+	var merged []T
+	seen := make(map[string]bool)
+	for _, list := range lists {
+		for _, r := range list {
+			if w := word(r); !seen[w] {
+				seen[w] = true
+				merged = append(merged, r)
+			}
+		}
+	}
+	return merged
+}
@@ -0,0 +1,180 @@
+package bbolthelper
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// medicalTestRows form a second dictionary that shares "apple" with the base fixture.
+var medicalTestRows = []string{
+	`apple,,the fruit of the apple tree,,,,,,,,,,`,
+	`aspirin,,a drug that relieves pain,,,,,,,,,,`,
+}
+
+// newDictionariesTestDB imports the base fixture and a "medical" dictionary into one database.
+func newDictionariesTestDB(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "dicts.db")
+
+	medical := newTestStore(t, Config{DBPath: dbPath, BucketName: "medical"})
+	if _, err := medical.ImportCSV(t.Context(), writeTestCSV(t, dir, medicalTestRows), ImportOptions{
+		Manifest: Manifest{DisplayName: "Medical", Priority: 5, Source: "clinic word list"},
+	}); err != nil {
+		t.Fatalf("ImportCSV(medical) failed: %v", err)
+	}
+	if err := medical.BuildIndexes(IndexAnagrams); err != nil {
+		t.Fatalf("BuildIndexes(medical) failed: %v", err)
+	}
+	medical.Close()
+
+	base := newTestStore(t, Config{DBPath: dbPath})
+	if _, err := base.ImportCSV(t.Context(), writeTestCSV(t, t.TempDir(), testCSVRows), ImportOptions{}); err != nil {
+		t.Fatalf("ImportCSV(base) failed: %v", err)
+	}
+	base.Close()
+	return dbPath
+}
+
+func TestDBStore_Dictionaries(t *testing.T) {
+	store := newTestStore(t, Config{DBPath: newDictionariesTestDB(t), ReadOnly: true})
+
+	dicts, err := store.Dictionaries()
+	if err != nil {
+		t.Fatalf("Dictionaries() failed: %v", err)
+	}
+	var labels []string
+	for _, d := range dicts {
+		labels = append(labels, d.Label())
+	}
+	if want := []string{"Medical", DefaultBucketName}; !reflect.DeepEqual(labels, want) {
+		t.Errorf("Dictionaries() labels = %v, want %v in priority order", labels, want)
+	}
+	if d := dicts[0]; d.Bucket != "medical" || d.Priority != 5 || d.Source != "clinic word list" {
+		t.Errorf("Dictionaries()[0] manifest = %+v, want the medical manifest", d.Manifest)
+	}
+
+	meta, found, err := store.FindDictionary("MEDICAL")
+	if err != nil || !found || meta.Bucket != "medical" {
+		t.Fatalf("FindDictionary(MEDICAL) = %v, %v, %v; want the medical dictionary", meta, found, err)
+	}
+	if _, found, _ := store.FindDictionary("legal"); found {
+		t.Error("FindDictionary(legal) found a dictionary, want none")
+	}
+
+	// A dictionary view shares the database but reads its own bucket.
+	entry, found, err := store.Dictionary("medical").GetEntry("apple")
+	if err != nil || !found || entry.Definition != "the fruit of the apple tree" {
+		t.Errorf("Dictionary(medical).GetEntry(apple) = %+v, %v, %v", entry, found, err)
+	}
+	if _, found, _ := store.GetEntry("aspirin"); found {
+		t.Error("base GetEntry(aspirin) found the medical word")
+	}
+}
+
+func TestDBStore_Dictionaries_WithoutMetadata(t *testing.T) {
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "legacy.db")
+
+	// A database built before metadata was recorded holds only its entries.
+	legacy := newTestStore(t, Config{DBPath: dbPath})
+	if err := legacy.PutEntry(&Entry{Word: "go", Translation: "v. 去", Frq: 77}); err != nil {
+		t.Fatalf("PutEntry(go) failed: %v", err)
+	}
+	legacy.Close()
+
+	cedict := newTestStore(t, Config{DBPath: dbPath, BucketName: "cedict"})
+	if _, err := cedict.ImportCSV(t.Context(), writeTestCSV(t, dir, medicalTestRows), ImportOptions{
+		Manifest: Manifest{DisplayName: "CC-CEDICT"},
+	}); err != nil {
+		t.Fatalf("ImportCSV(cedict) failed: %v", err)
+	}
+	if err := cedict.BuildIndexes(IndexAnagrams); err != nil {
+		t.Fatalf("BuildIndexes(cedict) failed: %v", err)
+	}
+	cedict.Close()
+	// An empty bucket holds no dictionary.
+	newTestStore(t, Config{DBPath: dbPath, BucketName: "empty"}).Close()
+	cedict = newTestStore(t, Config{DBPath: dbPath, BucketName: "cedict", ReadOnly: true})
+
+	dicts, err := cedict.Dictionaries()
+	if err != nil {
+		t.Fatalf("Dictionaries() failed: %v", err)
+	}
+	var buckets []string
+	for _, d := range dicts {
+		buckets = append(buckets, d.Bucket)
+	}
+	if want := []string{DefaultBucketName, "cedict"}; !reflect.DeepEqual(buckets, want) {
+		t.Fatalf("Dictionaries() buckets = %v, want %v", buckets, want)
+	}
+	if d := dicts[0]; d.Label() != DefaultBucketName || d.Priority != 0 || d.Supplement {
+		t.Errorf("Dictionaries()[0] = %+v, want the default manifest", d)
+	}
+
+	ref, found, err := cedict.referenceDictionary()
+	if err != nil || !found {
+		t.Fatalf("referenceDictionary() = found %v, %v; want the dictionary without metadata", found, err)
+	}
+	if entry, found, _ := ref.GetEntry("go"); !found || entry.Frq != 77 {
+		t.Errorf("referenceDictionary().GetEntry(go) = %+v, %v", entry, found)
+	}
+}
+
+func TestDBStore_CopyDictionaries(t *testing.T) {
+	srcPath := newDictionariesTestDB(t)
+
+	// Rebuild the base dictionary in a new file, as kvbuilder does, keeping the medical one.
+	rebuilt := newTestStore(t, Config{DBPath: filepath.Join(t.TempDir(), "rebuilt.db")})
+	if _, err := rebuilt.ImportCSV(t.Context(), writeTestCSV(t, t.TempDir(), testCSVRows[:2]), ImportOptions{}); err != nil {
+		t.Fatalf("ImportCSV() failed: %v", err)
+	}
+	copied, err := rebuilt.CopyDictionaries(srcPath)
+	if err != nil {
+		t.Fatalf("CopyDictionaries() failed: %v", err)
+	}
+	if want := []string{"medical", "medical:anagrams"}; !reflect.DeepEqual(copied, want) {
+		t.Errorf("CopyDictionaries() copied %v, want %v", copied, want)
+	}
+
+	dicts, err := rebuilt.Dictionaries()
+	if err != nil || len(dicts) != 2 {
+		t.Fatalf("Dictionaries() = %v, %v; want 2 dictionaries", dicts, err)
+	}
+	meta, _, _ := rebuilt.Metadata()
+	if meta.RecordCount != 2 {
+		t.Errorf("rebuilt base metadata records %d, want its own 2", meta.RecordCount)
+	}
+	medical := rebuilt.Dictionary("medical")
+	if err := medical.Verify(); err != nil {
+		t.Errorf("copied medical dictionary does not verify: %v", err)
+	}
+	if !medical.HasIndex(IndexAnagrams) {
+		t.Error("copied medical dictionary lost its anagrams index")
+	}
+	if _, found, _ := rebuilt.GetEntry("apply"); found {
+		t.Error("rebuilt base dictionary contains a record of the old build")
+	}
+}
+
+func TestDBStore_CheckReplaceable(t *testing.T) {
+	srcPath := newDictionariesTestDB(t)
+	build := newTestStore(t, Config{DBPath: filepath.Join(t.TempDir(), "build.db")})
+
+	for _, tc := range []struct {
+		bucket, format string
+		wantErr        bool
+	}{
+		{DefaultBucketName, "csv", false},
+		{DefaultBucketName, "sqlite", false},
+		{DefaultBucketName, "tatoeba", true},
+		{"medical", "wiktionary", true},
+		{"tatoeba", "tatoeba", false},
+	} {
+		err := build.Dictionary(tc.bucket).CheckReplaceable(srcPath, tc.format)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("CheckReplaceable(%s, %s) = %v, want error %v", tc.bucket, tc.format, err, tc.wantErr)
+		}
+	}
+}
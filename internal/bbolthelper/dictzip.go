@@ -0,0 +1,194 @@
+package bbolthelper
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// gzip header flags (RFC 1952).
+const (
+	gzipFlagHCRC    = 1 << 1
+	gzipFlagExtra   = 1 << 2
+	gzipFlagName    = 1 << 3
+	gzipFlagComment = 1 << 4
+)
+
+// errNotDictzip reports a gzip file that cannot be read at random offsets.
+var errNotDictzip = errors.New("gzip file has no dictzip chunk table")
+
+// dictzipReader gives random access to a dictzip file: a gzip file whose deflate stream is flushed
+// every chunkLen uncompressed bytes, with the compressed size of each chunk listed in the "RA"
+// extra field of the header. Reading at an offset inflates only the chunks it covers.
+type dictzipReader struct {
+	r        io.ReaderAt
+	chunkLen int64
+	// offsets holds the file offset of every chunk, followed by the end of the last one.
+	offsets []int64
+
+	cached int
+	cache  []byte
+}
+
+// newDictzipReader parses the dictzip header of r. It returns errNotDictzip for gzip files without
+// chunk information.
+func newDictzipReader(r io.ReaderAt) (*dictzipReader, error) {
+	var hdr [12]byte
+	if _, err := r.ReadAt(hdr[:10], 0); err != nil {
+		return nil, fmt.Errorf("failed to read gzip header: %w", err)
+	}
+	if hdr[0] != 0x1f || hdr[1] != 0x8b || hdr[2] != 8 {
+		return nil, errors.New("not a gzip file")
+	}
+	flags := hdr[3]
+	if flags&gzipFlagExtra == 0 {
+		return nil, errNotDictzip
+	}
+	if _, err := r.ReadAt(hdr[10:12], 10); err != nil {
+		return nil, fmt.Errorf("failed to read gzip extra field: %w", err)
+	}
+	extra := make([]byte, binary.LittleEndian.Uint16(hdr[10:12]))
+	if _, err := r.ReadAt(extra, 12); err != nil {
+		return nil, fmt.Errorf("failed to read gzip extra field: %w", err)
+	}
+	pos := int64(12 + len(extra))
+
+	d := &dictzipReader{r: r, cached: -1}
+	var sizes []byte
+	for p := extra; len(p) >= 4; {
+		id, n := string(p[:2]), int(binary.LittleEndian.Uint16(p[2:4]))
+		if len(p) < 4+n {
+			return nil, errors.New("truncated gzip extra field")
+		}
+		if data := p[4 : 4+n]; id == "RA" && len(data) >= 6 {
+			d.chunkLen = int64(binary.LittleEndian.Uint16(data[2:4]))
+			count := int(binary.LittleEndian.Uint16(data[4:6]))
+			if len(data) < 6+2*count {
+				return nil, errors.New("truncated dictzip chunk table")
+			}
+			sizes = data[6 : 6+2*count]
+		}
+		p = p[4+n:]
+	}
+	if d.chunkLen == 0 {
+		return nil, errNotDictzip
+	}
+
+	// The file name and comment are zero-terminated; the header CRC is two bytes.
+	for _, flag := range []byte{gzipFlagName, gzipFlagComment} {
+		if flags&flag == 0 {
+			continue
+		}
+		var b [1]byte
+		for {
+			if _, err := r.ReadAt(b[:], pos); err != nil {
+				return nil, fmt.Errorf("failed to read gzip header: %w", err)
+			}
+			pos++
+			if b[0] == 0 {
+				break
+			}
+		}
+	}
+	if flags&gzipFlagHCRC != 0 {
+		pos += 2
+	}
+
+	d.offsets = append(d.offsets, pos)
+	for i := 0; i < len(sizes); i += 2 {
+		pos += int64(binary.LittleEndian.Uint16(sizes[i:]))
+		d.offsets = append(d.offsets, pos)
+	}
+	return d, nil
+}
+
+// chunk returns the uncompressed data of chunk i.
+func (d *dictzipReader) chunk(i int) ([]byte, error) {
+	if i == d.cached {
+		return d.cache, nil
+	}
+	compressed := make([]byte, d.offsets[i+1]-d.offsets[i])
+	if _, err := d.r.ReadAt(compressed, d.offsets[i]); err != nil {
+		return nil, fmt.Errorf("failed to read dictzip chunk %d: %w", i, err)
+	}
+	// Chunks end with a full flush rather than a final block, so a complete chunk still reads
+	// as an unexpected EOF.
+	data, err := io.ReadAll(flate.NewReader(bytes.NewReader(compressed)))
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return nil, fmt.Errorf("failed to inflate dictzip chunk %d: %w", i, err)
+	}
+	d.cached, d.cache = i, data
+	return data, nil
+}
+
+// ReadAt reads uncompressed data starting at off.
+func (d *dictzipReader) ReadAt(p []byte, off int64) (int, error) {
+	n := 0
+	for n < len(p) {
+		i := int((off + int64(n)) / d.chunkLen)
+		if i >= len(d.offsets)-1 {
+			return n, io.EOF
+		}
+		data, err := d.chunk(i)
+		if err != nil {
+			return n, err
+		}
+		start := (off + int64(n)) % d.chunkLen
+		if start >= int64(len(data)) {
+			return n, io.EOF
+		}
+		n += copy(p[n:], data[start:])
+	}
+	return n, nil
+}
+
+// openCompressible opens a file that may be stored plain or gzip-compressed and returns a reader
+// for random access to its contents, and the file to close when done. Dictzip files are inflated
+// chunk by chunk; other gzip files are inflated into memory.
+func openCompressible(path string) (io.ReaderAt, io.Closer, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	var magic [2]byte
+	if _, err := f.ReadAt(magic[:], 0); err != nil || magic != [2]byte{0x1f, 0x8b} {
+		return f, f, nil // Plain file, or too short to be compressed
+	}
+
+	dz, err := newDictzipReader(f)
+	if err == nil {
+		return dz, f, nil
+	}
+	if !errors.Is(err, errNotDictzip) {
+		f.Close()
+		return nil, nil, fmt.Errorf("failed to read '%s': %w", path, err)
+	}
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read '%s': %w", path, err)
+	}
+	data, err := io.ReadAll(zr)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to decompress '%s': %w", path, err)
+	}
+	return bytes.NewReader(data), io.NopCloser(nil), nil
+}
+
+// readCompressible returns the contents of a plain or gzip-compressed file.
+func readCompressible(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil || !bytes.HasPrefix(data, []byte{0x1f, 0x8b}) {
+		return data, err
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(zr)
+}
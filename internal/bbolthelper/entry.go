@@ -0,0 +1,340 @@
+package bbolthelper
+
+import (
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Field names of an ECDICT record as they appear in the CSV header and in the stored value map.
+// The headword itself ("word", column 0) is the bucket key and is not stored in the value map.
+const (
+	FieldPhonetic    = "phonetic"
+	FieldDefinition  = "definition"
+	FieldTranslation = "translation"
+	FieldPOS         = "pos"
+	FieldCollins     = "collins"
+	FieldOxford      = "oxford"
+	FieldTag         = "tag"
+	FieldBNC         = "bnc"
+	FieldFrq         = "frq"
+	FieldExchange    = "exchange"
+	FieldDetail      = "detail"
+	FieldAudio       = "audio"
+	// FieldPinyin is the Mandarin reading of Chinese headwords, as imported from CC-CEDICT.
+	FieldPinyin = "pinyin"
+	// FieldEtymology is the origin of a word, as imported from Wiktionary.
+	FieldEtymology = "etymology"
+	// FieldExamples holds example sentences with their translations, as imported from Tatoeba.
+	FieldExamples = "examples"
+)
+
+// exchangeTypeNames maps the single-character ECDICT inflection codes to readable descriptions.
+// Codes "0" and "1" are not listed: they hold the lemma of an inflected form and the inflection
+// codes of the form, not a form (see ExchangeLemma).
+var exchangeTypeNames = map[string]string{
+	"p": "past tense",
+	"d": "past participle",
+	"i": "present participle",
+	"3": "third person singular",
+	"r": "comparative",
+	"t": "superlative",
+	"s": "plural",
+}
+
+// ExchangeTypeName returns a readable description for an ECDICT exchange code (e.g. "p" -> "past tense").
+// Unknown codes are returned unchanged.
+func ExchangeTypeName(code string) string {
+	if name, ok := exchangeTypeNames[code]; ok {
+		return name
+	}
+	return code
+}
+
+// ExchangeLemma returns the lemma an inflected form's exchange names under code "0", and the
+// descriptions of the inflections listed under code "1" (e.g. "0:go/1:p" -> "go", ["past tense"]).
+func ExchangeLemma(ex map[string]string) (string, []string) {
+	var types []string
+	for _, code := range ex["1"] {
+		types = append(types, ExchangeTypeName(string(code)))
+	}
+	return ex["0"], types
+}
+
+// POSShare is one part-of-speech entry of the ECDICT "pos" distribution, e.g. "v:54".
+type POSShare struct {
+	Tag     string `json:"tag"`
+	Percent int    `json:"percent"`
+}
+
+// Example is an example sentence of a headword with its translation.
+type Example struct {
+	Sentence    string `json:"sentence"`
+	Translation string `json:"translation,omitempty"`
+}
+
+// Entry is the typed form of a dictionary record.
+// Numeric ECDICT columns are parsed to ints (0 when empty or malformed), the literal "\n", "\r"
+// and "\t" escapes used by ECDICT in text columns are decoded, and structured columns
+// (tag, pos, exchange) are split into their components.
+type Entry struct {
+	Word        string            `json:"word"`
+	Phonetic    string            `json:"phonetic,omitempty"`
+	Definition  string            `json:"definition,omitempty"`
+	Translation string            `json:"translation,omitempty"`
+	POS         []POSShare        `json:"pos,omitempty"`
+	Collins     int               `json:"collins,omitempty"`
+	Oxford      bool              `json:"oxford,omitempty"`
+	Tags        []string          `json:"tags,omitempty"`
+	BNC         int               `json:"bnc,omitempty"`
+	Frq         int               `json:"frq,omitempty"`
+	Exchange    map[string]string `json:"exchange,omitempty"`
+	Detail      string            `json:"detail,omitempty"`
+	Audio       string            `json:"audio,omitempty"`
+	Pinyin      string            `json:"pinyin,omitempty"`
+	Etymology   string            `json:"etymology,omitempty"`
+	Examples    []Example         `json:"examples,omitempty"`
+	// Extra holds any stored fields that are not part of the ECDICT schema, keyed by field name.
+	Extra map[string]string `json:"extra,omitempty"`
+}
+
+// ParseEntry converts a stored value map into an Entry for the given headword.
+func ParseEntry(word string, fields map[string]string) *Entry {
+	e := &Entry{Word: word}
+	for k, v := range fields {
+		switch k {
+		case FieldPhonetic:
+			e.Phonetic = v
+		case FieldDefinition:
+			e.Definition = decodeEscapes(v)
+		case FieldTranslation:
+			e.Translation = decodeEscapes(v)
+		case FieldPOS:
+			e.POS = ParsePOS(v)
+		case FieldCollins:
+			e.Collins = atoiOrZero(v)
+		case FieldOxford:
+			e.Oxford = atoiOrZero(v) != 0
+		case FieldTag:
+			e.Tags = strings.Fields(v)
+		case FieldBNC:
+			e.BNC = atoiOrZero(v)
+		case FieldFrq:
+			e.Frq = atoiOrZero(v)
+		case FieldExchange:
+			e.Exchange = ParseExchange(v)
+		case FieldDetail:
+			e.Detail = decodeEscapes(v)
+		case FieldAudio:
+			e.Audio = v
+		case FieldPinyin:
+			e.Pinyin = v
+		case FieldEtymology:
+			e.Etymology = decodeEscapes(v)
+		case FieldExamples:
+			e.Examples = ParseExamples(v)
+		default:
+			if e.Extra == nil {
+				e.Extra = make(map[string]string)
+			}
+			e.Extra[k] = v
+		}
+	}
+	return e
+}
+
+// Map converts the Entry back into the stored value map form, re-encoding escapes
+// and structured columns the way ECDICT writes them. Empty fields are omitted.
+func (e *Entry) Map() map[string]string {
+	m := make(map[string]string, 15+len(e.Extra))
+	set := func(k, v string) {
+		if v != "" {
+			m[k] = v
+		}
+	}
+	setInt := func(k string, v int) {
+		if v != 0 {
+			m[k] = strconv.Itoa(v)
+		}
+	}
+	set(FieldPhonetic, e.Phonetic)
+	set(FieldDefinition, encodeEscapes(e.Definition))
+	set(FieldTranslation, encodeEscapes(e.Translation))
+	set(FieldPOS, FormatPOS(e.POS))
+	setInt(FieldCollins, e.Collins)
+	if e.Oxford {
+		m[FieldOxford] = "1"
+	}
+	set(FieldTag, strings.Join(e.Tags, " "))
+	setInt(FieldBNC, e.BNC)
+	setInt(FieldFrq, e.Frq)
+	set(FieldExchange, FormatExchange(e.Exchange))
+	set(FieldDetail, encodeEscapes(e.Detail))
+	set(FieldAudio, e.Audio)
+	set(FieldPinyin, e.Pinyin)
+	set(FieldEtymology, encodeEscapes(e.Etymology))
+	set(FieldExamples, FormatExamples(e.Examples))
+	for k, v := range e.Extra {
+		set(k, v)
+	}
+	return m
+}
+
+// Supplement fills the empty text fields of e from other, such as the etymology and pronunciation
+// of a Wiktionary entry. Fields e already has are kept.
+func (e *Entry) Supplement(other *Entry) {
+	for _, f := range []struct {
+		dst *string
+		src string
+	}{
+		{&e.Phonetic, other.Phonetic},
+		{&e.Definition, other.Definition},
+		{&e.Translation, other.Translation},
+		{&e.Detail, other.Detail},
+		{&e.Audio, other.Audio},
+		{&e.Pinyin, other.Pinyin},
+		{&e.Etymology, other.Etymology},
+	} {
+		if *f.dst == "" {
+			*f.dst = f.src
+		}
+	}
+	if len(e.Examples) == 0 {
+		e.Examples = other.Examples
+	}
+	for k, v := range other.Extra {
+		if _, ok := e.Extra[k]; !ok {
+			if e.Extra == nil {
+				e.Extra = make(map[string]string)
+			}
+			e.Extra[k] = v
+		}
+	}
+}
+
+// HasTag reports whether the entry carries the given ECDICT tag (e.g. "gre", "cet4").
+func (e *Entry) HasTag(tag string) bool {
+	for _, t := range e.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// ParsePOS parses an ECDICT pos column such as "n:46/v:54" into its shares, in source order.
+// Malformed parts are skipped.
+func ParsePOS(s string) []POSShare {
+	var shares []POSShare
+	for _, part := range strings.Split(s, "/") {
+		tag, pct, ok := strings.Cut(strings.TrimSpace(part), ":")
+		if !ok || tag == "" {
+			continue
+		}
+		shares = append(shares, POSShare{Tag: tag, Percent: atoiOrZero(pct)})
+	}
+	return shares
+}
+
+// FormatPOS is the inverse of ParsePOS.
+func FormatPOS(shares []POSShare) string {
+	parts := make([]string, len(shares))
+	for i, p := range shares {
+		parts[i] = p.Tag + ":" + strconv.Itoa(p.Percent)
+	}
+	return strings.Join(parts, "/")
+}
+
+// ParseExchange parses an ECDICT exchange column such as "p:went/d:gone/i:going/3:goes"
+// into a map keyed by exchange code. Malformed parts are skipped.
+func ParseExchange(s string) map[string]string {
+	var ex map[string]string
+	for _, part := range strings.Split(s, "/") {
+		code, form, ok := strings.Cut(strings.TrimSpace(part), ":")
+		if !ok || code == "" || form == "" {
+			continue
+		}
+		if ex == nil {
+			ex = make(map[string]string)
+		}
+		ex[code] = form
+	}
+	return ex
+}
+
+// exchangeCodeOrder is the order in which ECDICT writes exchange codes.
+var exchangeCodeOrder = []string{"p", "d", "i", "3", "r", "t", "s", "0", "1"}
+
+// FormatExchange is the inverse of ParseExchange. Known codes are written in ECDICT order,
+// followed by any unknown codes in sorted order.
+func FormatExchange(ex map[string]string) string {
+	codes := make([]string, 0, len(ex))
+	for _, code := range exchangeCodeOrder {
+		if _, ok := ex[code]; ok {
+			codes = append(codes, code)
+		}
+	}
+	var unknown []string
+	for code := range ex {
+		if !slices.Contains(exchangeCodeOrder, code) {
+			unknown = append(unknown, code)
+		}
+	}
+	sort.Strings(unknown)
+	codes = append(codes, unknown...)
+	parts := make([]string, len(codes))
+	for i, code := range codes {
+		parts[i] = code + ":" + ex[code]
+	}
+	return strings.Join(parts, "/")
+}
+
+// ParseExamples parses a stored examples column: one example per "\n"-escaped line, with the
+// sentence and its translation separated by an escaped tab.
+func ParseExamples(s string) []Example {
+	var examples []Example
+	for _, line := range strings.Split(decodeEscapes(s), "\n") {
+		sentence, translation, _ := strings.Cut(line, "\t")
+		if sentence = strings.TrimSpace(sentence); sentence != "" {
+			examples = append(examples, Example{Sentence: sentence, Translation: strings.TrimSpace(translation)})
+		}
+	}
+	return examples
+}
+
+// FormatExamples is the inverse of ParseExamples.
+func FormatExamples(examples []Example) string {
+	lines := make([]string, len(examples))
+	for i, ex := range examples {
+		lines[i] = ex.Sentence
+		if ex.Translation != "" {
+			lines[i] += "\t" + ex.Translation
+		}
+	}
+	return encodeEscapes(strings.Join(lines, "\n"))
+}
+
+var (
+	escapeDecoder = strings.NewReplacer(`\n`, "\n", `\r`, "\r", `\t`, "\t")
+	escapeEncoder = strings.NewReplacer("\n", `\n`, "\r", `\r`, "\t", `\t`)
+)
+
+// decodeEscapes turns the literal "\n", "\r" and "\t" sequences used in ECDICT text columns into real characters.
+func decodeEscapes(s string) string {
+	return escapeDecoder.Replace(s)
+}
+
+// encodeEscapes is the inverse of decodeEscapes.
+func encodeEscapes(s string) string {
+	return escapeEncoder.Replace(s)
+}
+
+// atoiOrZero parses s as a base-10 int, returning 0 for empty or malformed input.
+func atoiOrZero(s string) int {
+	n, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil {
+		return 0
+	}
+	return n
+}
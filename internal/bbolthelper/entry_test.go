@@ -0,0 +1,130 @@
+package bbolthelper
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestParseEntry(t *testing.T) {
+	fields := map[string]string{
+		"phonetic":    "gəʊ",
+		"definition":  `v. move\nn. a time for working`,
+		"translation": `v. 去, 走\nn. 尝试`,
+		"pos":         "v:92/n:8",
+		"collins":     "5",
+		"oxford":      "1",
+		"tag":         "zk gk cet4",
+		"bnc":         "38",
+		"frq":         "42",
+		"exchange":    "p:went/d:gone/i:going/3:goes",
+		"custom":      "kept",
+	}
+
+	got := ParseEntry("go", fields)
+	want := &Entry{
+		Word:        "go",
+		Phonetic:    "gəʊ",
+		Definition:  "v. move\nn. a time for working",
+		Translation: "v. 去, 走\nn. 尝试",
+		POS:         []POSShare{{Tag: "v", Percent: 92}, {Tag: "n", Percent: 8}},
+		Collins:     5,
+		Oxford:      true,
+		Tags:        []string{"zk", "gk", "cet4"},
+		BNC:         38,
+		Frq:         42,
+		Exchange:    map[string]string{"p": "went", "d": "gone", "i": "going", "3": "goes"},
+		Extra:       map[string]string{"custom": "kept"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("ParseEntry() got = %+v, want %+v", got, want)
+	}
+	if !got.HasTag("cet4") || got.HasTag("gre") {
+		t.Errorf("HasTag() returned unexpected results for tags %v", got.Tags)
+	}
+
+	// Map must reproduce the stored form so entries round-trip through Put.
+	if m := got.Map(); !reflect.DeepEqual(m, fields) {
+		t.Errorf("Map() got = %v, want %v", m, fields)
+	}
+}
+
+func TestParseEntry_MalformedFields(t *testing.T) {
+	got := ParseEntry("x", map[string]string{
+		"collins":  "",
+		"frq":      "n/a",
+		"oxford":   "0",
+		"pos":      "bogus/v:",
+		"exchange": "p:/:x/d:done",
+	})
+	if got.Collins != 0 || got.Frq != 0 || got.Oxford {
+		t.Errorf("numeric fields should default to zero values, got %+v", got)
+	}
+	if want := []POSShare{{Tag: "v", Percent: 0}}; !reflect.DeepEqual(got.POS, want) {
+		t.Errorf("POS got = %v, want %v", got.POS, want)
+	}
+	if want := map[string]string{"d": "done"}; !reflect.DeepEqual(got.Exchange, want) {
+		t.Errorf("Exchange got = %v, want %v", got.Exchange, want)
+	}
+}
+
+func TestDBStore_PutGetEntry(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "bbolthelper_entry_test_*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	store, err := NewDBStore(Config{DBPath: filepath.Join(tempDir, "entry.db"), Logger: zap.NewNop()})
+	if err != nil {
+		t.Fatalf("NewDBStore() failed: %v", err)
+	}
+	defer store.Close()
+
+	entry := &Entry{Word: "apple", Translation: "n. 苹果", Frq: 2000, Tags: []string{"zk"}}
+	if err := store.PutEntry(entry); err != nil {
+		t.Fatalf("PutEntry() failed: %v", err)
+	}
+
+	got, found, err := store.GetEntry("apple")
+	if err != nil || !found {
+		t.Fatalf("GetEntry() found = %v, err = %v", found, err)
+	}
+	if !reflect.DeepEqual(got, entry) {
+		t.Errorf("GetEntry() got = %+v, want %+v", got, entry)
+	}
+
+	got, found, err = store.GetEntry("missing")
+	if err != nil || found || got != nil {
+		t.Errorf("GetEntry(missing) got = %v, %v, %v; want nil, false, nil", got, found, err)
+	}
+
+	if err := store.PutEntry(&Entry{}); err == nil {
+		t.Errorf("PutEntry() with empty word should fail")
+	}
+}
+
+func TestEntry_Supplement(t *testing.T) {
+	e := &Entry{Word: "go", Definition: "v. move", Translation: "v. 去", Extra: map[string]string{"note": "ecdict"}}
+	e.Supplement(&Entry{
+		Word:       "go",
+		Phonetic:   "ɡəʊ",
+		Definition: "v. To move through space.",
+		Etymology:  "From Middle English gon",
+		Extra:      map[string]string{"note": "wiktionary", "usage": "common"},
+	})
+	want := &Entry{
+		Word:        "go",
+		Phonetic:    "ɡəʊ",
+		Definition:  "v. move",
+		Translation: "v. 去",
+		Etymology:   "From Middle English gon",
+		Extra:       map[string]string{"note": "ecdict", "usage": "common"},
+	}
+	if !reflect.DeepEqual(e, want) {
+		t.Errorf("Supplement() = %+v, want %+v", e, want)
+	}
+}
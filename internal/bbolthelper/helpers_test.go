@@ -0,0 +1,50 @@
+package bbolthelper
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+// testCSVHeader is the ECDICT column layout used by the test fixtures.
+const testCSVHeader = "word,phonetic,definition,translation,pos,collins,oxford,tag,bnc,frq,exchange,detail,audio"
+
+// testCSVRows is a small ECDICT-shaped fixture shared by the import tests.
+var testCSVRows = []string{
+	`go,gəʊ,"v. move\nn. a turn",v. 去\nn. 尝试,v:92/n:8,5,1,zk gk cet4,38,42,p:went/d:gone/i:going/3:goes,,`,
+	`went,went,,v. 去(go的过去式),,,,,,1200,0:go/1:p,,`,
+	`apple,ˈæpl,n. fruit with red or green skin,n. 苹果,n:100,5,1,zk gk,1500,2000,s:apples,,`,
+	`apply,əˈplaɪ,v. put into service,v. 应用,v:100,5,1,cet4,700,800,p:applied/3:applies,,`,
+	`Listen,ˈlisn,v. hear with intention,v. 听,v:100,5,1,zk,900,700,p:listened,,`,
+}
+
+// writeTestCSV writes the header and rows to a CSV file in dir and returns its path.
+func writeTestCSV(t *testing.T, dir string, rows []string) string {
+	t.Helper()
+	path := filepath.Join(dir, "test.csv")
+	content := testCSVHeader + "\n" + strings.Join(rows, "\n") + "\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write test CSV: %v", err)
+	}
+	return path
+}
+
+// newTestStore opens a writable store in a fresh temporary directory, closed when the test ends.
+func newTestStore(t *testing.T, cfg Config) *DBStore {
+	t.Helper()
+	if cfg.DBPath == "" {
+		cfg.DBPath = filepath.Join(t.TempDir(), "test.db")
+	}
+	if cfg.Logger == nil {
+		cfg.Logger = zap.NewNop()
+	}
+	store, err := NewDBStore(cfg)
+	if err != nil {
+		t.Fatalf("NewDBStore() failed: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store
+}
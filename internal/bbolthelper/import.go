@@ -0,0 +1,421 @@
+package bbolthelper
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+	"go.uber.org/zap"
+)
+
+// DefaultImportBatchSize is the number of records committed per transaction when ImportOptions.BatchSize is unset.
+const DefaultImportBatchSize = 20000
+
+// checkpointKeyPrefix prefixes the __meta__ key under which a bucket's import checkpoint is stored.
+const checkpointKeyPrefix = "checkpoint:"
+
+// ImportOptions controls how ImportCSV commits and resumes an import.
+type ImportOptions struct {
+	// BatchSize is the number of records written per transaction. Defaults to DefaultImportBatchSize.
+	BatchSize int
+	// ProgressReportInterval logs a progress message every N records. 0 disables progress logging.
+	ProgressReportInterval int
+	// Workers is the number of goroutines parsing and serializing rows. Values above 1 enable a
+	// pipelined import with a single reader, parallel workers and an ordered writer; the stored
+	// contents are identical to a sequential import.
+	Workers int
+	// Resume continues from the checkpoint left by an interrupted import of the same file.
+	// Without a usable checkpoint the import starts from the beginning.
+	Resume bool
+	// Manifest is recorded in the build metadata of the dictionary.
+	Manifest Manifest
+}
+
+// ImportCheckpoint records how far an interrupted import got. It is committed together with
+// every batch and removed when the import completes.
+type ImportCheckpoint struct {
+	Bucket        string    `json:"bucket"`
+	SourceFile    string    `json:"source_file"`
+	SourceSize    int64     `json:"source_size"`
+	SourceModTime time.Time `json:"source_mod_time"`
+	Header        []string  `json:"header"`
+	// Offset is the CSV byte offset just past the last committed row.
+	Offset int64 `json:"offset"`
+	// Row is the number of data rows (excluding the header) consumed up to Offset.
+	Row int `json:"row"`
+	// Records is the number of records stored up to Offset.
+	Records   int       `json:"records"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ImportFromCSV reads records from a CSV file and stores them in the BoltDB database.
+// It returns the number of records processed and an error if any occurred.
+// It is equivalent to ImportCSV with default batching and no resume.
+func (s *DBStore) ImportFromCSV(csvFilePath string, progressReportInterval int) (int, error) {
+	return s.ImportCSV(context.Background(), csvFilePath, ImportOptions{ProgressReportInterval: progressReportInterval})
+}
+
+// ImportCSV reads records from a CSV file and stores them in the BoltDB database, committing
+// every opts.BatchSize records together with a checkpoint. When ctx is cancelled the import stops
+// after committing the records read so far and returns ctx.Err(); the import can later be
+// continued with opts.Resume. On success the build metadata is written and the checkpoint removed.
+// It returns the total number of records stored by this import, including resumed batches.
+func (s *DBStore) ImportCSV(ctx context.Context, csvFilePath string, opts ImportOptions) (int, error) {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = DefaultImportBatchSize
+	}
+	s.logger.Info("Starting CSV import...", zap.String("sourceCsv", csvFilePath), zap.Int("batchSize", opts.BatchSize))
+
+	csvFile, err := os.Open(csvFilePath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open CSV file '%s': %w", csvFilePath, err)
+	}
+	defer csvFile.Close()
+	fi, err := csvFile.Stat()
+	if err != nil {
+		return 0, fmt.Errorf("failed to stat CSV file '%s': %w", csvFilePath, err)
+	}
+	absPath, err := filepath.Abs(csvFilePath)
+	if err != nil {
+		absPath = csvFilePath
+	}
+
+	// Hash the file while it is being parsed so the metadata can identify the exact source.
+	hasher := sha256.New()
+	reader := csv.NewReader(io.TeeReader(csvFile, hasher))
+	header, err := reader.Read() // Read the header row
+	if err != nil {
+		if err == io.EOF {
+			return 0, fmt.Errorf("CSV file '%s' is empty or has no header", csvFilePath)
+		}
+		return 0, fmt.Errorf("failed to read header from CSV '%s': %w", csvFilePath, err)
+	}
+
+	if len(header) < 1 {
+		return 0, fmt.Errorf("CSV file '%s' header is invalid (too few columns)", csvFilePath)
+	}
+
+	cp := &ImportCheckpoint{
+		Bucket:        s.bucketName,
+		SourceFile:    absPath,
+		SourceSize:    fi.Size(),
+		SourceModTime: fi.ModTime().UTC(),
+		Header:        header,
+		Offset:        reader.InputOffset(),
+	}
+
+	if opts.Resume {
+		prev, found, err := s.ImportCheckpoint()
+		if err != nil {
+			return 0, err
+		}
+		switch {
+		case !found:
+			s.logger.Info("No import checkpoint found, starting from the beginning.", zap.String("bucketName", s.bucketName))
+		case !prev.matches(cp):
+			return 0, fmt.Errorf("import checkpoint for bucket '%s' was made from '%s' (%d bytes, modified %s), which does not match '%s'; rerun without resume to start over",
+				s.bucketName, prev.SourceFile, prev.SourceSize, prev.SourceModTime.Format(time.RFC3339), absPath)
+		default:
+			s.logger.Info("Resuming CSV import from checkpoint",
+				zap.Int64("offset", prev.Offset), zap.Int("row", prev.Row), zap.Int("records", prev.Records))
+			if _, err := csvFile.Seek(prev.Offset, io.SeekStart); err != nil {
+				return 0, fmt.Errorf("failed to seek CSV '%s' to offset %d: %w", csvFilePath, prev.Offset, err)
+			}
+			// The streaming hash no longer sees the whole file; it is computed separately at the end.
+			hasher = nil
+			reader = csv.NewReader(csvFile)
+			reader.FieldsPerRecord = len(header)
+			cp = prev
+		}
+	}
+	baseOffset := cp.Offset - reader.InputOffset()
+
+	nextRow := s.sequentialRows(reader, header, baseOffset, csvFilePath)
+	if opts.Workers > 1 {
+		var stop func()
+		nextRow, stop = s.pipelineRows(reader, header, baseOffset, csvFilePath, opts.Workers)
+		defer stop()
+	}
+
+	s.logger.Info("Processing CSV records...", zap.String("csvPath", csvFilePath), zap.Int("workers", max(opts.Workers, 1)))
+	batch := make([]keyValue, 0, opts.BatchSize)
+	for {
+		row, ok := nextRow()
+		if !ok {
+			break // End of file
+		}
+		cp.Row++
+		cp.Offset = row.offset
+		if row.kv != nil {
+			batch = append(batch, *row.kv)
+		}
+
+		stopping := ctx.Err() != nil
+		if len(batch) < opts.BatchSize && !stopping {
+			continue
+		}
+		if err := s.commitBatch(batch, cp, opts.ProgressReportInterval); err != nil {
+			return cp.Records, err
+		}
+		batch = batch[:0]
+		if stopping {
+			s.logger.Warn("CSV import interrupted at a batch boundary; it can be resumed.",
+				zap.Int("row", cp.Row), zap.Int("records", cp.Records))
+			return cp.Records, ctx.Err()
+		}
+	}
+
+	// Final batch: store the remaining records, the build metadata and drop the checkpoint atomically.
+	var sum string
+	if hasher != nil {
+		sum = hex.EncodeToString(hasher.Sum(nil))
+	} else if sum, err = fileSHA256(csvFilePath); err != nil {
+		return cp.Records, err
+	}
+	recordsProcessed := cp.Records + len(batch)
+	err = s.db.Update(func(tx *bolt.Tx) error {
+		b, err := s.putBatch(tx, batch)
+		if err != nil {
+			return err
+		}
+		if err := tx.Bucket([]byte(MetaBucketName)).Delete([]byte(checkpointKeyPrefix + s.bucketName)); err != nil {
+			return fmt.Errorf("failed to remove import checkpoint: %w", err)
+		}
+		return putMetadata(tx, &Metadata{
+			Bucket:         s.bucketName,
+			Manifest:       opts.Manifest,
+			Format:         "csv",
+			SourceFile:     filepath.Base(csvFilePath),
+			SourceSHA256:   sum,
+			RecordCount:    countKeys(b),
+			HeaderColumns:  header,
+			BuildTime:      time.Now().UTC(),
+			BuilderVersion: s.builder,
+			SchemaVersion:  SchemaVersion,
+			Codec:          s.codec.String(),
+		})
+	})
+	if err != nil {
+		// This error comes from db.Update if the transaction itself failed (e.g., disk full, permissions)
+		return cp.Records, fmt.Errorf("failed during bbolt transaction for CSV import: %w", err)
+	}
+
+	s.logger.Info("Successfully imported records from CSV.",
+		zap.Int("totalRecords", recordsProcessed),
+		zap.String("dbPath", s.dbPath),
+		zap.String("bucketName", s.bucketName),
+	)
+	return recordsProcessed, nil
+}
+
+// ImportCheckpoint returns the checkpoint of an unfinished import into the store's bucket, if any.
+func (s *DBStore) ImportCheckpoint() (*ImportCheckpoint, bool, error) {
+	var cp *ImportCheckpoint
+	err := s.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(MetaBucketName))
+		if b == nil {
+			return nil
+		}
+		data := b.Get([]byte(checkpointKeyPrefix + s.bucketName))
+		if data == nil {
+			return nil
+		}
+		cp = &ImportCheckpoint{}
+		if err := json.Unmarshal(data, cp); err != nil {
+			return fmt.Errorf("failed to decode import checkpoint for bucket '%s': %w", s.bucketName, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	return cp, cp != nil, nil
+}
+
+// matches reports whether a stored checkpoint was made from the same source file as cur.
+func (cp *ImportCheckpoint) matches(cur *ImportCheckpoint) bool {
+	return cp.SourceFile == cur.SourceFile &&
+		cp.SourceSize == cur.SourceSize &&
+		cp.SourceModTime.Equal(cur.SourceModTime) &&
+		slices.Equal(cp.Header, cur.Header)
+}
+
+// keyValue is a serialized record ready to be written.
+type keyValue struct {
+	key   string
+	value []byte
+}
+
+// recordToValueMap turns a CSV record into its key and value map using the header for field names.
+// It returns false for records that must be skipped.
+func (s *DBStore) recordToValueMap(header, record []string, csvFilePath string) (string, map[string]string, bool) {
+	if len(record) < 1 {
+		s.logger.Warn("Empty record found in CSV, skipping.", zap.String("csvPath", csvFilePath))
+		return "", nil, false
+	}
+
+	key := strings.ToLower(record[0])
+	valueMap := make(map[string]string)
+
+	for i := 1; i < len(record); i++ {
+		if i < len(header) {
+			valueMap[header[i]] = record[i]
+		} else {
+			s.logger.Warn("Record has more columns than header, extra columns ignored.", zap.String("key", key), zap.String("csvPath", csvFilePath))
+		}
+	}
+	return key, valueMap, true
+}
+
+// putBatch writes serialized records into the store's bucket within an existing transaction.
+// Records that fail to be stored are logged and skipped.
+func (s *DBStore) putBatch(tx *bolt.Tx, batch []keyValue) (*bolt.Bucket, error) {
+	b := tx.Bucket([]byte(s.bucketName))
+	if b == nil {
+		// This should ideally not happen if NewDBStore correctly created the bucket.
+		return nil, fmt.Errorf("bucket '%s' unexpectedly not found during CSV import", s.bucketName)
+	}
+	if _, err := tx.CreateBucketIfNotExists([]byte(MetaBucketName)); err != nil {
+		return nil, fmt.Errorf("failed to create bucket '%s': %w", MetaBucketName, err)
+	}
+	for _, kv := range batch {
+		if err := s.putCore(tx, kv.key, kv.value); err != nil {
+			// For robustness, we'll log and skip the problematic record.
+			// A more critical error (like transaction failure) would be returned by db.Update's main error.
+			s.logger.Error("Failed to put record into DB using putCore, record skipped", zap.String("key", kv.key), zap.Error(err))
+		}
+	}
+	return b, nil
+}
+
+// commitBatch writes a batch and the updated checkpoint in one transaction.
+func (s *DBStore) commitBatch(batch []keyValue, cp *ImportCheckpoint, progressReportInterval int) error {
+	before := cp.Records
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		if _, err := s.putBatch(tx, batch); err != nil {
+			return err
+		}
+		next := *cp
+		next.Records += len(batch)
+		next.UpdatedAt = time.Now().UTC()
+		data, err := json.Marshal(&next)
+		if err != nil {
+			return fmt.Errorf("failed to encode import checkpoint: %w", err)
+		}
+		return tx.Bucket([]byte(MetaBucketName)).Put([]byte(checkpointKeyPrefix+s.bucketName), data)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to commit import batch ending at row %d: %w", cp.Row, err)
+	}
+	cp.Records += len(batch)
+	if progressReportInterval > 0 && cp.Records/progressReportInterval > before/progressReportInterval {
+		s.logger.Info("Processed records milestone", zap.Int("count", cp.Records), zap.Int64("offset", cp.Offset))
+	}
+	return nil
+}
+
+// fileSHA256 returns the hex SHA-256 digest of a file.
+func fileSHA256(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open '%s' for hashing: %w", path, err)
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", fmt.Errorf("failed to hash '%s': %w", path, err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// recordSource yields the records of a dictionary in a format other than CSV, as a key and its
+// value map. It returns io.EOF after the last record; an empty key skips a record.
+type recordSource func() (string, map[string]string, error)
+
+// importRecords stores the records of next in batches of opts.BatchSize and completes meta with
+// the record count and build details in the final transaction. It is the ImportCSV loop for
+// formats that cannot be resumed: when ctx is cancelled it returns ctx.Err() without committing
+// the current batch, and a later import has to start over.
+func (s *DBStore) importRecords(ctx context.Context, next recordSource, meta *Metadata, opts ImportOptions) (int, error) {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = DefaultImportBatchSize
+	}
+	if opts.Resume {
+		s.logger.Warn("Resume is only supported for CSV imports, importing from the beginning.", zap.String("source", meta.SourceFile))
+	}
+
+	records := 0
+	batch := make([]keyValue, 0, opts.BatchSize)
+	for {
+		key, fields, err := next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return records, err
+		}
+		if key == "" {
+			continue
+		}
+		value, err := SerializeWith(s.codec, fields)
+		if err != nil {
+			s.logger.Error("Failed to serialize record, skipping", zap.String("key", key), zap.Error(err))
+			continue
+		}
+		batch = append(batch, keyValue{key: key, value: value})
+		if len(batch) < opts.BatchSize {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return records, err
+		}
+		if err := s.db.Update(func(tx *bolt.Tx) error {
+			_, err := s.putBatch(tx, batch)
+			return err
+		}); err != nil {
+			return records, fmt.Errorf("failed to commit import batch ending at record %d: %w", records+len(batch), err)
+		}
+		before := records
+		records += len(batch)
+		batch = batch[:0]
+		if opts.ProgressReportInterval > 0 && records/opts.ProgressReportInterval > before/opts.ProgressReportInterval {
+			s.logger.Info("Processed records milestone", zap.Int("count", records))
+		}
+	}
+
+	// Final batch: store the remaining records and the build metadata atomically.
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		b, err := s.putBatch(tx, batch)
+		if err != nil {
+			return err
+		}
+		meta.Bucket = s.bucketName
+		meta.Manifest = opts.Manifest
+		meta.RecordCount = countKeys(b)
+		meta.BuildTime = time.Now().UTC()
+		meta.BuilderVersion = s.builder
+		meta.SchemaVersion = SchemaVersion
+		meta.Codec = s.codec.String()
+		return putMetadata(tx, meta)
+	})
+	if err != nil {
+		return records, fmt.Errorf("failed during bbolt transaction for import: %w", err)
+	}
+	records += len(batch)
+	s.logger.Info("Successfully imported records.",
+		zap.Int("totalRecords", records),
+		zap.String("source", meta.SourceFile),
+		zap.String("bucketName", s.bucketName),
+	)
+	return records, nil
+}
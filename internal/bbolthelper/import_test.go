@@ -0,0 +1,110 @@
+package bbolthelper
+
+import (
+	"context"
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestImportCSV_Batches(t *testing.T) {
+	csvPath := writeTestCSV(t, t.TempDir(), testCSVRows)
+	store := newTestStore(t, Config{})
+
+	n, err := store.ImportCSV(context.Background(), csvPath, ImportOptions{BatchSize: 2})
+	if err != nil {
+		t.Fatalf("ImportCSV() error = %v", err)
+	}
+	if n != len(testCSVRows) {
+		t.Errorf("ImportCSV() = %d, want %d", n, len(testCSVRows))
+	}
+	// Keys are lowercased, as with the single-transaction import.
+	if _, found, _ := store.Get("listen"); !found {
+		t.Errorf("Get(listen) not found after batched import")
+	}
+	if _, found, err := store.ImportCheckpoint(); found || err != nil {
+		t.Errorf("ImportCheckpoint() after a completed import found = %v, err = %v", found, err)
+	}
+}
+
+func TestImportCSV_InterruptAndResume(t *testing.T) {
+	dir := t.TempDir()
+	csvPath := writeTestCSV(t, dir, testCSVRows)
+
+	// Reference: a full import in one go.
+	want := newTestStore(t, Config{})
+	if _, err := want.ImportFromCSV(csvPath, 0); err != nil {
+		t.Fatalf("ImportFromCSV() error = %v", err)
+	}
+	wantMeta, _, _ := want.Metadata()
+
+	store := newTestStore(t, Config{})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	n, err := store.ImportCSV(ctx, csvPath, ImportOptions{BatchSize: 2})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("ImportCSV() with cancelled context error = %v, want context.Canceled", err)
+	}
+	if n != 1 {
+		t.Errorf("ImportCSV() committed %d records before stopping, want 1", n)
+	}
+	cp, found, err := store.ImportCheckpoint()
+	if err != nil || !found {
+		t.Fatalf("ImportCheckpoint() found = %v, err = %v", found, err)
+	}
+	if cp.Row != 1 || cp.Records != 1 || cp.Offset == 0 {
+		t.Errorf("ImportCheckpoint() = %+v, want row 1, records 1 and a non-zero offset", cp)
+	}
+	if _, found, _ := store.Metadata(); found {
+		t.Errorf("Metadata() should not be written for an interrupted import")
+	}
+
+	n, err = store.ImportCSV(context.Background(), csvPath, ImportOptions{BatchSize: 2, Resume: true})
+	if err != nil {
+		t.Fatalf("resumed ImportCSV() error = %v", err)
+	}
+	if n != len(testCSVRows) {
+		t.Errorf("resumed ImportCSV() = %d, want %d", n, len(testCSVRows))
+	}
+	for _, key := range []string{"go", "went", "apple", "apply", "listen"} {
+		got, _, _ := store.Get(key)
+		exp, _, _ := want.Get(key)
+		if !reflect.DeepEqual(got, exp) {
+			t.Errorf("Get(%s) after resume = %v, want %v", key, got, exp)
+		}
+	}
+	meta, found, err := store.Metadata()
+	if err != nil || !found {
+		t.Fatalf("Metadata() found = %v, err = %v", found, err)
+	}
+	if meta.SourceSHA256 != wantMeta.SourceSHA256 || meta.RecordCount != wantMeta.RecordCount {
+		t.Errorf("Metadata() after resume = %+v, want sha %s and %d records", meta, wantMeta.SourceSHA256, wantMeta.RecordCount)
+	}
+	if _, found, _ := store.ImportCheckpoint(); found {
+		t.Errorf("ImportCheckpoint() should be removed after the resumed import completes")
+	}
+}
+
+func TestImportCSV_ResumeRejectsChangedSource(t *testing.T) {
+	csvPath := writeTestCSV(t, t.TempDir(), testCSVRows)
+	store := newTestStore(t, Config{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := store.ImportCSV(ctx, csvPath, ImportOptions{BatchSize: 2}); !errors.Is(err, context.Canceled) {
+		t.Fatalf("ImportCSV() error = %v, want context.Canceled", err)
+	}
+
+	// Rewrite the source with different content and a different modification time.
+	if err := os.WriteFile(csvPath, []byte(testCSVHeader+"\n"+testCSVRows[0]+"\n"), 0644); err != nil {
+		t.Fatalf("Failed to rewrite CSV: %v", err)
+	}
+	later := time.Now().Add(time.Hour)
+	os.Chtimes(csvPath, later, later)
+
+	if _, err := store.ImportCSV(context.Background(), csvPath, ImportOptions{Resume: true}); err == nil {
+		t.Errorf("ImportCSV() resuming from a changed source should fail")
+	}
+}
@@ -0,0 +1,174 @@
+package bbolthelper
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	bolt "go.etcd.io/bbolt"
+	"go.uber.org/zap"
+)
+
+// Names of the secondary indexes that BuildIndexes can build. Each index is stored in its own
+// top-level bucket named "<dictionary bucket>:<index name>".
+const (
+	// IndexInflections maps inflected forms to their lemmas, built from the exchange field.
+	IndexInflections = "inflections"
+)
+
+// DefaultIndexes are the indexes kvbuilder builds unless told otherwise.
+var DefaultIndexes = []string{IndexInflections, IndexRadix, IndexTranslation, IndexDefinition, IndexAnagrams, IndexRhymes, IndexMetaphone, IndexAttributes}
+
+// indexBuilder accumulates one secondary index while BuildIndexes scans the dictionary bucket.
+type indexBuilder interface {
+	// add is called once per dictionary record, in key order.
+	add(word string, fields map[string]string)
+	// emit writes the finished index through put, in any key order.
+	emit(put func(key, value []byte) error) error
+}
+
+// indexBuilders lists the constructors of all known indexes by name.
+var indexBuilders = map[string]func() indexBuilder{
+	IndexAnagrams:    newAnagramIndexBuilder,
+	IndexAttributes:  newAttributeIndexBuilder,
+	IndexDefinition:  newDefinitionIndexBuilder,
+	IndexInflections: newInflectionIndexBuilder,
+	IndexMetaphone:   newMetaphoneIndexBuilder,
+	IndexRadix:       newRadixIndexBuilder,
+	IndexRhymes:      newRhymeIndexBuilder,
+	IndexSymSpell:    newSymSpellIndexBuilder,
+	IndexTranslation: newTranslationIndexBuilder,
+}
+
+// AvailableIndexes returns the names of all indexes BuildIndexes accepts, sorted.
+func AvailableIndexes() []string {
+	names := make([]string, 0, len(indexBuilders))
+	for name := range indexBuilders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// indexBucketName returns the bucket holding the given index of the store's dictionary.
+func (s *DBStore) indexBucketName(index string) []byte {
+	return []byte(s.bucketName + ":" + index)
+}
+
+// HasIndex reports whether the given index has been built for the store's dictionary.
+func (s *DBStore) HasIndex(index string) bool {
+	found := false
+	s.db.View(func(tx *bolt.Tx) error {
+		found = tx.Bucket(s.indexBucketName(index)) != nil
+		return nil
+	})
+	return found
+}
+
+// BuildIndexes (re)builds the named secondary indexes from the dictionary bucket in a single scan.
+// Each index replaces its previous bucket in one transaction, so readers see either the old or the
+// new index. The names of the built indexes are added to the bucket's metadata, when present.
+func (s *DBStore) BuildIndexes(indexes ...string) error {
+	builders := make(map[string]indexBuilder, len(indexes))
+	for _, name := range indexes {
+		newBuilder, ok := indexBuilders[name]
+		if !ok {
+			return fmt.Errorf("unknown index '%s' (available: %s)", name, strings.Join(AvailableIndexes(), ", "))
+		}
+		builders[name] = newBuilder()
+	}
+	if len(builders) == 0 {
+		return nil
+	}
+
+	s.logger.Info("Building secondary indexes", zap.Strings("indexes", indexes), zap.String("bucketName", s.bucketName))
+	err := s.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(s.bucketName))
+		if b == nil {
+			return fmt.Errorf("bucket '%s' not found during index build", s.bucketName)
+		}
+		return b.ForEach(func(k, v []byte) error {
+			fields, err := Deserialize(v)
+			if err != nil {
+				s.logger.Warn("Failed to deserialize record for indexing, skipping.", zap.ByteString("word", k), zap.Error(err))
+				return nil
+			}
+			for _, builder := range builders {
+				builder.add(string(k), fields)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	for name, builder := range builders {
+		bucketName := s.indexBucketName(name)
+		count := 0
+		err := s.db.Update(func(tx *bolt.Tx) error {
+			if err := tx.DeleteBucket(bucketName); err != nil && err != bolt.ErrBucketNotFound {
+				return fmt.Errorf("failed to drop old index bucket '%s': %w", bucketName, err)
+			}
+			ib, err := tx.CreateBucket(bucketName)
+			if err != nil {
+				return fmt.Errorf("failed to create index bucket '%s': %w", bucketName, err)
+			}
+			return builder.emit(func(key, value []byte) error {
+				count++
+				return ib.Put(key, value)
+			})
+		})
+		if err != nil {
+			return fmt.Errorf("failed to build index '%s': %w", name, err)
+		}
+		s.logger.Info("Secondary index built", zap.String("index", name), zap.Int("keys", count))
+	}
+
+	return s.recordIndexes(indexes)
+}
+
+// recordIndexes adds the given index names to the metadata of the store's bucket, if it has any.
+func (s *DBStore) recordIndexes(indexes []string) error {
+	meta, found, err := s.Metadata()
+	if err != nil || !found {
+		return err
+	}
+	for _, name := range indexes {
+		if !slices.Contains(meta.Indexes, name) {
+			meta.Indexes = append(meta.Indexes, name)
+		}
+	}
+	sort.Strings(meta.Indexes)
+	return s.WriteMetadata(meta)
+}
+
+// emitSortedLists writes a map of string lists through put in sorted key order, joining each
+// deduplicated list with sep. Sorted insertion keeps bolt pages densely packed.
+func emitSortedLists(m map[string][]string, sep string, put func(key, value []byte) error) error {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if err := put([]byte(k), []byte(strings.Join(dedupe(m[k]), sep))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dedupe removes repeated strings from list, keeping the first occurrence of each.
+func dedupe(list []string) []string {
+	seen := make(map[string]bool, len(list))
+	out := list[:0]
+	for _, v := range list {
+		if !seen[v] {
+			seen[v] = true
+			out = append(out, v)
+		}
+	}
+	return out
+}
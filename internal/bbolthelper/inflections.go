@@ -0,0 +1,93 @@
+package bbolthelper
+
+import (
+	"fmt"
+	"strings"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// inflectionCodes are the exchange codes that name an inflected form of the record's headword.
+var inflectionCodes = []string{"p", "d", "i", "3", "r", "t", "s"}
+
+// Lemma is a base form of an inflected word, as recorded by the inflections index.
+type Lemma struct {
+	Lemma string `json:"lemma"`
+	// Type is the ECDICT exchange code of the inflection (e.g. "p" for past tense).
+	// It is empty when the source only records that the word is some form of the lemma.
+	Type string `json:"type,omitempty"`
+}
+
+// Description returns a readable name for the inflection type, e.g. "past tense".
+func (l Lemma) Description() string {
+	if l.Type == "" {
+		return "inflected form"
+	}
+	return ExchangeTypeName(l.Type)
+}
+
+// inflectionIndexBuilder collects form -> "code:lemma" pairs. Values are stored in the
+// exchange mini-format, e.g. "left" -> "p:leave/d:leave".
+type inflectionIndexBuilder struct {
+	forms map[string][]string
+}
+
+func newInflectionIndexBuilder() indexBuilder {
+	return &inflectionIndexBuilder{forms: make(map[string][]string)}
+}
+
+func (ib *inflectionIndexBuilder) add(word string, fields map[string]string) {
+	ex := ParseExchange(fields[FieldExchange])
+	if len(ex) == 0 {
+		return
+	}
+	// Forward links: the lemma lists its inflected forms ("go" has "p:went").
+	for _, code := range inflectionCodes {
+		if form := strings.ToLower(ex[code]); form != "" && form != word {
+			ib.forms[form] = append(ib.forms[form], code+":"+word)
+		}
+	}
+	// Backward links: an inflected form names its lemma ("went" has "0:go/1:p").
+	if lemma := strings.ToLower(ex["0"]); lemma != "" && lemma != word {
+		codes := ex["1"]
+		if codes == "" {
+			ib.forms[word] = append(ib.forms[word], ":"+lemma)
+		}
+		for _, code := range codes {
+			ib.forms[word] = append(ib.forms[word], string(code)+":"+lemma)
+		}
+	}
+}
+
+func (ib *inflectionIndexBuilder) emit(put func(key, value []byte) error) error {
+	return emitSortedLists(ib.forms, "/", put)
+}
+
+// Lemmas returns the lemmas of an inflected form (e.g. "went" -> past tense of "go"), using the
+// inflections index. A word can be a form of several lemmas or of one lemma in several ways.
+// It returns nil without error when the word is not an inflected form or the index was not built.
+func (s *DBStore) Lemmas(form string) ([]Lemma, error) {
+	var lemmas []Lemma
+	err := s.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket(s.indexBucketName(IndexInflections))
+		if b == nil {
+			return nil
+		}
+		v := b.Get([]byte(strings.ToLower(form)))
+		if v == nil {
+			return nil
+		}
+		for _, part := range strings.Split(string(v), "/") {
+			code, lemma, ok := strings.Cut(part, ":")
+			if !ok || lemma == "" {
+				return fmt.Errorf("malformed inflections index value for '%s': %q", form, v)
+			}
+			lemmas = append(lemmas, Lemma{Lemma: lemma, Type: code})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return lemmas, nil
+}
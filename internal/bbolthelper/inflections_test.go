@@ -0,0 +1,93 @@
+package bbolthelper
+
+import (
+	"reflect"
+	"testing"
+)
+
+// newIndexedTestStore imports testCSVRows into a fresh store and builds the given indexes.
+func newIndexedTestStore(t *testing.T, indexes ...string) *DBStore {
+	t.Helper()
+	store := newTestStore(t, Config{BucketName: "TestBucket"})
+	csvPath := writeTestCSV(t, t.TempDir(), testCSVRows)
+	if _, err := store.ImportFromCSV(csvPath, 0); err != nil {
+		t.Fatalf("ImportFromCSV() failed: %v", err)
+	}
+	if err := store.BuildIndexes(indexes...); err != nil {
+		t.Fatalf("BuildIndexes(%v) failed: %v", indexes, err)
+	}
+	return store
+}
+
+func TestDBStore_Lemmas(t *testing.T) {
+	store := newIndexedTestStore(t, IndexInflections)
+
+	tests := []struct {
+		form string
+		want []Lemma
+	}{
+		// "went" is linked from both "go" (p:went) and itself (0:go/1:p); it must appear once.
+		{"went", []Lemma{{Lemma: "go", Type: "p"}}},
+		{"Goes", []Lemma{{Lemma: "go", Type: "3"}}},
+		{"applied", []Lemma{{Lemma: "apply", Type: "p"}}},
+		{"apples", []Lemma{{Lemma: "apple", Type: "s"}}},
+		{"go", nil},
+		{"unknown", nil},
+	}
+	for _, tt := range tests {
+		got, err := store.Lemmas(tt.form)
+		if err != nil {
+			t.Fatalf("Lemmas(%q) failed: %v", tt.form, err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Lemmas(%q) = %v, want %v", tt.form, got, tt.want)
+		}
+	}
+
+	if got := (Lemma{Lemma: "go", Type: "p"}).Description(); got != "past tense" {
+		t.Errorf("Description() = %q, want %q", got, "past tense")
+	}
+}
+
+func TestDBStore_BuildIndexes(t *testing.T) {
+	store := newIndexedTestStore(t, IndexInflections)
+
+	if !store.HasIndex(IndexInflections) {
+		t.Errorf("HasIndex(%q) = false after BuildIndexes", IndexInflections)
+	}
+	meta, found, err := store.Metadata()
+	if err != nil || !found {
+		t.Fatalf("Metadata() = found %v, err %v", found, err)
+	}
+	if !reflect.DeepEqual(meta.Indexes, []string{IndexInflections}) {
+		t.Errorf("Metadata().Indexes = %v, want [%s]", meta.Indexes, IndexInflections)
+	}
+	// The index bucket must not be mistaken for dictionary data.
+	if err := store.Verify(); err != nil {
+		t.Errorf("Verify() after BuildIndexes failed: %v", err)
+	}
+
+	// Rebuilding replaces the index instead of appending to it.
+	if err := store.BuildIndexes(IndexInflections); err != nil {
+		t.Fatalf("second BuildIndexes() failed: %v", err)
+	}
+	if got, _ := store.Lemmas("went"); len(got) != 1 {
+		t.Errorf("Lemmas(went) after rebuild = %v, want a single lemma", got)
+	}
+
+	if err := store.BuildIndexes("no-such-index"); err == nil {
+		t.Error("BuildIndexes() with an unknown index succeeded, want error")
+	}
+}
+
+func TestDBStore_LemmasWithoutIndex(t *testing.T) {
+	store := newIndexedTestStore(t)
+
+	if store.HasIndex(IndexInflections) {
+		t.Errorf("HasIndex(%q) = true without BuildIndexes", IndexInflections)
+	}
+	got, err := store.Lemmas("went")
+	if err != nil || got != nil {
+		t.Errorf("Lemmas(went) without index = %v, %v; want nil, nil", got, err)
+	}
+}
@@ -0,0 +1,159 @@
+package bbolthelper
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Mapping describes how the columns of a tabular source become records: which column holds the
+// headword, what the other columns are called in the stored value map, which are dropped and how
+// their values are transformed. The zero Mapping stores the first column as the lowercased key
+// and every other column under its own name, as ImportCSV does.
+type Mapping struct {
+	// Table is the SQLite table to read. It defaults to the only table of the database.
+	Table string `json:"table,omitempty"`
+	// Key is the column holding the headword. It defaults to the first column.
+	Key string `json:"key,omitempty"`
+	// KeepKeyCase stores headwords as they are instead of lowercasing them. ne lowercases the
+	// words it looks up, so it only finds lowercase headwords.
+	KeepKeyCase bool `json:"keep_key_case,omitempty"`
+	// Rename maps source columns to field names, e.g. "gloss" to "translation".
+	Rename map[string]string `json:"rename,omitempty"`
+	// Drop lists the source columns that are not stored.
+	Drop []string `json:"drop,omitempty"`
+	// Transforms lists the value transforms applied to a source column, in order, e.g.
+	// {"gloss": ["trim", "lines:;"]}. See ValueTransformNames.
+	Transforms map[string][]string `json:"transforms,omitempty"`
+}
+
+// BuiltinMappings are the mappings that can be named instead of given as a file.
+var BuiltinMappings = map[string]Mapping{
+	// ecdict reads the stardict table of ECDICT's stardict.db release. "sw" is the headword
+	// stripped for fuzzy matching, which ne does not use.
+	"ecdict": {Table: "stardict", Key: "word", Drop: []string{"id", "sw"}},
+}
+
+// valueTransforms are the value transforms a Mapping can apply, by name. A transform can take an
+// argument after a colon, as in "lines:;".
+var valueTransforms = map[string]func(v, arg string) string{
+	// trim removes leading and trailing white space.
+	"trim":  func(v, _ string) string { return strings.TrimSpace(v) },
+	"lower": func(v, _ string) string { return strings.ToLower(v) },
+	"upper": func(v, _ string) string { return strings.ToUpper(v) },
+	// strip-html reduces HTML to plain text lines.
+	"strip-html": func(v, _ string) string { return markupText(v) },
+	// lines splits the value at every occurrence of its argument, e.g. "lines:;", into trimmed
+	// lines. Empty parts are dropped.
+	"lines": func(v, sep string) string {
+		if sep == "" {
+			return v
+		}
+		var lines []string
+		for _, part := range strings.Split(v, sep) {
+			if part = strings.TrimSpace(part); part != "" {
+				lines = append(lines, part)
+			}
+		}
+		return strings.Join(lines, "\n")
+	},
+	// prefix prepends its argument to non-empty values, e.g. "prefix:n. ".
+	"prefix": func(v, p string) string {
+		if v == "" {
+			return v
+		}
+		return p + v
+	},
+}
+
+// ValueTransformNames returns the names of the value transforms, sorted.
+func ValueTransformNames() []string {
+	names := make([]string, 0, len(valueTransforms))
+	for name := range valueTransforms {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LoadMapping returns the built-in mapping with the given name, or reads a mapping from a JSON
+// file with the fields of Mapping.
+func LoadMapping(nameOrPath string) (Mapping, error) {
+	if m, ok := BuiltinMappings[nameOrPath]; ok {
+		return m, nil
+	}
+	data, err := os.ReadFile(nameOrPath)
+	if err != nil {
+		return Mapping{}, fmt.Errorf("failed to read mapping '%s': %w", nameOrPath, err)
+	}
+	var m Mapping
+	if err := json.Unmarshal(data, &m); err != nil {
+		return Mapping{}, fmt.Errorf("failed to parse mapping '%s': %w", nameOrPath, err)
+	}
+	if err := m.Validate(); err != nil {
+		return Mapping{}, fmt.Errorf("invalid mapping '%s': %w", nameOrPath, err)
+	}
+	return m, nil
+}
+
+// Validate reports unknown value transforms.
+func (m *Mapping) Validate() error {
+	for col, names := range m.Transforms {
+		for _, name := range names {
+			name, _, _ = strings.Cut(name, ":")
+			if _, ok := valueTransforms[name]; !ok {
+				return fmt.Errorf("unknown transform '%s' for column '%s' (want one of %s)", name, col, strings.Join(ValueTransformNames(), ", "))
+			}
+		}
+	}
+	return nil
+}
+
+// transform applies the transforms of a column to a value.
+func (m *Mapping) transform(col, v string) string {
+	for _, t := range m.Transforms[col] {
+		name, arg, _ := strings.Cut(t, ":")
+		if fn, ok := valueTransforms[name]; ok {
+			v = fn(v, arg)
+		}
+	}
+	return v
+}
+
+// fields returns the stored field name of every kept column other than the key, in source order.
+func (m *Mapping) fields(columns []string, key string) (cols, names []string) {
+	dropped := make(map[string]bool, len(m.Drop))
+	for _, col := range m.Drop {
+		dropped[col] = true
+	}
+	for _, col := range columns {
+		if col == key || dropped[col] {
+			continue
+		}
+		name := col
+		if to, ok := m.Rename[col]; ok {
+			name = to
+		}
+		cols = append(cols, col)
+		names = append(names, name)
+	}
+	return cols, names
+}
+
+// record turns a row into its key and value map. Values are stored in ECDICT's escaped form,
+// with real line breaks written as "\n". The key is empty when the row has no headword.
+func (m *Mapping) record(row map[string]string, key string, cols, names []string) (string, map[string]string) {
+	word := strings.TrimSpace(m.transform(key, row[key]))
+	if !m.KeepKeyCase {
+		word = strings.ToLower(word)
+	}
+	fields := make(map[string]string, len(cols))
+	for i, col := range cols {
+		if v := m.transform(col, row[col]); v != "" {
+			fields[names[i]] = encodeEscapes(v)
+		}
+	}
+	return word, fields
+}
@@ -0,0 +1,88 @@
+package bbolthelper
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestMapping_Record(t *testing.T) {
+	m := Mapping{
+		Key:    "term",
+		Rename: map[string]string{"gloss": FieldTranslation, "type": FieldPOS},
+		Drop:   []string{"owner"},
+		Transforms: map[string][]string{
+			"term":  {"upper"},
+			"gloss": {"trim", "lines:;"},
+			"type":  {"prefix:pos:"},
+			"notes": {"strip-html"},
+		},
+	}
+	cols, names := m.fields([]string{"term", "gloss", "owner", "type", "notes"}, "term")
+	if !reflect.DeepEqual(cols, []string{"gloss", "type", "notes"}) || !reflect.DeepEqual(names, []string{FieldTranslation, FieldPOS, "notes"}) {
+		t.Fatalf("fields() = %q, %q", cols, names)
+	}
+
+	row := map[string]string{
+		"term":  " SLA ",
+		"gloss": " 服务等级协议; service level agreement; ",
+		"owner": "ops",
+		"type":  "",
+		"notes": "<b>See</b> also <i>SLO</i>",
+	}
+	word, fields := m.record(row, "term", cols, names)
+	if word != "sla" {
+		t.Errorf("record() key = %q, want %q", word, "sla")
+	}
+	want := map[string]string{
+		FieldTranslation: `服务等级协议\nservice level agreement`,
+		"notes":          "See also SLO",
+	}
+	if !reflect.DeepEqual(fields, want) {
+		t.Errorf("record() fields = %q, want %q", fields, want)
+	}
+
+	m.KeepKeyCase = true
+	if word, _ := m.record(row, "term", cols, names); word != "SLA" {
+		t.Errorf("record() with KeepKeyCase key = %q, want %q", word, "SLA")
+	}
+}
+
+func TestLoadMapping(t *testing.T) {
+	m, err := LoadMapping("ecdict")
+	if err != nil || m.Table != "stardict" || m.Key != "word" {
+		t.Errorf("LoadMapping(ecdict) = %+v, %v", m, err)
+	}
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "glossary.json")
+	content := `{"key": "term", "rename": {"gloss": "translation"}, "drop": ["owner"], "transforms": {"gloss": ["lines:;"]}}`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	m, err = LoadMapping(path)
+	if err != nil {
+		t.Fatalf("LoadMapping() failed: %v", err)
+	}
+	want := Mapping{
+		Key:        "term",
+		Rename:     map[string]string{"gloss": FieldTranslation},
+		Drop:       []string{"owner"},
+		Transforms: map[string][]string{"gloss": {"lines:;"}},
+	}
+	if !reflect.DeepEqual(m, want) {
+		t.Errorf("LoadMapping() = %+v, want %+v", m, want)
+	}
+
+	bad := filepath.Join(dir, "bad.json")
+	if err := os.WriteFile(bad, []byte(`{"transforms": {"gloss": ["shout"]}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadMapping(bad); err == nil {
+		t.Error("LoadMapping() accepted an unknown transform")
+	}
+	if _, err := LoadMapping(filepath.Join(dir, "missing.json")); err == nil {
+		t.Error("LoadMapping() accepted a missing file")
+	}
+}
@@ -0,0 +1,110 @@
+package bbolthelper
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"regexp/syntax"
+	"strings"
+	"unicode/utf8"
+
+	bolt "go.etcd.io/bbolt"
+	"go.uber.org/zap"
+)
+
+// MatchOptions controls Match.
+type MatchOptions struct {
+	// Regex interprets the pattern as a Go regular expression instead of a wildcard pattern.
+	Regex bool
+	// Length keeps only words of exactly this many characters when positive.
+	Length int
+	// Limit is the maximum number of words returned; <= 0 returns all matches.
+	Limit int
+	Order PrefixOrder
+}
+
+// compileMatchPattern compiles a pattern and returns the literal prefix every match starts with.
+// Wildcard patterns are case-insensitive and match whole words: '?' stands for one character and
+// '*' for any number of characters. Regular expressions match anywhere in the word unless anchored.
+func compileMatchPattern(pattern string, isRegex bool) (*regexp.Regexp, string, error) {
+	if !isRegex {
+		pattern = strings.ToLower(pattern)
+		var expr strings.Builder
+		expr.WriteString("^")
+		literal := true
+		prefix := ""
+		for _, r := range pattern {
+			switch r {
+			case '?':
+				expr.WriteString(".")
+				literal = false
+			case '*':
+				expr.WriteString(".*")
+				literal = false
+			default:
+				expr.WriteString(regexp.QuoteMeta(string(r)))
+				if literal {
+					prefix += string(r)
+				}
+			}
+		}
+		expr.WriteString("$")
+		re, err := regexp.Compile(expr.String())
+		return re, prefix, err
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid regular expression '%s': %w", pattern, err)
+	}
+	// A literal only bounds the start of the word when it directly follows a leading ^.
+	parsed, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return nil, "", err
+	}
+	parsed = parsed.Simplify()
+	if parsed.Op == syntax.OpConcat && len(parsed.Sub) > 1 && parsed.Sub[0].Op == syntax.OpBeginText {
+		if lit := parsed.Sub[1]; lit.Op == syntax.OpLiteral && lit.Flags&syntax.FoldCase == 0 {
+			return re, string(lit.Rune), nil
+		}
+	}
+	return re, "", nil
+}
+
+// Match returns the keys matching a wildcard pattern ("c?t", "pre*tion") or, with opts.Regex, a
+// regular expression ("^un.+able$"). When the pattern starts with a literal prefix only the
+// cursor range of that prefix is read; otherwise every key is scanned.
+func (s *DBStore) Match(pattern string, opts MatchOptions) ([]Completion, error) {
+	re, prefix, err := compileMatchPattern(pattern, opts.Regex)
+	if err != nil {
+		return nil, err
+	}
+	p := []byte(prefix)
+	s.logger.Debug("Matching pattern", zap.String("regexp", re.String()), zap.String("prefix", prefix))
+
+	var completions []Completion
+	err = s.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(s.bucketName))
+		if b == nil {
+			return fmt.Errorf("bucket '%s' not found during Match operation", s.bucketName)
+		}
+
+		c := b.Cursor()
+		for k, v := c.Seek(p); k != nil && bytes.HasPrefix(k, p); k, v = c.Next() {
+			if opts.Order == PrefixOrderLexical && opts.Limit > 0 && len(completions) >= opts.Limit {
+				break
+			}
+			if opts.Length > 0 && utf8.RuneCount(k) != opts.Length {
+				continue
+			}
+			if re.Match(k) {
+				completions = append(completions, s.completion(k, v))
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return orderCompletions(completions, opts.Limit, opts.Order), nil
+}
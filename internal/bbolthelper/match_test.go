@@ -0,0 +1,87 @@
+package bbolthelper
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCompileMatchPattern(t *testing.T) {
+	tests := []struct {
+		pattern    string
+		regex      bool
+		wantPrefix string
+	}{
+		{"c?t", false, "c"},
+		{"Pre*tion", false, "pre"},
+		{"*ing", false, ""},
+		{"a.b", false, "a.b"},
+		{"^un.+able$", true, "un"},
+		{"un.+able", true, ""},
+		{"^a|b", true, ""},
+		{"(?i)^un", true, ""},
+	}
+	for _, tt := range tests {
+		_, prefix, err := compileMatchPattern(tt.pattern, tt.regex)
+		if err != nil {
+			t.Fatalf("compileMatchPattern(%q) failed: %v", tt.pattern, err)
+		}
+		if prefix != tt.wantPrefix {
+			t.Errorf("compileMatchPattern(%q) prefix = %q, want %q", tt.pattern, prefix, tt.wantPrefix)
+		}
+	}
+	if _, _, err := compileMatchPattern("(", true); err == nil {
+		t.Error("compileMatchPattern(\"(\") succeeded, want error")
+	}
+}
+
+func TestDBStore_Match(t *testing.T) {
+	store := newTestStore(t, Config{BucketName: "TestBucket"})
+	rows := []string{
+		`cat,,,,,,,,,900,,,`,
+		`cot,,,,,,,,,3000,,,`,
+		`cut,,,,,,,,,500,,,`,
+		`coat,,,,,,,,,1200,,,`,
+		`a.b,,,,,,,,,,,,`,
+		`axb,,,,,,,,,,,,`,
+		`prevention,,,,,,,,,4000,,,`,
+		`presentation,,,,,,,,,2500,,,`,
+		`unbelievable,,,,,,,,,6000,,,`,
+		`unable,,,,,,,,,1500,,,`,
+		`understandable,,,,,,,,,3500,,,`,
+	}
+	if _, err := store.ImportFromCSV(writeTestCSV(t, t.TempDir(), rows), 0); err != nil {
+		t.Fatalf("ImportFromCSV() failed: %v", err)
+	}
+
+	tests := []struct {
+		pattern string
+		opts    MatchOptions
+		want    []string
+	}{
+		{"c?t", MatchOptions{}, []string{"cat", "cot", "cut"}},
+		{"C?T", MatchOptions{Order: PrefixOrderFrequency}, []string{"cut", "cat", "cot"}},
+		{"c*t", MatchOptions{}, []string{"cat", "coat", "cot", "cut"}},
+		{"c*t", MatchOptions{Length: 4}, []string{"coat"}},
+		{"c*t", MatchOptions{Limit: 2}, []string{"cat", "coat"}},
+		{"pre*tion", MatchOptions{}, []string{"presentation", "prevention"}},
+		{"*tion", MatchOptions{Order: PrefixOrderFrequency, Limit: 1}, []string{"presentation"}},
+		{"a.b", MatchOptions{}, []string{"a.b"}},
+		{"^un.+able$", MatchOptions{Regex: true}, []string{"unbelievable", "understandable"}},
+		{"able$", MatchOptions{Regex: true, Order: PrefixOrderFrequency}, []string{"unable", "understandable", "unbelievable"}},
+		{"^a.b$", MatchOptions{Regex: true}, []string{"a.b", "axb"}},
+		{"z*", MatchOptions{}, nil},
+	}
+	for _, tt := range tests {
+		got, err := store.Match(tt.pattern, tt.opts)
+		if err != nil {
+			t.Fatalf("Match(%q, %+v) failed: %v", tt.pattern, tt.opts, err)
+		}
+		var words []string
+		for _, c := range got {
+			words = append(words, c.Word)
+		}
+		if !reflect.DeepEqual(words, tt.want) {
+			t.Errorf("Match(%q, %+v) = %v, want %v", tt.pattern, tt.opts, words, tt.want)
+		}
+	}
+}
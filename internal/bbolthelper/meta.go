@@ -0,0 +1,137 @@
+package bbolthelper
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+	"go.uber.org/zap"
+)
+
+const (
+	// MetaBucketName is the bucket holding build metadata. Each dictionary bucket has one
+	// JSON-encoded Metadata value stored under its own bucket name.
+	MetaBucketName = "__meta__"
+	// SchemaVersion is the version of the database layout (buckets and key conventions)
+	// written by this package. It is independent of RecordFormatVersion.
+	SchemaVersion = 1
+)
+
+// Metadata describes how a dictionary bucket was built, and its Manifest.
+type Metadata struct {
+	Bucket string `json:"bucket"`
+	Manifest
+
+	// Format is the source format the dictionary was imported from, as named by `kvbuilder
+	// import --format`. It is empty for dictionaries built before formats were recorded, which
+	// were all ECDICT CSV imports.
+	Format         string    `json:"format,omitempty"`
+	SourceFile     string    `json:"source_file,omitempty"`
+	SourceSHA256   string    `json:"source_sha256,omitempty"`
+	RecordCount    int       `json:"record_count"`
+	HeaderColumns  []string  `json:"header_columns,omitempty"`
+	BuildTime      time.Time `json:"build_time"`
+	BuilderVersion string    `json:"builder_version,omitempty"`
+	SchemaVersion  int       `json:"schema_version"`
+	Codec          string    `json:"codec,omitempty"`
+	Indexes        []string  `json:"indexes,omitempty"`
+}
+
+// BucketInfo holds the page statistics of a single top-level bucket.
+type BucketInfo struct {
+	Name  string           `json:"name"`
+	Stats bolt.BucketStats `json:"stats"`
+}
+
+// DBInfo summarizes the physical state of the database file.
+type DBInfo struct {
+	Path     string       `json:"path"`
+	FileSize int64        `json:"file_size"`
+	PageSize int          `json:"page_size"`
+	Stats    bolt.Stats   `json:"stats"`
+	Buckets  []BucketInfo `json:"buckets"`
+}
+
+// putMetadata stores the metadata for its bucket within an existing write transaction.
+func putMetadata(tx *bolt.Tx, meta *Metadata) error {
+	b, err := tx.CreateBucketIfNotExists([]byte(MetaBucketName))
+	if err != nil {
+		return fmt.Errorf("failed to create bucket '%s': %w", MetaBucketName, err)
+	}
+	data, err := json.Marshal(meta)
+	if err != nil {
+		return fmt.Errorf("failed to encode metadata for bucket '%s': %w", meta.Bucket, err)
+	}
+	if err := b.Put([]byte(meta.Bucket), data); err != nil {
+		return fmt.Errorf("failed to store metadata for bucket '%s': %w", meta.Bucket, err)
+	}
+	return nil
+}
+
+// WriteMetadata stores build metadata for the store's bucket, replacing any previous value.
+// Bucket and SchemaVersion are filled in when left empty.
+func (s *DBStore) WriteMetadata(meta *Metadata) error {
+	if meta.Bucket == "" {
+		meta.Bucket = s.bucketName
+	}
+	if meta.SchemaVersion == 0 {
+		meta.SchemaVersion = SchemaVersion
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return putMetadata(tx, meta)
+	})
+}
+
+// Metadata returns the build metadata of the store's bucket.
+// The boolean is false when the database was built without metadata.
+func (s *DBStore) Metadata() (*Metadata, bool, error) {
+	var meta *Metadata
+	err := s.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(MetaBucketName))
+		if b == nil {
+			return nil
+		}
+		data := b.Get([]byte(s.bucketName))
+		if data == nil {
+			return nil
+		}
+		meta = &Metadata{}
+		if err := json.Unmarshal(data, meta); err != nil {
+			return fmt.Errorf("failed to decode metadata for bucket '%s': %w", s.bucketName, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	return meta, meta != nil, nil
+}
+
+// Info reports file size, database statistics and per-bucket page statistics.
+func (s *DBStore) Info() (*DBInfo, error) {
+	info := &DBInfo{
+		Path:     s.dbPath,
+		PageSize: s.db.Info().PageSize,
+		Stats:    s.db.Stats(),
+	}
+	if fi, err := os.Stat(s.dbPath); err == nil {
+		info.FileSize = fi.Size()
+	} else {
+		s.logger.Warn("Failed to stat database file", zap.String("dbPath", s.dbPath), zap.Error(err))
+	}
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.ForEach(func(name []byte, b *bolt.Bucket) error {
+			info.Buckets = append(info.Buckets, BucketInfo{Name: string(name), Stats: b.Stats()})
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect bucket statistics: %w", err)
+	}
+	sort.Slice(info.Buckets, func(i, j int) bool { return info.Buckets[i].Name < info.Buckets[j].Name })
+	return info, nil
+}
@@ -0,0 +1,69 @@
+package bbolthelper
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestImportFromCSV_WritesMetadata(t *testing.T) {
+	csvPath := writeTestCSV(t, t.TempDir(), testCSVRows)
+	store := newTestStore(t, Config{Codec: CodecBinary, BuilderVersion: "kvbuilder test"})
+
+	if _, found, err := store.Metadata(); err != nil || found {
+		t.Fatalf("Metadata() on a fresh store found = %v, err = %v; want not found", found, err)
+	}
+
+	n, err := store.ImportFromCSV(csvPath, 0)
+	if err != nil {
+		t.Fatalf("ImportFromCSV() error = %v", err)
+	}
+	if n != len(testCSVRows) {
+		t.Errorf("ImportFromCSV() imported %d records, want %d", n, len(testCSVRows))
+	}
+
+	meta, found, err := store.Metadata()
+	if err != nil || !found {
+		t.Fatalf("Metadata() found = %v, err = %v", found, err)
+	}
+	raw, _ := os.ReadFile(csvPath)
+	sum := sha256.Sum256(raw)
+	if meta.SourceSHA256 != hex.EncodeToString(sum[:]) {
+		t.Errorf("SourceSHA256 = %s, want %x", meta.SourceSHA256, sum)
+	}
+	if meta.RecordCount != len(testCSVRows) {
+		t.Errorf("RecordCount = %d, want %d", meta.RecordCount, len(testCSVRows))
+	}
+	if want := strings.Split(testCSVHeader, ","); !reflect.DeepEqual(meta.HeaderColumns, want) {
+		t.Errorf("HeaderColumns = %v, want %v", meta.HeaderColumns, want)
+	}
+	if meta.Bucket != DefaultBucketName || meta.Codec != "binary" || meta.BuilderVersion != "kvbuilder test" ||
+		meta.SchemaVersion != SchemaVersion || meta.SourceFile != "test.csv" || meta.BuildTime.IsZero() {
+		t.Errorf("Metadata() = %+v has unexpected descriptive fields", meta)
+	}
+}
+
+func TestDBStore_Info(t *testing.T) {
+	store := newTestStore(t, Config{})
+	if err := store.WriteMetadata(&Metadata{RecordCount: 0}); err != nil {
+		t.Fatalf("WriteMetadata() error = %v", err)
+	}
+
+	info, err := store.Info()
+	if err != nil {
+		t.Fatalf("Info() error = %v", err)
+	}
+	if info.FileSize == 0 || info.PageSize == 0 {
+		t.Errorf("Info() FileSize = %d, PageSize = %d; want non-zero", info.FileSize, info.PageSize)
+	}
+	var names []string
+	for _, b := range info.Buckets {
+		names = append(names, b.Name)
+	}
+	if want := []string{DefaultBucketName, MetaBucketName}; !reflect.DeepEqual(names, want) {
+		t.Errorf("Info() buckets = %v, want %v", names, want)
+	}
+}
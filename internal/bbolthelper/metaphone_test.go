@@ -0,0 +1,35 @@
+package bbolthelper
+
+import "testing"
+
+func TestDoubleMetaphone(t *testing.T) {
+	tests := []struct {
+		word, primary, alternate string
+	}{
+		{"phonetic", "FNTK", "FNTK"},
+		{"fonetik", "FNTK", "FNTK"},
+		{"knowledge", "NLJ", "NLJ"},
+		{"nolege", "NLJ", "NLK"},
+		{"Thompson", "TMPS", "TMPS"},
+		{"smith", "SM0", "XMT"},
+		{"schmidt", "XMT", "SMT"},
+		{"school", "SKL", "SKL"},
+		{"character", "KRKT", "KRKT"},
+		{"Michael", "MKL", "MXL"},
+		{"laugh", "LF", "LF"},
+		{"edge", "AJ", "AJ"},
+		{"accident", "AKST", "AKST"},
+		{"dumb", "TM", "TM"},
+		{"island", "ALNT", "ALNT"},
+		{"sugar", "XKR", "SKR"},
+		{"gnome", "NM", "NM"},
+		{"Xavier", "SF", "SFR"},
+		{"", "", ""},
+	}
+	for _, tt := range tests {
+		primary, alternate := doubleMetaphone(tt.word)
+		if primary != tt.primary || alternate != tt.alternate {
+			t.Errorf("doubleMetaphone(%q) = %q, %q; want %q, %q", tt.word, primary, alternate, tt.primary, tt.alternate)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package bbolthelper
+
+import (
+	"context"
+	"encoding/csv"
+	"io"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+// pipelineBufferPerWorker is the number of in-flight rows buffered per parse worker.
+const pipelineBufferPerWorker = 256
+
+// importRow is one CSV data row after parsing and serialization.
+type importRow struct {
+	seq int
+	// kv is nil when the row was skipped (read, parse or serialization error).
+	kv *keyValue
+	// offset is the CSV byte offset just past this row.
+	offset int64
+}
+
+// rawRow is a CSV data row as read from the file, before parsing.
+type rawRow struct {
+	seq     int
+	record  []string
+	readErr error
+	offset  int64
+}
+
+// parseRow converts a raw CSV row into its serialized record.
+// Errors are logged and yield a row without a record, so the row still advances the checkpoint.
+func (s *DBStore) parseRow(header []string, raw rawRow, csvFilePath string) importRow {
+	row := importRow{seq: raw.seq, offset: raw.offset}
+	if raw.readErr != nil {
+		s.logger.Warn("Error reading record from CSV, skipping record.", zap.String("csvPath", csvFilePath), zap.Error(raw.readErr))
+		return row
+	}
+	key, valueMap, ok := s.recordToValueMap(header, raw.record, csvFilePath)
+	if !ok {
+		return row
+	}
+	// Serialize the valueMap for the current record
+	serialized, err := SerializeWith(s.codec, valueMap)
+	if err != nil {
+		s.logger.Error("Failed to serialize record, skipping", zap.String("key", key), zap.Error(err))
+		return row
+	}
+	row.kv = &keyValue{key: key, value: serialized}
+	return row
+}
+
+// sequentialRows returns an iterator that reads, parses and serializes rows on the calling goroutine.
+func (s *DBStore) sequentialRows(reader *csv.Reader, header []string, baseOffset int64, csvFilePath string) func() (importRow, bool) {
+	seq := 0
+	return func() (importRow, bool) {
+		record, readErr := reader.Read()
+		if readErr == io.EOF {
+			return importRow{}, false // End of file
+		}
+		seq++
+		return s.parseRow(header, rawRow{seq: seq, record: record, readErr: readErr, offset: baseOffset + reader.InputOffset()}, csvFilePath), true
+	}
+}
+
+// pipelineRows returns an iterator backed by a three-stage pipeline: a single reader goroutine,
+// the given number of parse/serialize workers, and an ordering stage that yields rows in file order.
+// Because rows come out in the same order as sequentialRows, the writer produces identical contents.
+// Calling the returned stop function releases the pipeline goroutines; it must be called once the
+// iterator is no longer used.
+func (s *DBStore) pipelineRows(reader *csv.Reader, header []string, baseOffset int64, csvFilePath string, workers int) (func() (importRow, bool), func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	rawCh := make(chan rawRow, workers*pipelineBufferPerWorker)
+	parsedCh := make(chan importRow, workers*pipelineBufferPerWorker)
+	orderedCh := make(chan importRow, workers*pipelineBufferPerWorker)
+
+	// Stage 1: the csv.Reader is not safe for concurrent use, so a single goroutine reads.
+	go func() {
+		defer close(rawCh)
+		for seq := 1; ; seq++ {
+			record, readErr := reader.Read()
+			if readErr == io.EOF {
+				return
+			}
+			select {
+			case rawCh <- rawRow{seq: seq, record: record, readErr: readErr, offset: baseOffset + reader.InputOffset()}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	// Stage 2: parse and serialize in parallel; completion order is arbitrary.
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for raw := range rawCh {
+				select {
+				case parsedCh <- s.parseRow(header, raw, csvFilePath):
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(parsedCh)
+	}()
+
+	// Stage 3: restore file order before handing rows to the single writer.
+	go func() {
+		defer close(orderedCh)
+		pending := make(map[int]importRow)
+		next := 1
+		for row := range parsedCh {
+			pending[row.seq] = row
+			for r, ok := pending[next]; ok; r, ok = pending[next] {
+				delete(pending, next)
+				select {
+				case orderedCh <- r:
+				case <-ctx.Done():
+					return
+				}
+				next++
+			}
+		}
+	}()
+
+	iter := func() (importRow, bool) {
+		row, ok := <-orderedCh
+		return row, ok
+	}
+	stop := func() {
+		cancel()
+		// Drain so that blocked stages observe the cancellation and exit.
+		for range orderedCh {
+		}
+	}
+	return iter, stop
+}
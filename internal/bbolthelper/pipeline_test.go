@@ -0,0 +1,94 @@
+package bbolthelper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// generatedCSVRows returns n rows including duplicate keys (differing only in case) and malformed rows,
+// so that ordering mistakes in the pipeline change the final contents.
+func generatedCSVRows(n int) []string {
+	rows := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		switch {
+		case i%97 == 0:
+			rows = append(rows, "broken,too,few,fields") // field count error, skipped
+		case i%31 == 0:
+			rows = append(rows, fmt.Sprintf("Word%d,,,dup %d,,,,,,%d,,,", i/31, i, i))
+		default:
+			rows = append(rows, fmt.Sprintf("word%d,,def %d,trans %d,n:100,1,,cet4,%d,%d,,,", i, i, i, i, i))
+		}
+	}
+	return rows
+}
+
+// dumpBucket returns all records of the store's bucket, deserialized.
+// Values are compared after decoding because gob output depends on map iteration order.
+func dumpBucket(t *testing.T, store *DBStore) map[string]map[string]string {
+	t.Helper()
+	out := make(map[string]map[string]string)
+	err := store.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket([]byte(store.bucketName)).ForEach(func(k, v []byte) error {
+			m, err := Deserialize(v)
+			out[string(k)] = m
+			return err
+		})
+	})
+	if err != nil {
+		t.Fatalf("Failed to dump bucket: %v", err)
+	}
+	return out
+}
+
+func TestImportCSV_PipelineMatchesSequential(t *testing.T) {
+	csvPath := writeTestCSV(t, t.TempDir(), generatedCSVRows(5000))
+
+	seq := newTestStore(t, Config{Codec: CodecBinary})
+	nSeq, err := seq.ImportCSV(context.Background(), csvPath, ImportOptions{BatchSize: 333, Workers: 1})
+	if err != nil {
+		t.Fatalf("sequential ImportCSV() error = %v", err)
+	}
+
+	for _, workers := range []int{2, 8} {
+		t.Run(fmt.Sprintf("workers=%d", workers), func(t *testing.T) {
+			par := newTestStore(t, Config{Codec: CodecBinary})
+			nPar, err := par.ImportCSV(context.Background(), csvPath, ImportOptions{BatchSize: 333, Workers: workers})
+			if err != nil {
+				t.Fatalf("pipelined ImportCSV() error = %v", err)
+			}
+			if nPar != nSeq {
+				t.Errorf("pipelined ImportCSV() = %d records, sequential = %d", nPar, nSeq)
+			}
+			if got, want := dumpBucket(t, par), dumpBucket(t, seq); !reflect.DeepEqual(got, want) {
+				t.Errorf("pipelined import produced %d keys that differ from the sequential import (%d keys)", len(got), len(want))
+			}
+		})
+	}
+}
+
+func TestImportCSV_PipelineInterruptAndResume(t *testing.T) {
+	csvPath := writeTestCSV(t, t.TempDir(), generatedCSVRows(2000))
+
+	want := newTestStore(t, Config{})
+	if _, err := want.ImportCSV(context.Background(), csvPath, ImportOptions{Workers: 1}); err != nil {
+		t.Fatalf("ImportCSV() error = %v", err)
+	}
+
+	store := newTestStore(t, Config{})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := store.ImportCSV(ctx, csvPath, ImportOptions{BatchSize: 100, Workers: 4}); !errors.Is(err, context.Canceled) {
+		t.Fatalf("ImportCSV() error = %v, want context.Canceled", err)
+	}
+	if _, err := store.ImportCSV(context.Background(), csvPath, ImportOptions{BatchSize: 100, Workers: 4, Resume: true}); err != nil {
+		t.Fatalf("resumed ImportCSV() error = %v", err)
+	}
+	if got, exp := dumpBucket(t, store), dumpBucket(t, want); !reflect.DeepEqual(got, exp) {
+		t.Errorf("resumed pipelined import has %d keys, want %d identical keys", len(got), len(exp))
+	}
+}
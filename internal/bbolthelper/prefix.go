@@ -0,0 +1,117 @@
+package bbolthelper
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	bolt "go.etcd.io/bbolt"
+	"go.uber.org/zap"
+)
+
+// PrefixOrder selects how Prefix orders its results.
+type PrefixOrder int
+
+const (
+	// PrefixOrderLexical returns keys in byte order, as stored. It stops reading after limit keys.
+	PrefixOrderLexical PrefixOrder = iota
+	// PrefixOrderFrequency ranks keys by frq, most frequent first; words without a frq come last.
+	// Every key with the prefix has to be read before the best ones are known.
+	PrefixOrderFrequency
+)
+
+// String returns the name accepted by ParsePrefixOrder.
+func (o PrefixOrder) String() string {
+	switch o {
+	case PrefixOrderLexical:
+		return "lex"
+	case PrefixOrderFrequency:
+		return "frq"
+	default:
+		return fmt.Sprintf("PrefixOrder(%d)", int(o))
+	}
+}
+
+// ParsePrefixOrder parses an order name ("lex" or "frq").
+func ParsePrefixOrder(name string) (PrefixOrder, error) {
+	switch strings.ToLower(name) {
+	case "lex", "lexical", "alpha":
+		return PrefixOrderLexical, nil
+	case "frq", "freq", "frequency":
+		return PrefixOrderFrequency, nil
+	default:
+		return 0, fmt.Errorf("unknown order '%s' (expected 'lex' or 'frq')", name)
+	}
+}
+
+// Completion is a dictionary key returned by Prefix, with its frequency rank (0 when unknown).
+type Completion struct {
+	Word string `json:"word"`
+	Frq  int    `json:"frq,omitempty"`
+}
+
+// Prefix returns up to limit keys starting with prefix (case-insensitive), using a cursor seek
+// into the sorted bucket. A limit <= 0 returns all matching keys. An empty prefix matches every key.
+func (s *DBStore) Prefix(prefix string, limit int, order PrefixOrder) ([]Completion, error) {
+	p := []byte(strings.ToLower(prefix))
+	var completions []Completion
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(s.bucketName))
+		if b == nil {
+			return fmt.Errorf("bucket '%s' not found during Prefix operation", s.bucketName)
+		}
+
+		c := b.Cursor()
+		for k, v := c.Seek(p); k != nil && bytes.HasPrefix(k, p); k, v = c.Next() {
+			if order == PrefixOrderLexical && limit > 0 && len(completions) >= limit {
+				break
+			}
+			completions = append(completions, s.completion(k, v))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return orderCompletions(completions, limit, order), nil
+}
+
+// completion returns the Completion of a bucket entry.
+func (s *DBStore) completion(k, v []byte) Completion {
+	completion := Completion{Word: string(k)}
+	// Decode only the frequency field; binary records skip building the full map.
+	freqStr, _, err := DeserializeField(v, FieldFrq)
+	if err != nil {
+		s.logger.Warn("Failed to deserialize value for completion, skipping frequency.", zap.String("word", completion.Word), zap.Error(err))
+	} else {
+		completion.Frq = ParseEntry(completion.Word, map[string]string{FieldFrq: freqStr}).Frq
+	}
+	return completion
+}
+
+// MergeCompletions merges the completions of several dictionaries, given in priority order, as
+// Prefix orders those of one dictionary. A word found in several dictionaries is listed once.
+func MergeCompletions(lists [][]Completion, limit int, order PrefixOrder) []Completion {
+	completions := mergeResults(lists, func(c Completion) string { return c.Word })
+	sort.SliceStable(completions, func(i, j int) bool { return completions[i].Word < completions[j].Word })
+	return orderCompletions(completions, limit, order)
+}
+
+// orderCompletions sorts lexically collected completions by the given order and applies limit.
+func orderCompletions(completions []Completion, limit int, order PrefixOrder) []Completion {
+	if order == PrefixOrderFrequency {
+		sort.SliceStable(completions, func(i, j int) bool {
+			fi, fj := completions[i].Frq, completions[j].Frq
+			if (fi == 0) != (fj == 0) {
+				return fj == 0 // Ranked words before unranked ones
+			}
+			return fi < fj // Lower frq value first (higher frequency); ties stay lexical
+		})
+	}
+	if limit > 0 && len(completions) > limit {
+		completions = completions[:limit]
+	}
+	return completions
+}
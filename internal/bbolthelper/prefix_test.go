@@ -0,0 +1,70 @@
+package bbolthelper
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDBStore_Prefix(t *testing.T) {
+	store := newTestStore(t, Config{BucketName: "TestBucket"})
+	rows := append([]string{
+		`applet,,,,,,,,,,,,`,
+		`app,,,,,,,,,300,,,`,
+		`b,,,,,,,,,1,,,`,
+	}, testCSVRows...)
+	if _, err := store.ImportFromCSV(writeTestCSV(t, t.TempDir(), rows), 0); err != nil {
+		t.Fatalf("ImportFromCSV() failed: %v", err)
+	}
+
+	words := func(cs []Completion) []string {
+		var out []string
+		for _, c := range cs {
+			out = append(out, c.Word)
+		}
+		return out
+	}
+
+	tests := []struct {
+		name   string
+		prefix string
+		limit  int
+		order  PrefixOrder
+		want   []string
+	}{
+		{"lexical", "app", 0, PrefixOrderLexical, []string{"app", "apple", "applet", "apply"}},
+		{"lexical limit", "app", 2, PrefixOrderLexical, []string{"app", "apple"}},
+		{"frequency", "app", 0, PrefixOrderFrequency, []string{"app", "apply", "apple", "applet"}},
+		{"frequency limit", "APP", 2, PrefixOrderFrequency, []string{"app", "apply"}},
+		{"exact key only", "went", 0, PrefixOrderLexical, []string{"went"}},
+		{"no match", "zzz", 0, PrefixOrderLexical, nil},
+		{"past last key", "went2", 0, PrefixOrderLexical, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := store.Prefix(tt.prefix, tt.limit, tt.order)
+			if err != nil {
+				t.Fatalf("Prefix() failed: %v", err)
+			}
+			if !reflect.DeepEqual(words(got), tt.want) {
+				t.Errorf("Prefix(%q, %d, %v) = %v, want %v", tt.prefix, tt.limit, tt.order, words(got), tt.want)
+			}
+		})
+	}
+
+	got, _ := store.Prefix("apply", 0, PrefixOrderLexical)
+	if len(got) != 1 || got[0].Frq != 800 {
+		t.Errorf("Prefix(apply) = %+v, want frq 800", got)
+	}
+}
+
+func TestParsePrefixOrder(t *testing.T) {
+	for name, want := range map[string]PrefixOrder{"lex": PrefixOrderLexical, "FRQ": PrefixOrderFrequency} {
+		got, err := ParsePrefixOrder(name)
+		if err != nil || got != want {
+			t.Errorf("ParsePrefixOrder(%q) = %v, %v; want %v", name, got, err, want)
+		}
+	}
+	if _, err := ParsePrefixOrder("random"); err == nil {
+		t.Error("ParsePrefixOrder(random) succeeded, want error")
+	}
+}
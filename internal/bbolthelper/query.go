@@ -0,0 +1,483 @@
+package bbolthelper
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	bolt "go.etcd.io/bbolt"
+	"go.uber.org/zap"
+)
+
+// IndexAttributes is the name of the index mapping record attributes (tags, parts of speech,
+// Collins stars and the Oxford 3000 flag) to words, used by the Query planner.
+const IndexAttributes = "attributes"
+
+// Query fields. Text fields take a value, numeric fields a number, a range or a comparison.
+const (
+	QueryFieldWord    = "word"    // Wildcard pattern over the headword, e.g. word:pre*
+	QueryFieldTag     = "tag"     // ECDICT tag, e.g. tag:gre
+	QueryFieldPOS     = "pos"     // Part of speech, e.g. pos:v, pos:adj
+	QueryFieldLen     = "len"     // Headword length in characters
+	QueryFieldCollins = "collins" // Collins stars, 0 to 5
+	QueryFieldOxford  = "oxford"  // Oxford 3000 membership, as a boolean
+	QueryFieldBNC     = "bnc"     // British National Corpus rank
+	QueryFieldFrq     = "frq"     // Contemporary corpus frequency rank
+)
+
+// queryNumericFields lists the fields compared as numbers.
+var queryNumericFields = []string{QueryFieldLen, QueryFieldCollins, QueryFieldOxford, QueryFieldBNC, QueryFieldFrq}
+
+// queryTerm splits a query term into negation, field, operator and value.
+var queryTerm = regexp.MustCompile(`^(-?)([a-z]+)(>=|<=|:|=|>|<)(.+)$`)
+
+// QueryFilter is one condition of a Query.
+type QueryFilter struct {
+	Field string `json:"field"`
+	// Negate keeps the records that do not satisfy the condition.
+	Negate bool `json:"negate,omitempty"`
+	// Value is the tag, part of speech or pattern of a text field.
+	Value string `json:"value,omitempty"`
+	// Min and Max are the inclusive bounds of a numeric field.
+	Min int `json:"min,omitempty"`
+	Max int `json:"max,omitempty"`
+
+	re     *regexp.Regexp
+	prefix string
+}
+
+// Query is a parsed structured query, see ParseQuery.
+type Query struct {
+	Filters []QueryFilter `json:"filters"`
+	// Sort is the field results are ordered by; Desc reverses it.
+	Sort  string `json:"sort"`
+	Desc  bool   `json:"desc,omitempty"`
+	Limit int    `json:"limit,omitempty"`
+}
+
+// ParseQuery parses a query of space-separated terms, all of which must hold, e.g.
+//
+//	tag:gre collins>=3 len:6..8 pos:v sort:frq limit:100
+//
+// Text fields (word, tag, pos) take field:value; word takes a wildcard pattern with '?' and '*'.
+// Numeric fields (len, collins, oxford, bnc, frq) take field:n, a range field:lo..hi with either
+// bound optional, or a comparison with >=, <=, > or <; oxford also accepts true and false.
+// A leading '-' negates a term. sort:field orders the results (sort:-field for descending, the
+// default is sort:word) and limit:n caps their number.
+func ParseQuery(input string) (*Query, error) {
+	q := &Query{Sort: QueryFieldWord}
+	for _, term := range strings.Fields(input) {
+		m := queryTerm.FindStringSubmatch(strings.ToLower(term))
+		if m == nil {
+			return nil, fmt.Errorf("invalid query term '%s': expected field:value", term)
+		}
+		negate, field, op, value := m[1] == "-", m[2], m[3], m[4]
+
+		switch field {
+		case "sort":
+			if negate || op != ":" {
+				return nil, fmt.Errorf("invalid query term '%s': expected sort:field", term)
+			}
+			q.Desc = strings.HasPrefix(value, "-")
+			q.Sort = strings.TrimPrefix(value, "-")
+			if q.Sort != QueryFieldWord && !slices.Contains(queryNumericFields, q.Sort) {
+				return nil, fmt.Errorf("cannot sort by '%s': expected word or one of %s", q.Sort, strings.Join(queryNumericFields, ", "))
+			}
+			continue
+		case "limit":
+			n, err := strconv.Atoi(value)
+			if negate || op != ":" || err != nil || n < 0 {
+				return nil, fmt.Errorf("invalid query term '%s': expected limit:n", term)
+			}
+			q.Limit = n
+			continue
+		}
+
+		f := QueryFilter{Field: field, Negate: negate}
+		switch {
+		case field == QueryFieldWord || field == QueryFieldTag || field == QueryFieldPOS:
+			if op != ":" && op != "=" {
+				return nil, fmt.Errorf("invalid query term '%s': %s cannot be compared with %s", term, field, op)
+			}
+			f.Value = value
+			if field == QueryFieldPOS {
+				f.Value = canonicalPOS(value)
+			}
+			if field == QueryFieldWord {
+				re, prefix, err := compileMatchPattern(value, false)
+				if err != nil {
+					return nil, fmt.Errorf("invalid query term '%s': %w", term, err)
+				}
+				f.re, f.prefix = re, prefix
+			}
+		case slices.Contains(queryNumericFields, field):
+			var err error
+			if f.Min, f.Max, err = parseQueryBounds(field, op, value); err != nil {
+				return nil, fmt.Errorf("invalid query term '%s': %w", term, err)
+			}
+		default:
+			return nil, fmt.Errorf("unknown query field '%s': expected word, tag, pos, %s, sort or limit", field, strings.Join(queryNumericFields, ", "))
+		}
+		q.Filters = append(q.Filters, f)
+	}
+	return q, nil
+}
+
+// parseQueryBounds converts a numeric operator and value into inclusive bounds.
+func parseQueryBounds(field, op, value string) (int, int, error) {
+	parse := func(s string, open int) (int, error) {
+		if s == "" {
+			return open, nil
+		}
+		if field == QueryFieldOxford {
+			switch s {
+			case "true", "yes":
+				return 1, nil
+			case "false", "no":
+				return 0, nil
+			}
+		}
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return 0, fmt.Errorf("%s expects a number, got '%s'", field, s)
+		}
+		return n, nil
+	}
+
+	if op == ":" || op == "=" {
+		lo, hi, isRange := strings.Cut(value, "..")
+		if !isRange {
+			n, err := parse(value, 0)
+			return n, n, err
+		}
+		min, err := parse(lo, math.MinInt)
+		if err != nil {
+			return 0, 0, err
+		}
+		max, err := parse(hi, math.MaxInt)
+		return min, max, err
+	}
+	n, err := parse(value, 0)
+	if err != nil {
+		return 0, 0, err
+	}
+	switch op {
+	case ">=":
+		return n, math.MaxInt, nil
+	case ">":
+		if n == math.MaxInt {
+			return 0, 0, fmt.Errorf("%s>%d matches nothing", field, n)
+		}
+		return n + 1, math.MaxInt, nil
+	case "<=":
+		return math.MinInt, n, nil
+	default: // "<"
+		if n == math.MinInt {
+			return 0, 0, fmt.Errorf("%s<%d matches nothing", field, n)
+		}
+		return math.MinInt, n - 1, nil
+	}
+}
+
+// canonicalPOS maps the part-of-speech labels of the ECDICT pos column ("j", "r") and of its
+// translations ("vt.", "adj.") to one name per part of speech.
+func canonicalPOS(label string) string {
+	switch label = strings.TrimSuffix(strings.ToLower(label), "."); label {
+	case "vt", "vi":
+		return "v"
+	case "a", "j":
+		return "adj"
+	case "r", "ad":
+		return "adv"
+	case "interj":
+		return "int"
+	}
+	return label
+}
+
+// entryPOS returns the canonical parts of speech of an entry, from its pos column and the labels
+// of its translation lines.
+func entryPOS(e *Entry) []string {
+	var pos []string
+	for _, p := range e.POS {
+		pos = append(pos, canonicalPOS(p.Tag))
+	}
+	for _, line := range strings.Split(e.Translation, "\n") {
+		if label := translationPOS.FindString(strings.TrimSpace(line)); label != "" {
+			pos = append(pos, canonicalPOS(strings.TrimSpace(label)))
+		}
+	}
+	return dedupe(pos)
+}
+
+// numericValue returns the value of a numeric field of an entry.
+func numericValue(e *Entry, field string) int {
+	switch field {
+	case QueryFieldLen:
+		return utf8.RuneCountInString(e.Word)
+	case QueryFieldCollins:
+		return e.Collins
+	case QueryFieldOxford:
+		if e.Oxford {
+			return 1
+		}
+		return 0
+	case QueryFieldBNC:
+		return e.BNC
+	case QueryFieldFrq:
+		return e.Frq
+	}
+	return 0
+}
+
+// matches reports whether an entry satisfies the filter. Words without a bnc or frq rank never
+// satisfy a condition on it.
+func (f *QueryFilter) matches(e *Entry) bool {
+	var ok bool
+	switch f.Field {
+	case QueryFieldWord:
+		ok = f.re.MatchString(strings.ToLower(e.Word))
+	case QueryFieldTag:
+		ok = e.HasTag(f.Value)
+	case QueryFieldPOS:
+		ok = slices.Contains(entryPOS(e), f.Value)
+	default:
+		v := numericValue(e, f.Field)
+		if (f.Field == QueryFieldBNC || f.Field == QueryFieldFrq) && v == 0 {
+			return false
+		}
+		ok = v >= f.Min && v <= f.Max
+	}
+	return ok != f.Negate
+}
+
+// attributeKeys returns the attributes index keys of a filter whose candidates are the union of
+// those keys' lists, or false when the filter cannot be answered from the index.
+func (f *QueryFilter) attributeKeys() ([]string, bool) {
+	if f.Negate {
+		return nil, false
+	}
+	switch f.Field {
+	case QueryFieldTag, QueryFieldPOS:
+		return []string{f.Field + ":" + f.Value}, true
+	case QueryFieldCollins:
+		if f.Min < 1 {
+			return nil, false // Words without stars are not indexed
+		}
+		var keys []string
+		for n := f.Min; n <= min(f.Max, 5); n++ {
+			keys = append(keys, QueryFieldCollins+":"+strconv.Itoa(n))
+		}
+		return keys, true
+	case QueryFieldOxford:
+		if f.Min == 1 && f.Max >= 1 {
+			return []string{QueryFieldOxford + ":1"}, true
+		}
+	}
+	return nil, false
+}
+
+// attributeIndexBuilder collects the words of every tag, part of speech, Collins star count and
+// of the Oxford 3000.
+type attributeIndexBuilder struct {
+	words map[string][]string
+}
+
+func newAttributeIndexBuilder() indexBuilder {
+	return &attributeIndexBuilder{words: make(map[string][]string)}
+}
+
+func (ab *attributeIndexBuilder) add(word string, fields map[string]string) {
+	e := ParseEntry(word, fields)
+	for _, tag := range e.Tags {
+		ab.words[QueryFieldTag+":"+tag] = append(ab.words[QueryFieldTag+":"+tag], word)
+	}
+	for _, pos := range entryPOS(e) {
+		ab.words[QueryFieldPOS+":"+pos] = append(ab.words[QueryFieldPOS+":"+pos], word)
+	}
+	if e.Collins > 0 {
+		key := QueryFieldCollins + ":" + strconv.Itoa(e.Collins)
+		ab.words[key] = append(ab.words[key], word)
+	}
+	if e.Oxford {
+		ab.words[QueryFieldOxford+":1"] = append(ab.words[QueryFieldOxford+":1"], word)
+	}
+}
+
+func (ab *attributeIndexBuilder) emit(put func(key, value []byte) error) error {
+	return emitSortedLists(ab.words, "\n", put)
+}
+
+// QueryResult holds the entries matching a Query and a description of how they were found.
+type QueryResult struct {
+	Entries []*Entry `json:"entries"`
+	// Plan describes the access path chosen by the planner, e.g. "attributes index (tag:gre)".
+	Plan string `json:"plan"`
+}
+
+// Query runs a parsed query. The planner reads candidates from the attributes index when the
+// query has a condition on tag, pos, collins (at least one star) or oxford (true), intersecting
+// the lists of all such conditions; otherwise it reads the key range of a word pattern's literal
+// prefix, or scans every record. Each candidate is then checked against all conditions.
+func (s *DBStore) Query(q *Query) (*QueryResult, error) {
+	result := &QueryResult{}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(s.bucketName))
+		if b == nil {
+			return fmt.Errorf("bucket '%s' not found during Query operation", s.bucketName)
+		}
+
+		consider := func(k, v []byte) {
+			fields, err := Deserialize(v)
+			if err != nil {
+				s.logger.Warn("Failed to deserialize value for query, skipping.", zap.ByteString("word", k), zap.Error(err))
+				return
+			}
+			e := ParseEntry(string(k), fields)
+			for i := range q.Filters {
+				if !q.Filters[i].matches(e) {
+					return
+				}
+			}
+			result.Entries = append(result.Entries, e)
+		}
+
+		if ib := tx.Bucket(s.indexBucketName(IndexAttributes)); ib != nil {
+			if words, used, ok := queryCandidates(ib, q.Filters); ok {
+				result.Plan = fmt.Sprintf("attributes index (%s): %d candidates", strings.Join(used, " ∩ "), len(words))
+				for _, w := range words {
+					if v := b.Get([]byte(w)); v != nil {
+						consider([]byte(w), v)
+					}
+				}
+				return nil
+			}
+		}
+
+		prefix := ""
+		for _, f := range q.Filters {
+			if f.Field == QueryFieldWord && !f.Negate && len(f.prefix) > len(prefix) {
+				prefix = f.prefix
+			}
+		}
+		c := b.Cursor()
+		if prefix != "" {
+			result.Plan = fmt.Sprintf("key range scan (prefix %q)", prefix)
+			p := []byte(prefix)
+			for k, v := c.Seek(p); k != nil && bytes.HasPrefix(k, p); k, v = c.Next() {
+				consider(k, v)
+			}
+			return nil
+		}
+		result.Plan = "full scan"
+		for k, v := c.First(); k != nil; k, v = c.Next() {
+			consider(k, v)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.logger.Debug("Query executed", zap.String("plan", result.Plan), zap.Int("results", len(result.Entries)))
+
+	result.Entries = orderEntries(result.Entries, q)
+	return result, nil
+}
+
+// MergeQueryResults merges the results of a query in several dictionaries, given in priority
+// order, as Query orders those of one dictionary, and joins their plans. A word found in several
+// dictionaries is listed once, with its entry from the first.
+func MergeQueryResults(q *Query, results []*QueryResult) *QueryResult {
+	lists := make([][]*Entry, len(results))
+	plans := make([]string, len(results))
+	for i, r := range results {
+		lists[i], plans[i] = r.Entries, r.Plan
+	}
+	entries := mergeResults(lists, func(e *Entry) string { return e.Word })
+	return &QueryResult{Entries: orderEntries(entries, q), Plan: strings.Join(plans, "; ")}
+}
+
+// orderEntries sorts entries as the query asks and applies its limit.
+func orderEntries(entries []*Entry, q *Query) []*Entry {
+	sortEntries(entries, q.Sort, q.Desc)
+	if q.Limit > 0 && len(entries) > q.Limit {
+		entries = entries[:q.Limit]
+	}
+	return entries
+}
+
+// queryCandidates intersects the attributes index lists of the filters that the index can answer,
+// smallest first. It returns the candidate words in key order and the conditions used, or false
+// when no filter can be answered from the index.
+func queryCandidates(ib *bolt.Bucket, filters []QueryFilter) ([]string, []string, bool) {
+	type candidateSet struct {
+		label string
+		words map[string]bool
+	}
+	var sets []candidateSet
+	for _, f := range filters {
+		keys, ok := f.attributeKeys()
+		if !ok {
+			continue
+		}
+		set := candidateSet{label: strings.Join(keys, "|"), words: make(map[string]bool)}
+		for _, key := range keys {
+			if v := ib.Get([]byte(key)); v != nil {
+				for _, w := range strings.Split(string(v), "\n") {
+					set.words[w] = true
+				}
+			}
+		}
+		sets = append(sets, set)
+	}
+	if len(sets) == 0 {
+		return nil, nil, false
+	}
+
+	sort.SliceStable(sets, func(i, j int) bool { return len(sets[i].words) < len(sets[j].words) })
+	var words, used []string
+	for _, set := range sets {
+		used = append(used, set.label)
+	}
+	for w := range sets[0].words {
+		inAll := true
+		for _, set := range sets[1:] {
+			if !set.words[w] {
+				inAll = false
+				break
+			}
+		}
+		if inAll {
+			words = append(words, w)
+		}
+	}
+	sort.Strings(words)
+	return words, used, true
+}
+
+// sortEntries orders entries by a query sort field, breaking ties by word. Words without a bnc
+// or frq rank come last in either direction when sorting by it.
+func sortEntries(entries []*Entry, field string, desc bool) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if field != QueryFieldWord {
+			va, vb := numericValue(a, field), numericValue(b, field)
+			if field == QueryFieldBNC || field == QueryFieldFrq {
+				if (va == 0) != (vb == 0) {
+					return vb == 0 // Ranked words before unranked ones
+				}
+			}
+			if va != vb {
+				return (va < vb) != desc
+			}
+			return a.Word < b.Word
+		}
+		return (a.Word < b.Word) != desc
+	})
+}
@@ -0,0 +1,141 @@
+package bbolthelper
+
+import (
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// queryTestRows have the attributes the query language filters on.
+var queryTestRows = []string{
+	`abate,,,vt. 减少,v:100,3,,gre toefl,15000,12000,,,`,
+	`abhor,,,vt. 憎恶,,2,,gre,20000,18000,,,`,
+	`debase,,,vt. 贬低,,3,,gre,,25000,,,`,
+	`candid,,,a. 坦率的,j:100,4,1,gre cet6,9000,8000,,,`,
+	`abandon,,,vt. 放弃\nn. 放任,v:90/n:10,5,1,cet4 gre,2000,1500,,,`,
+	`go,,,v. 去,v:92/n:8,5,1,zk gk cet4,38,42,,,`,
+	`zeal,,,n. 热情,,3,,gre,,,,,`,
+}
+
+func TestParseQuery(t *testing.T) {
+	q, err := ParseQuery("tag:gre collins>=3 len:6..8 pos:vt -oxford:true word:a* sort:-frq limit:100")
+	if err != nil {
+		t.Fatalf("ParseQuery() failed: %v", err)
+	}
+	got := make([]string, len(q.Filters))
+	for i, f := range q.Filters {
+		got[i] = f.Field
+	}
+	if want := []string{"tag", "collins", "len", "pos", "oxford", "word"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseQuery() fields = %v, want %v", got, want)
+	}
+	if f := q.Filters[1]; f.Min != 3 || f.Max != math.MaxInt {
+		t.Errorf("collins>=3 bounds = %d..%d, want 3..MaxInt", f.Min, f.Max)
+	}
+	if f := q.Filters[2]; f.Min != 6 || f.Max != 8 {
+		t.Errorf("len:6..8 bounds = %d..%d, want 6..8", f.Min, f.Max)
+	}
+	if f := q.Filters[3]; f.Value != "v" {
+		t.Errorf("pos:vt value = %q, want canonical %q", f.Value, "v")
+	}
+	if f := q.Filters[4]; !f.Negate || f.Min != 1 || f.Max != 1 {
+		t.Errorf("-oxford:true = %+v, want negated 1..1", f)
+	}
+	if q.Sort != "frq" || !q.Desc || q.Limit != 100 {
+		t.Errorf("ParseQuery() sort = %q desc=%v limit=%d, want frq desc 100", q.Sort, q.Desc, q.Limit)
+	}
+
+	for _, bad := range []string{"gre", "color:red", "collins>=many", "tag>3", "sort:translation", "limit:-1", "-sort:frq",
+		"frq>9223372036854775807", "bnc<-9223372036854775808", "frq>9223372036854775808"} {
+		if _, err := ParseQuery(bad); err == nil {
+			t.Errorf("ParseQuery(%q) succeeded, want error", bad)
+		}
+	}
+}
+
+func TestDBStore_Query(t *testing.T) {
+	store := newTestStore(t, Config{BucketName: "TestBucket"})
+	if _, err := store.ImportFromCSV(writeTestCSV(t, t.TempDir(), queryTestRows), 0); err != nil {
+		t.Fatalf("ImportFromCSV() failed: %v", err)
+	}
+
+	tests := []struct {
+		query     string
+		want      []string
+		indexPlan string
+	}{
+		// pos matches the pos column and the translation labels ("vt." of abhor and debase).
+		{"tag:gre collins>=3 len:5..7 pos:v sort:frq", []string{"abandon", "abate", "debase"}, "attributes index"},
+		{"tag:gre collins>=3 len:5..7 pos:v sort:frq limit:2", []string{"abandon", "abate"}, "attributes index"},
+		{"pos:adj", []string{"candid"}, "attributes index"},
+		{"oxford:true -tag:gre", []string{"go"}, "attributes index"},
+		{"tag:gre sort:-collins", []string{"abandon", "candid", "abate", "debase", "zeal", "abhor"}, "attributes index"},
+		// Unranked words never satisfy frq conditions and sort last.
+		{"frq<=20000 sort:-frq", []string{"abhor", "abate", "candid", "abandon", "go"}, "full scan"},
+		{"word:ab* bnc>10000", []string{"abate", "abhor"}, "key range scan"},
+		{"collins:0..2", []string{"abhor"}, "full scan"},
+		{"tag:none", nil, "attributes index"},
+	}
+	run := func(label string, indexed bool) {
+		for _, tt := range tests {
+			q, err := ParseQuery(tt.query)
+			if err != nil {
+				t.Fatalf("ParseQuery(%q) failed: %v", tt.query, err)
+			}
+			res, err := store.Query(q)
+			if err != nil {
+				t.Fatalf("%s: Query(%q) failed: %v", label, tt.query, err)
+			}
+			var words []string
+			for _, e := range res.Entries {
+				words = append(words, e.Word)
+			}
+			if !reflect.DeepEqual(words, tt.want) {
+				t.Errorf("%s: Query(%q) = %v, want %v", label, tt.query, words, tt.want)
+			}
+			wantPlan := tt.indexPlan
+			if !indexed && wantPlan == "attributes index" {
+				wantPlan = "full scan"
+			}
+			if !strings.HasPrefix(res.Plan, wantPlan) {
+				t.Errorf("%s: Query(%q) plan = %q, want %q", label, tt.query, res.Plan, wantPlan)
+			}
+		}
+	}
+	run("scan", false)
+	if err := store.BuildIndexes(IndexAttributes); err != nil {
+		t.Fatalf("BuildIndexes() failed: %v", err)
+	}
+	run("index", true)
+}
+
+func TestMergeQueryResults(t *testing.T) {
+	q, err := ParseQuery("sort:-frq limit:3")
+	if err != nil {
+		t.Fatalf("ParseQuery() failed: %v", err)
+	}
+	glossary := &QueryResult{Plan: "full scan", Entries: []*Entry{
+		{Word: "abate", Frq: 100, Translation: "glossary"},
+		{Word: "zeal"},
+	}}
+	ecdict := &QueryResult{Plan: "attributes index (tag:gre)", Entries: []*Entry{
+		{Word: "abate", Frq: 12000},
+		{Word: "abandon", Frq: 700},
+		{Word: "aberrant", Frq: 30000},
+	}}
+	res := MergeQueryResults(q, []*QueryResult{glossary, ecdict})
+	var words []string
+	for _, e := range res.Entries {
+		words = append(words, e.Word)
+	}
+	if want := []string{"aberrant", "abandon", "abate"}; !reflect.DeepEqual(words, want) {
+		t.Errorf("MergeQueryResults() words = %v, want %v", words, want)
+	}
+	if res.Entries[2].Translation != "glossary" {
+		t.Errorf("MergeQueryResults() kept %+v, want the entry of the first dictionary", res.Entries[2])
+	}
+	if res.Plan != "full scan; attributes index (tag:gre)" {
+		t.Errorf("MergeQueryResults() plan = %q", res.Plan)
+	}
+}
@@ -0,0 +1,212 @@
+package bbolthelper
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// IndexRadix is the name of the radix tree index over the dictionary keys, used by FindSimilar.
+const IndexRadix = "radix"
+
+const (
+	// radixTreeKey is the key of the serialized tree within the radix index bucket.
+	radixTreeKey = "tree"
+	// radixFormatVersion is the first byte of a serialized tree.
+	radixFormatVersion = 1
+	// radixHeaderSize is the version byte followed by the little-endian uint64 root offset.
+	radixHeaderSize = 9
+	// radixTerminal marks a node that ends a dictionary key.
+	radixTerminal = 1
+)
+
+// The serialized radix tree is a flat byte slice that is searched in place, straight from the
+// memory-mapped bolt value, so `ne` never has to decode it into Go objects. Layout:
+//
+//	header: version byte, uint64 LE offset of the root node
+//	node:   flags byte, uvarint child count, then per child:
+//	        uvarint label length, label bytes (UTF-8), uvarint child node offset
+//
+// Nodes are written children first, so every offset points backwards. Edge labels are split on
+// rune boundaries only, which keeps edit distances rune-based like levenshtein.ComputeDistance.
+
+// radixIndexBuilder collects the dictionary keys, which BuildIndexes passes in sorted order.
+type radixIndexBuilder struct {
+	words []string
+}
+
+func newRadixIndexBuilder() indexBuilder {
+	return &radixIndexBuilder{}
+}
+
+func (rb *radixIndexBuilder) add(word string, fields map[string]string) {
+	rb.words = append(rb.words, word)
+}
+
+func (rb *radixIndexBuilder) emit(put func(key, value []byte) error) error {
+	return put([]byte(radixTreeKey), buildRadixTree(rb.words))
+}
+
+// buildRadixTree serializes a radix tree over words, which must be sorted and unique.
+func buildRadixTree(words []string) []byte {
+	var buf bytes.Buffer
+	buf.Write(make([]byte, radixHeaderSize))
+	root := writeRadixNode(&buf, words, 0)
+	data := buf.Bytes()
+	data[0] = radixFormatVersion
+	binary.LittleEndian.PutUint64(data[1:radixHeaderSize], uint64(root))
+	return data
+}
+
+// writeRadixNode writes the subtree of words that share their first depth bytes and returns its offset.
+func writeRadixNode(buf *bytes.Buffer, words []string, depth int) int {
+	var flags byte
+	if len(words) > 0 && len(words[0]) == depth {
+		// Sorted order puts the word that ends here first.
+		flags |= radixTerminal
+		words = words[1:]
+	}
+
+	type edge struct {
+		label  string
+		offset int
+	}
+	var edges []edge
+	for len(words) > 0 {
+		// Group the words continuing with the same rune; they are contiguous because words are sorted.
+		_, size := utf8.DecodeRuneInString(words[0][depth:])
+		lead := words[0][depth : depth+size]
+		n := 1
+		for n < len(words) && strings.HasPrefix(words[n][depth:], lead) {
+			n++
+		}
+		group := words[:n]
+		words = words[n:]
+
+		// The common prefix of a sorted group is that of its first and last word.
+		first, last := group[0], group[len(group)-1]
+		end := depth + size
+		for end < len(first) && end < len(last) && first[end] == last[end] {
+			end++
+		}
+		for end < len(first) && !utf8.RuneStart(first[end]) {
+			end--
+		}
+		edges = append(edges, edge{label: first[depth:end], offset: writeRadixNode(buf, group, end)})
+	}
+
+	offset := buf.Len()
+	var tmp [binary.MaxVarintLen64]byte
+	buf.WriteByte(flags)
+	buf.Write(tmp[:binary.PutUvarint(tmp[:], uint64(len(edges)))])
+	for _, e := range edges {
+		buf.Write(tmp[:binary.PutUvarint(tmp[:], uint64(len(e.label)))])
+		buf.WriteString(e.label)
+		buf.Write(tmp[:binary.PutUvarint(tmp[:], uint64(e.offset))])
+	}
+	return offset
+}
+
+// radixTree is a read-only view of a serialized radix tree.
+type radixTree []byte
+
+// openRadixTree validates the header of a serialized tree.
+func openRadixTree(data []byte) (radixTree, error) {
+	if len(data) < radixHeaderSize {
+		return nil, fmt.Errorf("radix index is truncated (%d bytes)", len(data))
+	}
+	if data[0] != radixFormatVersion {
+		return nil, fmt.Errorf("unsupported radix index format version %d", data[0])
+	}
+	return radixTree(data), nil
+}
+
+// fuzzySearch calls visit for every key within maxDistance edits of word, with its distance.
+// It walks the tree while maintaining one row of the Levenshtein matrix per rune of the path and
+// abandons a branch as soon as every entry of the row exceeds maxDistance.
+func (t radixTree) fuzzySearch(word string, maxDistance int, visit func(key string, dist int)) error {
+	target := []rune(word)
+	row := make([]int, len(target)+1)
+	for i := range row {
+		row[i] = i
+	}
+	root := binary.LittleEndian.Uint64(t[1:radixHeaderSize])
+	return t.searchNode(int(root), target, row, nil, maxDistance, visit)
+}
+
+func (t radixTree) searchNode(offset int, target []rune, row []int, path []byte, maxDistance int, visit func(string, int)) error {
+	if offset < radixHeaderSize || offset >= len(t) {
+		return fmt.Errorf("radix index node offset %d out of range", offset)
+	}
+	if t[offset]&radixTerminal != 0 && row[len(target)] <= maxDistance {
+		visit(string(path), row[len(target)])
+	}
+
+	pos := offset + 1
+	children, err := t.uvarint(&pos)
+	if err != nil {
+		return err
+	}
+	for ; children > 0; children-- {
+		labelLen, err := t.uvarint(&pos)
+		if err != nil {
+			return err
+		}
+		if pos+labelLen > len(t) {
+			return fmt.Errorf("radix index label at %d out of range", pos)
+		}
+		label := t[pos : pos+labelLen]
+		pos += labelLen
+		child, err := t.uvarint(&pos)
+		if err != nil {
+			return err
+		}
+
+		childRow, ok := advanceRows(row, target, label, maxDistance)
+		if !ok {
+			continue
+		}
+		if err := t.searchNode(child, target, childRow, append(path, label...), maxDistance, visit); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// advanceRows extends the Levenshtein row by every rune of label. It reports false when the
+// branch can be pruned because no row entry is within maxDistance.
+func advanceRows(row []int, target []rune, label []byte, maxDistance int) ([]int, bool) {
+	for len(label) > 0 {
+		r, size := utf8.DecodeRune(label)
+		label = label[size:]
+
+		next := make([]int, len(row))
+		next[0] = row[0] + 1
+		best := next[0]
+		for i := 1; i < len(row); i++ {
+			cost := 1
+			if target[i-1] == r {
+				cost = 0
+			}
+			next[i] = min(next[i-1]+1, row[i]+1, row[i-1]+cost)
+			best = min(best, next[i])
+		}
+		if best > maxDistance {
+			return nil, false
+		}
+		row = next
+	}
+	return row, true
+}
+
+// uvarint reads an unsigned varint at *pos and advances it.
+func (t radixTree) uvarint(pos *int) (int, error) {
+	v, n := binary.Uvarint(t[*pos:])
+	if n <= 0 {
+		return 0, fmt.Errorf("radix index is corrupt at offset %d", *pos)
+	}
+	*pos += n
+	return int(v), nil
+}
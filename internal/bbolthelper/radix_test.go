@@ -0,0 +1,113 @@
+package bbolthelper
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/agnivade/levenshtein"
+	bolt "go.etcd.io/bbolt"
+)
+
+// radixTestWords mixes shared prefixes, prefix-of-another keys, phrases and multi-byte runes
+// that share their UTF-8 lead byte (é and è are both 0xC3 ...).
+var radixTestWords = []string{
+	"a", "app", "apple", "applet", "apply", "bat", "cafe", "café", "cafè", "cat",
+	"de facto", "devel", "develop", "developer", "development", "mat", "naïve", "rat", "test", "testing",
+}
+
+func TestRadixTree_FuzzySearchMatchesLevenshtein(t *testing.T) {
+	words := append([]string(nil), radixTestWords...)
+	sort.Strings(words)
+	tree, err := openRadixTree(buildRadixTree(words))
+	if err != nil {
+		t.Fatalf("openRadixTree() failed: %v", err)
+	}
+
+	queries := []string{"", "a", "aple", "cafe", "cafë", "develp", "deveoper", "dat", "de-facto", "naive", "testin", "zzzz"}
+	for _, q := range queries {
+		for maxDistance := 0; maxDistance <= 2; maxDistance++ {
+			got := map[string]int{}
+			err := tree.fuzzySearch(q, maxDistance, func(key string, dist int) { got[key] = dist })
+			if err != nil {
+				t.Fatalf("fuzzySearch(%q, %d) failed: %v", q, maxDistance, err)
+			}
+			want := map[string]int{}
+			for _, w := range words {
+				if d := levenshtein.ComputeDistance(q, w); d <= maxDistance {
+					want[w] = d
+				}
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("fuzzySearch(%q, %d) = %v, want %v", q, maxDistance, got, want)
+			}
+		}
+	}
+}
+
+func TestRadixTree_RejectsBadData(t *testing.T) {
+	if _, err := openRadixTree(nil); err == nil {
+		t.Error("openRadixTree(nil) succeeded, want error")
+	}
+	data := buildRadixTree([]string{"go"})
+	data[0] = radixFormatVersion + 1
+	if _, err := openRadixTree(data); err == nil {
+		t.Error("openRadixTree() accepted an unknown version")
+	}
+
+	data = buildRadixTree([]string{"go", "went"})
+	tree, err := openRadixTree(data[:len(data)-2])
+	if err != nil {
+		t.Fatalf("openRadixTree() failed: %v", err)
+	}
+	if err := tree.fuzzySearch("go", 1, func(string, int) {}); err == nil {
+		t.Error("fuzzySearch() on a truncated tree succeeded, want error")
+	}
+}
+
+func TestDBStore_FindSimilarWithRadixIndex(t *testing.T) {
+	store := newTestStore(t, Config{BucketName: "TestBucket"})
+	freqs := map[string]string{"develop": "100", "development": "80", "developer": "90", "devel": "70", "apple": "300", "apply": "250"}
+	for word, freq := range freqs {
+		if err := store.Put(word, map[string]string{FieldFrq: freq}); err != nil {
+			t.Fatalf("Put(%s) failed: %v", word, err)
+		}
+	}
+
+	scan, err := store.FindSimilar("develp", 1)
+	if err != nil {
+		t.Fatalf("FindSimilar() without index failed: %v", err)
+	}
+	if err := store.BuildIndexes(IndexRadix); err != nil {
+		t.Fatalf("BuildIndexes() failed: %v", err)
+	}
+	for _, tt := range []struct {
+		word string
+		want []string
+	}{
+		{"develp", scan},
+		{"develp", []string{"devel", "develop"}},
+		{"apple", []string{"apply"}},
+		{"xyz", []string{}},
+	} {
+		got, err := store.FindSimilar(tt.word, 1)
+		if err != nil {
+			t.Fatalf("FindSimilar(%s) failed: %v", tt.word, err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("FindSimilar(%s) with radix index = %v, want %v", tt.word, got, tt.want)
+		}
+	}
+
+	// A corrupt index must not break lookups: FindSimilar falls back to the scan.
+	err = store.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(store.indexBucketName(IndexRadix)).Put([]byte(radixTreeKey), []byte{0xFF})
+	})
+	if err != nil {
+		t.Fatalf("Failed to corrupt radix index: %v", err)
+	}
+	got, err := store.FindSimilar("develp", 1)
+	if err != nil || !reflect.DeepEqual(got, scan) {
+		t.Errorf("FindSimilar() with corrupt index = %v, %v; want %v", got, err, scan)
+	}
+}
@@ -0,0 +1,338 @@
+package bbolthelper
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	bolt "go.etcd.io/bbolt"
+	"go.uber.org/zap"
+)
+
+// IndexRhymes is the name of the reversed-phonetic index used by Rhymes.
+const IndexRhymes = "rhymes"
+
+// The rhymes index bucket maps "<rhyme key>|<word>" to the word's uvarint syllable count, where
+// the rhyme key is the word's rhyme part (the phonemes from its last stressed vowel onward) in
+// reverse order, joined by ".". Words that rhyme perfectly therefore share a key prefix, and near
+// rhymes are found by looking up every single-phoneme variation of the key. The phonemes seen at
+// build time are stored as a JSON list under rhymePhonemesKey to enumerate those variations.
+const (
+	rhymeKeySep      = "|"
+	rhymePhonemeSep  = "."
+	rhymePhonemesKey = "\x00phonemes"
+)
+
+// RhymeMatch is a word that rhymes with the query.
+type RhymeMatch struct {
+	Word      string `json:"word"`
+	Frq       int    `json:"frq,omitempty"`
+	Syllables int    `json:"syllables"`
+	// Perfect is true when the rhyme parts are identical; near rhymes differ in one sound.
+	Perfect bool `json:"perfect"`
+}
+
+// RhymeOptions controls Rhymes.
+type RhymeOptions struct {
+	// Near also returns near rhymes, whose rhyme part differs from the word's in one sound
+	// (e.g. time/line, bend/band).
+	Near bool
+	// Limit is the maximum number of words returned; <= 0 returns all.
+	Limit int
+}
+
+var (
+	// ipaOptional matches optional sounds such as the British "(r)" in "ˈsʌmə(r)".
+	ipaOptional = regexp.MustCompile(`\([^)]*\)`)
+	// ipaReplacer normalizes the ASCII and look-alike characters found in ECDICT's phonetics, and
+	// the older diphthong spellings (e.g. "tai" for "taɪ") to modern IPA.
+	ipaReplacer = strings.NewReplacer("'", "ˈ", ":", "ː", "∫", "ʃ", "ɡ", "g", "ε", "e", "ɛ", "e",
+		"ai", "aɪ", "ei", "eɪ", "au", "aʊ", "əu", "əʊ", "ɔi", "ɔɪ")
+	// ipaMultiPhonemes are the phonemes written with more than one character, longest first.
+	ipaMultiPhonemes = []string{"aɪ", "aʊ", "eɪ", "əʊ", "oʊ", "ɔɪ", "ɪə", "eə", "ʊə", "tʃ", "dʒ"}
+)
+
+// ipaIsVowel reports whether a phoneme is a vowel, i.e. the nucleus of a syllable.
+func ipaIsVowel(phoneme string) bool {
+	for _, r := range phoneme {
+		return strings.ContainsRune("aeiouæɑɒɔəɜɪʊʌɚɝɐ", r)
+	}
+	return false
+}
+
+// ipaPhonemes splits the first variant of an ECDICT phonetic transcription into phonemes and
+// returns the index of the vowel carrying the last primary stress, or -1 when none is marked.
+func ipaPhonemes(phonetic string) ([]string, int) {
+	phonetic, _, _ = strings.Cut(phonetic, ",")
+	phonetic, _, _ = strings.Cut(phonetic, ";")
+	phonetic = ipaReplacer.Replace(ipaOptional.ReplaceAllString(strings.ToLower(phonetic), ""))
+
+	var phonemes []string
+	stressed, pendingStress := -1, false
+	for len(phonetic) > 0 {
+		if strings.HasPrefix(phonetic, "ˈ") {
+			pendingStress = true
+			phonetic = phonetic[len("ˈ"):]
+			continue
+		}
+		if strings.HasPrefix(phonetic, "ː") {
+			if len(phonemes) > 0 {
+				phonemes[len(phonemes)-1] += "ː"
+			}
+			phonetic = phonetic[len("ː"):]
+			continue
+		}
+		phoneme := ""
+		for _, p := range ipaMultiPhonemes {
+			if strings.HasPrefix(phonetic, p) {
+				phoneme = p
+				break
+			}
+		}
+		if phoneme == "" {
+			r := []rune(phonetic)[0]
+			phoneme = string(r)
+			if !strings.ContainsRune("abcdefghijklmnopqrstuvwxzæɑɒɔəɜɪʊʌɚɝɐʃʒθðŋ", r) {
+				// Secondary stress, syllable dots, spaces and other marks carry no sound.
+				phonetic = phonetic[len(phoneme):]
+				continue
+			}
+		}
+		if pendingStress && ipaIsVowel(phoneme) {
+			stressed, pendingStress = len(phonemes), false
+		}
+		phonemes = append(phonemes, phoneme)
+		phonetic = phonetic[len(phoneme):]
+	}
+	return phonemes, stressed
+}
+
+// rhymePart returns the phonemes of a transcription from its last stressed vowel onward, and the
+// number of syllables. Without a stress mark the last full (non-schwa) vowel is taken, which is
+// right for the monosyllables ECDICT leaves unmarked. It returns nil when there is no vowel.
+func rhymePart(phonetic string) ([]string, int) {
+	phonemes, stressed := ipaPhonemes(phonetic)
+	syllables, lastVowel, lastFull := 0, -1, -1
+	for i, p := range phonemes {
+		if ipaIsVowel(p) {
+			syllables++
+			lastVowel = i
+			if p != "ə" && p != "ɚ" {
+				lastFull = i
+			}
+		}
+	}
+	if syllables == 0 {
+		return nil, 0
+	}
+	if stressed < 0 {
+		stressed = lastFull
+	}
+	if stressed < 0 {
+		stressed = lastVowel
+	}
+	return phonemes[stressed:], syllables
+}
+
+// rhymeKey joins a rhyme part in reverse order, so that words ending alike share a key prefix.
+func rhymeKey(part []string) string {
+	reversed := slices.Clone(part)
+	slices.Reverse(reversed)
+	return strings.Join(reversed, rhymePhonemeSep)
+}
+
+// rhymeIndexBuilder collects the rhyme key of every word with a transcription.
+type rhymeIndexBuilder struct {
+	entries  map[string]int
+	phonemes map[string]bool
+}
+
+func newRhymeIndexBuilder() indexBuilder {
+	return &rhymeIndexBuilder{entries: make(map[string]int), phonemes: make(map[string]bool)}
+}
+
+func (rb *rhymeIndexBuilder) add(word string, fields map[string]string) {
+	part, syllables := rhymePart(fields[FieldPhonetic])
+	if part == nil {
+		return
+	}
+	for _, p := range part {
+		rb.phonemes[p] = true
+	}
+	rb.entries[rhymeKey(part)+rhymeKeySep+word] = syllables
+}
+
+func (rb *rhymeIndexBuilder) emit(put func(key, value []byte) error) error {
+	phonemes := make([]string, 0, len(rb.phonemes))
+	for p := range rb.phonemes {
+		phonemes = append(phonemes, p)
+	}
+	sort.Strings(phonemes)
+	data, err := json.Marshal(phonemes)
+	if err != nil {
+		return err
+	}
+	if err := put([]byte(rhymePhonemesKey), data); err != nil {
+		return err
+	}
+
+	keys := make([]string, 0, len(rb.entries))
+	for k := range rb.entries {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if err := put([]byte(k), binary.AppendUvarint(nil, uint64(rb.entries[k]))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// nearRhymeParts returns every variation of part with one phoneme replaced by another of the
+// same kind (vowel for vowel, consonant for consonant) from phonemes.
+func nearRhymeParts(part, phonemes []string) [][]string {
+	var variants [][]string
+	for i, orig := range part {
+		for _, p := range phonemes {
+			if p == orig || ipaIsVowel(p) != ipaIsVowel(orig) {
+				continue
+			}
+			variant := slices.Clone(part)
+			variant[i] = p
+			variants = append(variants, variant)
+		}
+	}
+	return variants
+}
+
+// isNearRhyme reports whether two rhyme parts differ in exactly one phoneme of the same kind.
+func isNearRhyme(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	diff := 0
+	for i := range a {
+		if a[i] != b[i] {
+			if ipaIsVowel(a[i]) != ipaIsVowel(b[i]) {
+				return false
+			}
+			diff++
+		}
+	}
+	return diff == 1
+}
+
+// Rhymes returns the words rhyming with word, judged on the phonetic field: perfect rhymes share
+// the sounds from the last stressed vowel onward. Results are ordered perfect rhymes first, then
+// by syllable count and frequency. It uses the rhymes index when it was built and scans every
+// record otherwise. Words without a transcription have no rhymes.
+func (s *DBStore) Rhymes(word string, opts RhymeOptions) ([]RhymeMatch, error) {
+	word = strings.ToLower(word)
+	var matches []RhymeMatch
+	err := s.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(s.bucketName))
+		if b == nil {
+			return fmt.Errorf("bucket '%s' not found during Rhymes operation", s.bucketName)
+		}
+		v := b.Get([]byte(word))
+		if v == nil {
+			return nil
+		}
+		phonetic, _, err := DeserializeField(v, FieldPhonetic)
+		if err != nil {
+			return fmt.Errorf("failed to deserialize value for key '%s': %w", word, err)
+		}
+		part, _ := rhymePart(phonetic)
+		if part == nil {
+			return nil
+		}
+
+		add := func(candidate string, syllables int, perfect bool) {
+			if candidate == word {
+				return
+			}
+			if cv := b.Get([]byte(candidate)); cv != nil {
+				c := s.completion([]byte(candidate), cv)
+				matches = append(matches, RhymeMatch{Word: candidate, Frq: c.Frq, Syllables: syllables, Perfect: perfect})
+			}
+		}
+
+		if ib := tx.Bucket(s.indexBucketName(IndexRhymes)); ib != nil {
+			s.logger.Debug("Rhymes used rhymes index", zap.Strings("rhymePart", part))
+			keys := []string{rhymeKey(part)}
+			if opts.Near {
+				var phonemes []string
+				if err := json.Unmarshal(ib.Get([]byte(rhymePhonemesKey)), &phonemes); err != nil {
+					return fmt.Errorf("failed to decode rhymes index phonemes: %w", err)
+				}
+				for _, variant := range nearRhymeParts(part, phonemes) {
+					keys = append(keys, rhymeKey(variant))
+				}
+			}
+			for i, key := range keys {
+				prefix := []byte(key + rhymeKeySep)
+				c := ib.Cursor()
+				for k, sv := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, sv = c.Next() {
+					syllables, _ := binary.Uvarint(sv)
+					add(string(k[len(prefix):]), int(syllables), i == 0)
+				}
+			}
+			return nil
+		}
+
+		s.logger.Debug("Rhymes index not built, scanning all phonetics", zap.Strings("rhymePart", part))
+		return b.ForEach(func(k, cv []byte) error {
+			candidatePhonetic, _, err := DeserializeField(cv, FieldPhonetic)
+			if err != nil || candidatePhonetic == "" {
+				return nil
+			}
+			candidatePart, syllables := rhymePart(candidatePhonetic)
+			if perfect := slices.Equal(candidatePart, part); perfect || (opts.Near && isNearRhyme(candidatePart, part)) {
+				add(string(k), syllables, perfect)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return orderRhymes(matches, opts.Limit), nil
+}
+
+// MergeRhymes merges the rhymes of several dictionaries, given in priority order, as Rhymes
+// orders those of one dictionary. A word found in several dictionaries is listed once.
+func MergeRhymes(lists [][]RhymeMatch, limit int) []RhymeMatch {
+	return orderRhymes(mergeResults(lists, func(m RhymeMatch) string { return m.Word }), limit)
+}
+
+// orderRhymes sorts perfect rhymes before near ones, then by syllables and frequency, and applies
+// limit.
+func orderRhymes(matches []RhymeMatch, limit int) []RhymeMatch {
+	sort.SliceStable(matches, func(i, j int) bool {
+		a, b := matches[i], matches[j]
+		if a.Perfect != b.Perfect {
+			return a.Perfect
+		}
+		if a.Syllables != b.Syllables {
+			return a.Syllables < b.Syllables
+		}
+		if (a.Frq == 0) != (b.Frq == 0) {
+			return b.Frq == 0 // Ranked words before unranked ones
+		}
+		if a.Frq != b.Frq {
+			return a.Frq < b.Frq // Lower frq value first (higher frequency)
+		}
+		return a.Word < b.Word
+	})
+	if limit > 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches
+}
@@ -0,0 +1,96 @@
+package bbolthelper
+
+import (
+	"reflect"
+	"testing"
+)
+
+// rhymeTestRows use ECDICT-style transcriptions, including its ASCII stress and length marks.
+var rhymeTestRows = []string{
+	`time,taim,,,,,,,,300,,,`,
+	`line,lain,,,,,,,,600,,,`,
+	`mime,maim,,,,,,,,9000,,,`,
+	`rhyme,raim,,,,,,,,7000,,,`,
+	`sublime,sə'blaim,,,,,,,,8000,,,`,
+	`sometime,'sʌmtaim,,,,,,,,5000,,,`,
+	`nation,ˈneɪʃən,,,,,,,,900,,,`,
+	`station,'stei∫ən,,,,,,,,1100,,,`,
+	`relation,ri'lei∫ən,,,,,,,,1500,,,`,
+	`passion,ˈpæʃən,,,,,,,,2000,,,`,
+	`bend,bend,,,,,,,,4000,,,`,
+	`band,bænd,,,,,,,,3000,,,`,
+	`summer,ˈsʌmə(r),,,,,,,,1000,,,`,
+	`drummer,ˈdrʌmə,,,,,,,,12000,,,`,
+	`silent,,,,,,,,,,,,`,
+}
+
+func TestRhymePart(t *testing.T) {
+	tests := []struct {
+		phonetic  string
+		part      []string
+		syllables int
+	}{
+		{"taim", []string{"aɪ", "m"}, 1},
+		{"ˈneɪʃən", []string{"eɪ", "ʃ", "ə", "n"}, 2},
+		{"'stei∫ən", []string{"eɪ", "ʃ", "ə", "n"}, 2},
+		{"ri'lei∫ən", []string{"eɪ", "ʃ", "ə", "n"}, 3},
+		{"ˈsʌmə(r)", []string{"ʌ", "m", "ə"}, 2},
+		{"fɑ:", []string{"ɑː"}, 1},
+		{"gəʊ", []string{"əʊ"}, 1},
+		{"ˈæpl, ˈæpəl", []string{"æ", "p", "l"}, 1},
+		{"", nil, 0},
+	}
+	for _, tt := range tests {
+		part, syllables := rhymePart(tt.phonetic)
+		if !reflect.DeepEqual(part, tt.part) || syllables != tt.syllables {
+			t.Errorf("rhymePart(%q) = %q, %d; want %q, %d", tt.phonetic, part, syllables, tt.part, tt.syllables)
+		}
+	}
+}
+
+func TestDBStore_Rhymes(t *testing.T) {
+	store := newTestStore(t, Config{BucketName: "TestBucket"})
+	if _, err := store.ImportFromCSV(writeTestCSV(t, t.TempDir(), rhymeTestRows), 0); err != nil {
+		t.Fatalf("ImportFromCSV() failed: %v", err)
+	}
+
+	type result struct {
+		word      string
+		syllables int
+		perfect   bool
+	}
+	tests := []struct {
+		word string
+		opts RhymeOptions
+		want []result
+	}{
+		// Perfect rhymes by syllable count, then frequency. "sometime" is stressed on its first syllable.
+		{"time", RhymeOptions{}, []result{{"rhyme", 1, true}, {"mime", 1, true}, {"sublime", 2, true}}},
+		{"time", RhymeOptions{Near: true, Limit: 4}, []result{{"rhyme", 1, true}, {"mime", 1, true}, {"sublime", 2, true}, {"line", 1, false}}},
+		{"station", RhymeOptions{}, []result{{"nation", 2, true}, {"relation", 3, true}}},
+		{"bend", RhymeOptions{Near: true}, []result{{"band", 1, false}}},
+		{"Summer", RhymeOptions{}, []result{{"drummer", 2, true}}},
+		{"silent", RhymeOptions{}, nil},
+		{"unknown", RhymeOptions{}, nil},
+	}
+	run := func(label string) {
+		for _, tt := range tests {
+			got, err := store.Rhymes(tt.word, tt.opts)
+			if err != nil {
+				t.Fatalf("%s: Rhymes(%q) failed: %v", label, tt.word, err)
+			}
+			var results []result
+			for _, m := range got {
+				results = append(results, result{m.Word, m.Syllables, m.Perfect})
+			}
+			if !reflect.DeepEqual(results, tt.want) {
+				t.Errorf("%s: Rhymes(%q, %+v) = %v, want %v", label, tt.word, tt.opts, results, tt.want)
+			}
+		}
+	}
+	run("scan")
+	if err := store.BuildIndexes(IndexRhymes); err != nil {
+		t.Fatalf("BuildIndexes() failed: %v", err)
+	}
+	run("index")
+}
@@ -0,0 +1,141 @@
+package bbolthelper
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/agnivade/levenshtein"
+	bolt "go.etcd.io/bbolt"
+	"go.uber.org/zap"
+)
+
+// IndexMetaphone is the name of the index mapping Double Metaphone codes to words, used by SoundsLike.
+const IndexMetaphone = "metaphone"
+
+// SoundMatch is a word that sounds like the query.
+type SoundMatch struct {
+	Word string `json:"word"`
+	Frq  int    `json:"frq,omitempty"`
+	// Distance is the Levenshtein distance between the spellings.
+	Distance int `json:"distance"`
+}
+
+// metaphoneCodes returns the distinct, non-empty Double Metaphone codes of word.
+func metaphoneCodes(word string) []string {
+	primary, alternate := doubleMetaphone(word)
+	var codes []string
+	if primary != "" {
+		codes = append(codes, primary)
+	}
+	if alternate != "" && alternate != primary {
+		codes = append(codes, alternate)
+	}
+	return codes
+}
+
+// metaphoneIndexBuilder groups the dictionary keys by their primary and alternate codes.
+type metaphoneIndexBuilder struct {
+	words map[string][]string
+}
+
+func newMetaphoneIndexBuilder() indexBuilder {
+	return &metaphoneIndexBuilder{words: make(map[string][]string)}
+}
+
+func (mb *metaphoneIndexBuilder) add(word string, fields map[string]string) {
+	for _, code := range metaphoneCodes(word) {
+		mb.words[code] = append(mb.words[code], word)
+	}
+}
+
+func (mb *metaphoneIndexBuilder) emit(put func(key, value []byte) error) error {
+	return emitSortedLists(mb.words, "\n", put)
+}
+
+// SoundsLike returns the words pronounced like word according to Double Metaphone, catching
+// phonetic misspellings that are several edits away ("fonetik" for "phonetic"). Words sharing
+// either code are merged and ranked by edit distance, then frequency. It uses the metaphone index
+// when it was built and encodes every key otherwise. A limit <= 0 returns all matches.
+func (s *DBStore) SoundsLike(word string, limit int) ([]SoundMatch, error) {
+	word = strings.ToLower(word)
+	codes := metaphoneCodes(word)
+	if len(codes) == 0 {
+		return nil, nil
+	}
+
+	var matches []SoundMatch
+	err := s.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(s.bucketName))
+		if b == nil {
+			return fmt.Errorf("bucket '%s' not found during SoundsLike operation", s.bucketName)
+		}
+
+		var words []string
+		if ib := tx.Bucket(s.indexBucketName(IndexMetaphone)); ib != nil {
+			s.logger.Debug("SoundsLike used metaphone index", zap.Strings("codes", codes))
+			for _, code := range codes {
+				if v := ib.Get([]byte(code)); v != nil {
+					words = append(words, strings.Split(string(v), "\n")...)
+				}
+			}
+		} else {
+			s.logger.Debug("Metaphone index not built, encoding all keys", zap.Strings("codes", codes))
+			c := b.Cursor()
+			for k, _ := c.First(); k != nil; k, _ = c.Next() {
+				for _, code := range metaphoneCodes(string(k)) {
+					if slices.Contains(codes, code) {
+						words = append(words, string(k))
+						break
+					}
+				}
+			}
+		}
+
+		for _, w := range dedupe(words) {
+			if w == word {
+				continue
+			}
+			v := b.Get([]byte(w))
+			if v == nil {
+				continue // Index is stale
+			}
+			c := s.completion([]byte(w), v)
+			matches = append(matches, SoundMatch{Word: w, Frq: c.Frq, Distance: levenshtein.ComputeDistance(word, w)})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return orderSoundMatches(matches, limit), nil
+}
+
+// MergeSoundMatches merges the matches of several dictionaries, given in priority order, as
+// SoundsLike orders those of one dictionary. A word found in several dictionaries is listed once.
+func MergeSoundMatches(lists [][]SoundMatch, limit int) []SoundMatch {
+	return orderSoundMatches(mergeResults(lists, func(m SoundMatch) string { return m.Word }), limit)
+}
+
+// orderSoundMatches sorts matches by distance, then frequency, and applies limit.
+func orderSoundMatches(matches []SoundMatch, limit int) []SoundMatch {
+	sort.Slice(matches, func(i, j int) bool {
+		a, b := matches[i], matches[j]
+		if a.Distance != b.Distance {
+			return a.Distance < b.Distance
+		}
+		if (a.Frq == 0) != (b.Frq == 0) {
+			return b.Frq == 0 // Ranked words before unranked ones
+		}
+		if a.Frq != b.Frq {
+			return a.Frq < b.Frq // Lower frq value first (higher frequency)
+		}
+		return a.Word < b.Word
+	})
+	if limit > 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches
+}
@@ -0,0 +1,65 @@
+package bbolthelper
+
+import (
+	"reflect"
+	"testing"
+)
+
+// soundsLikeTestRows extends the fixture with words that share Double Metaphone codes.
+var soundsLikeTestRows = append([]string{
+	`phonetic,,,,,,,,,4000,,,`,
+	`phonetics,,,,,,,,,9000,,,`,
+	`fanatic,,,,,,,,,7000,,,`,
+	`knowledge,,,,,,,,,800,,,`,
+	`knowledgeable,,,,,,,,,5000,,,`,
+	`smith,,,,,,,,,3000,,,`,
+	`schmidt,,,,,,,,,,,,`,
+}, testCSVRows...)
+
+func TestDBStore_SoundsLike(t *testing.T) {
+	store := newTestStore(t, Config{BucketName: "TestBucket"})
+	if _, err := store.ImportFromCSV(writeTestCSV(t, t.TempDir(), soundsLikeTestRows), 0); err != nil {
+		t.Fatalf("ImportFromCSV() failed: %v", err)
+	}
+
+	type result struct {
+		word     string
+		distance int
+	}
+	tests := []struct {
+		word  string
+		limit int
+		want  []result
+	}{
+		// Ranked by edit distance, then frequency.
+		{"fonetik", 0, []result{{"phonetic", 3}, {"fanatic", 3}, {"phonetics", 4}}},
+		{"fonetik", 2, []result{{"phonetic", 3}, {"fanatic", 3}}},
+		// "knowledgeable" encodes to NLJP and does not match.
+		{"nolege", 0, []result{{"knowledge", 3}}},
+		// The alternate code of "smith" (XMT) is the primary code of "schmidt".
+		{"Smith", 0, []result{{"schmidt", 4}}},
+		// The word itself is not a match.
+		{"phonetic", 0, []result{{"phonetics", 1}, {"fanatic", 4}}},
+		{"", 0, nil},
+	}
+	run := func(label string) {
+		for _, tt := range tests {
+			got, err := store.SoundsLike(tt.word, tt.limit)
+			if err != nil {
+				t.Fatalf("%s: SoundsLike(%q) failed: %v", label, tt.word, err)
+			}
+			var results []result
+			for _, m := range got {
+				results = append(results, result{m.Word, m.Distance})
+			}
+			if !reflect.DeepEqual(results, tt.want) {
+				t.Errorf("%s: SoundsLike(%q, %d) = %v, want %v", label, tt.word, tt.limit, results, tt.want)
+			}
+		}
+	}
+	run("scan")
+	if err := store.BuildIndexes(IndexMetaphone); err != nil {
+		t.Fatalf("BuildIndexes() failed: %v", err)
+	}
+	run("index")
+}
@@ -0,0 +1,660 @@
+package bbolthelper
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// SQLite file format constants (https://www.sqlite.org/fileformat2.html).
+const (
+	sqliteMagic      = "SQLite format 3\x00"
+	sqliteHeaderSize = 100
+
+	sqlitePageInteriorTable = 0x05
+	sqlitePageLeafTable     = 0x0d
+
+	// sqliteMaxDepth bounds the b-tree depth, so that a corrupt file cannot loop forever.
+	sqliteMaxDepth = 64
+)
+
+// sqliteFile reads the tables of an SQLite 3 database file without cgo or an SQL engine: it walks
+// the b-tree of a rowid table and decodes its records. Indexes, WITHOUT ROWID tables and
+// write-ahead logs are not read.
+type sqliteFile struct {
+	r        io.ReaderAt
+	pageSize int
+	// usable is the page size minus the bytes reserved at the end of every page.
+	usable   int
+	pages    uint32
+	encoding uint32
+}
+
+// openSQLite reads the header of an SQLite database of the given size.
+func openSQLite(r io.ReaderAt, size int64) (*sqliteFile, error) {
+	var hdr [sqliteHeaderSize]byte
+	if _, err := r.ReadAt(hdr[:], 0); err != nil {
+		return nil, fmt.Errorf("failed to read SQLite header: %w", err)
+	}
+	if string(hdr[:16]) != sqliteMagic {
+		return nil, errors.New("not an SQLite 3 database")
+	}
+	f := &sqliteFile{r: r, pageSize: int(binary.BigEndian.Uint16(hdr[16:18]))}
+	if f.pageSize == 1 {
+		f.pageSize = 65536
+	}
+	if f.pageSize < 512 || f.pageSize&(f.pageSize-1) != 0 {
+		return nil, fmt.Errorf("invalid SQLite page size %d", f.pageSize)
+	}
+	f.usable = f.pageSize - int(hdr[20])
+	f.pages = uint32(size / int64(f.pageSize))
+	f.encoding = binary.BigEndian.Uint32(hdr[56:60])
+	if f.encoding == 0 {
+		f.encoding = 1 // Empty databases have no encoding yet; UTF-8 is the default.
+	}
+	if f.encoding > 3 {
+		return nil, fmt.Errorf("unknown SQLite text encoding %d", f.encoding)
+	}
+	return f, nil
+}
+
+// page returns page n, numbered from 1.
+func (f *sqliteFile) page(n uint32) ([]byte, error) {
+	if n == 0 || n > f.pages {
+		return nil, fmt.Errorf("SQLite page %d out of range (database has %d pages)", n, f.pages)
+	}
+	p := make([]byte, f.pageSize)
+	if _, err := f.r.ReadAt(p, int64(n-1)*int64(f.pageSize)); err != nil {
+		return nil, fmt.Errorf("failed to read SQLite page %d: %w", n, err)
+	}
+	return p, nil
+}
+
+// sqliteVarint decodes the big-endian variable-length integer at the start of b and returns it
+// with its length, or a length of 0 when b is too short.
+func sqliteVarint(b []byte) (uint64, int) {
+	var v uint64
+	for i := 0; i < 9; i++ {
+		if i >= len(b) {
+			return 0, 0
+		}
+		if i == 8 {
+			return v<<8 | uint64(b[i]), 9
+		}
+		v = v<<7 | uint64(b[i]&0x7f)
+		if b[i] < 0x80 {
+			return v, i + 1
+		}
+	}
+	return v, 9
+}
+
+// payload returns the payload of size bytes of a table leaf cell starting at off, following the
+// overflow page chain for the part that does not fit in the page.
+func (f *sqliteFile) payload(page []byte, off int, size uint64) ([]byte, error) {
+	u := uint64(f.usable)
+	local := size
+	if maxLocal := u - 35; size > maxLocal {
+		minLocal := (u-12)*32/255 - 23
+		local = minLocal + (size-minLocal)%(u-4)
+		if local > maxLocal {
+			local = minLocal
+		}
+	}
+	if uint64(off)+local > uint64(len(page)) {
+		return nil, errors.New("SQLite cell extends past its page")
+	}
+	out := make([]byte, 0, size)
+	out = append(out, page[off:off+int(local)]...)
+	if local == size {
+		return out, nil
+	}
+	if off+int(local)+4 > len(page) {
+		return nil, errors.New("SQLite cell overflow pointer extends past its page")
+	}
+	next := binary.BigEndian.Uint32(page[off+int(local):])
+	for n := uint32(0); uint64(len(out)) < size; n++ {
+		if n > f.pages {
+			return nil, errors.New("SQLite overflow chain loops")
+		}
+		p, err := f.page(next)
+		if err != nil {
+			return nil, err
+		}
+		chunk := min(size-uint64(len(out)), u-4)
+		out = append(out, p[4:4+chunk]...)
+		next = binary.BigEndian.Uint32(p[:4])
+	}
+	return out, nil
+}
+
+// sqliteFrame is a page of a b-tree being walked by a cursor.
+type sqliteFrame struct {
+	page []byte
+	// hdr is the offset of the b-tree page header: 100 on page 1, after the database header.
+	hdr   int
+	cells int
+	i     int
+}
+
+// isLeaf reports whether the frame is a table leaf page.
+func (fr *sqliteFrame) isLeaf() bool {
+	return fr.page[fr.hdr] == sqlitePageLeafTable
+}
+
+// cell returns the offset of cell i of the page.
+func (fr *sqliteFrame) cell(i int) (int, error) {
+	headerSize := 12
+	if fr.isLeaf() {
+		headerSize = 8
+	}
+	at := fr.hdr + headerSize + 2*i
+	if at+2 > len(fr.page) {
+		return 0, errors.New("SQLite cell pointer array extends past its page")
+	}
+	off := int(binary.BigEndian.Uint16(fr.page[at:]))
+	if off >= len(fr.page) {
+		return 0, errors.New("SQLite cell offset past its page")
+	}
+	return off, nil
+}
+
+// sqliteCursor walks the rows of a table b-tree in rowid order.
+type sqliteCursor struct {
+	f     *sqliteFile
+	stack []sqliteFrame
+	// err is the error reading the root page, returned by the first call to next.
+	err error
+}
+
+// cursor returns a cursor over the table b-tree with the given root page.
+func (f *sqliteFile) cursor(root uint32) *sqliteCursor {
+	c := &sqliteCursor{f: f}
+	c.err = c.push(root)
+	return c
+}
+
+// push descends into page n.
+func (c *sqliteCursor) push(n uint32) error {
+	if len(c.stack) >= sqliteMaxDepth {
+		return errors.New("SQLite b-tree too deep")
+	}
+	p, err := c.f.page(n)
+	if err != nil {
+		return err
+	}
+	fr := sqliteFrame{page: p}
+	if n == 1 {
+		fr.hdr = sqliteHeaderSize
+	}
+	if fr.hdr+8 > len(p) {
+		return fmt.Errorf("SQLite page %d is truncated", n)
+	}
+	switch p[fr.hdr] {
+	case sqlitePageInteriorTable, sqlitePageLeafTable:
+	default:
+		return fmt.Errorf("SQLite page %d is not a table b-tree page (type %#x)", n, p[fr.hdr])
+	}
+	fr.cells = int(binary.BigEndian.Uint16(p[fr.hdr+3:]))
+	c.stack = append(c.stack, fr)
+	return nil
+}
+
+// next returns the rowid and record of the next row, or io.EOF after the last one.
+func (c *sqliteCursor) next() (int64, []byte, error) {
+	if c.err != nil {
+		return 0, nil, c.err
+	}
+	for len(c.stack) > 0 {
+		fr := &c.stack[len(c.stack)-1]
+		if fr.isLeaf() {
+			if fr.i >= fr.cells {
+				c.stack = c.stack[:len(c.stack)-1]
+				continue
+			}
+			off, err := fr.cell(fr.i)
+			if err != nil {
+				return 0, nil, err
+			}
+			fr.i++
+			size, n := sqliteVarint(fr.page[off:])
+			if n == 0 {
+				return 0, nil, errors.New("truncated SQLite cell")
+			}
+			rowid, m := sqliteVarint(fr.page[off+n:])
+			if m == 0 {
+				return 0, nil, errors.New("truncated SQLite cell")
+			}
+			payload, err := c.f.payload(fr.page, off+n+m, size)
+			return int64(rowid), payload, err
+		}
+
+		// Interior page: the left child of every cell, then the right-most child.
+		var child uint32
+		switch {
+		case fr.i < fr.cells:
+			off, err := fr.cell(fr.i)
+			if err != nil {
+				return 0, nil, err
+			}
+			if off+4 > len(fr.page) {
+				return 0, nil, errors.New("truncated SQLite cell")
+			}
+			child = binary.BigEndian.Uint32(fr.page[off:])
+		case fr.i == fr.cells:
+			child = binary.BigEndian.Uint32(fr.page[fr.hdr+8:])
+		default:
+			c.stack = c.stack[:len(c.stack)-1]
+			continue
+		}
+		fr.i++
+		if err := c.push(child); err != nil {
+			return 0, nil, err
+		}
+	}
+	return 0, nil, io.EOF
+}
+
+// record decodes an SQLite record into its values: nil for NULL, int64, float64, string for text
+// and []byte for blobs.
+func (f *sqliteFile) record(payload []byte) ([]any, error) {
+	hdrSize, n := sqliteVarint(payload)
+	if n == 0 || hdrSize > uint64(len(payload)) {
+		return nil, errors.New("malformed SQLite record header")
+	}
+	var values []any
+	body := payload[hdrSize:]
+	for pos := n; pos < int(hdrSize); {
+		serial, m := sqliteVarint(payload[pos:hdrSize])
+		if m == 0 {
+			return nil, errors.New("malformed SQLite record header")
+		}
+		pos += m
+
+		var size uint64
+		switch {
+		case serial <= 4:
+			size = serial
+		case serial == 5:
+			size = 6
+		case serial == 6 || serial == 7:
+			size = 8
+		case serial >= 12:
+			size = (serial - 12) / 2
+		}
+		if size > uint64(len(body)) {
+			return nil, errors.New("SQLite record value extends past its payload")
+		}
+		data := body[:size]
+		body = body[size:]
+
+		switch {
+		case serial == 0:
+			values = append(values, nil)
+		case serial <= 6:
+			// Big-endian two's complement integers of 1, 2, 3, 4, 6 or 8 bytes.
+			v := int64(int8(data[0]))
+			for _, b := range data[1:] {
+				v = v<<8 | int64(b)
+			}
+			values = append(values, v)
+		case serial == 7:
+			values = append(values, math.Float64frombits(binary.BigEndian.Uint64(data)))
+		case serial == 8 || serial == 9:
+			values = append(values, int64(serial-8))
+		case serial >= 12 && serial%2 == 0:
+			values = append(values, data)
+		case serial >= 13:
+			values = append(values, f.text(data))
+		default:
+			return nil, fmt.Errorf("reserved SQLite serial type %d", serial)
+		}
+	}
+	return values, nil
+}
+
+// text decodes a text value in the encoding of the database.
+func (f *sqliteFile) text(b []byte) string {
+	if f.encoding == 1 {
+		return string(b)
+	}
+	order := binary.ByteOrder(binary.LittleEndian)
+	if f.encoding == 3 {
+		order = binary.BigEndian
+	}
+	u := make([]uint16, len(b)/2)
+	for i := range u {
+		u[i] = order.Uint16(b[2*i:])
+	}
+	return string(utf16.Decode(u))
+}
+
+// sqliteValueString renders a value decoded by record as text, the way SQLite casts it: NULL is
+// empty and numbers are in decimal.
+func sqliteValueString(v any) string {
+	switch v := v.(type) {
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	}
+	return ""
+}
+
+// sqliteTable is a rowid table of an SQLite database.
+type sqliteTable struct {
+	name    string
+	root    uint32
+	columns []string
+	// rowidColumn is the index of the INTEGER PRIMARY KEY column, which stores NULL and is an
+	// alias for the rowid, or -1.
+	rowidColumn int
+}
+
+// tables returns the tables of the database from the schema table on page 1. Internal tables
+// such as sqlite_sequence are left out.
+func (f *sqliteFile) tables() ([]sqliteTable, error) {
+	var tables []sqliteTable
+	c := f.cursor(1)
+	for {
+		_, payload, err := c.next()
+		if err == io.EOF {
+			return tables, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read SQLite schema: %w", err)
+		}
+		row, err := f.record(payload)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read SQLite schema: %w", err)
+		}
+		// The schema table has the columns type, name, tbl_name, rootpage and sql.
+		if len(row) < 5 || row[0] != "table" {
+			continue
+		}
+		name, _ := row[1].(string)
+		root, _ := row[3].(int64)
+		sql, _ := row[4].(string)
+		if strings.HasPrefix(name, "sqlite_") {
+			continue
+		}
+		if root <= 0 {
+			continue // Virtual tables have no b-tree.
+		}
+		t := sqliteTable{name: name, root: uint32(root)}
+		if t.columns, t.rowidColumn, err = parseCreateTable(sql); err != nil {
+			return nil, fmt.Errorf("table '%s': %w", name, err)
+		}
+		tables = append(tables, t)
+	}
+}
+
+// isSQLQuote reports whether ch opens a quoted identifier or string.
+func isSQLQuote(ch byte) bool {
+	return ch == '"' || ch == '\'' || ch == '`' || ch == '['
+}
+
+// sqlQuoteEnd returns the index of the quote closing the one at sql[start], or len(sql) if it is
+// unterminated. Quotes other than brackets are escaped by doubling them.
+func sqlQuoteEnd(sql string, start int) int {
+	end := sql[start]
+	if end == '[' {
+		end = ']'
+	}
+	j := start + 1
+	for j < len(sql) {
+		if sql[j] == end {
+			if j+1 < len(sql) && sql[j+1] == end && end != ']' {
+				j += 2 // Doubled quote
+				continue
+			}
+			break
+		}
+		j++
+	}
+	return j
+}
+
+// sqlTokens splits SQL into tokens: quoted identifiers and strings, parenthesized groups, commas
+// and words.
+func sqlTokens(sql string) []string {
+	var tokens []string
+	for i := 0; i < len(sql); {
+		switch ch := sql[i]; {
+		case ch == ' ' || ch == '\t' || ch == '\n' || ch == '\r':
+			i++
+		case ch == ',':
+			tokens = append(tokens, ",")
+			i++
+		case isSQLQuote(ch):
+			j := sqlQuoteEnd(sql, i)
+			tokens = append(tokens, sql[i:min(j+1, len(sql))])
+			i = j + 1
+		case ch == '(':
+			// Parentheses inside quotes, as in DEFAULT ')', do not count.
+			depth, j := 0, i
+			for ; j < len(sql); j++ {
+				if isSQLQuote(sql[j]) {
+					j = sqlQuoteEnd(sql, j)
+				} else if sql[j] == '(' {
+					depth++
+				} else if sql[j] == ')' {
+					if depth--; depth == 0 {
+						break
+					}
+				}
+			}
+			tokens = append(tokens, sql[i:min(j+1, len(sql))])
+			i = j + 1
+		default:
+			j := i
+			for j < len(sql) && !strings.ContainsRune(" \t\n\r,()\"'`[", rune(sql[j])) {
+				j++
+			}
+			if j == i {
+				j++ // A stray closing parenthesis
+			}
+			tokens = append(tokens, sql[i:j])
+			i = j
+		}
+	}
+	return tokens
+}
+
+// sqlUnquote returns an identifier without its quotes.
+func sqlUnquote(s string) string {
+	if len(s) >= 2 {
+		switch q := s[0]; q {
+		case '"', '\'', '`':
+			if s[len(s)-1] == q {
+				return strings.ReplaceAll(s[1:len(s)-1], string([]byte{q, q}), string(q))
+			}
+		case '[':
+			if s[len(s)-1] == ']' {
+				return s[1 : len(s)-1]
+			}
+		}
+	}
+	return s
+}
+
+// sqlColumnConstraints are the keywords that end the type name of a column definition.
+var sqlColumnConstraints = map[string]bool{
+	"CONSTRAINT": true, "PRIMARY": true, "NOT": true, "NULL": true, "UNIQUE": true, "CHECK": true,
+	"DEFAULT": true, "COLLATE": true, "REFERENCES": true, "GENERATED": true, "AS": true,
+}
+
+// parseCreateTable returns the column names of a CREATE TABLE statement and the index of its
+// rowid alias column (INTEGER PRIMARY KEY), or -1.
+func parseCreateTable(sql string) ([]string, int, error) {
+	tokens := sqlTokens(sql)
+	body := -1
+	for i, tok := range tokens {
+		if strings.HasPrefix(tok, "(") {
+			body = i
+			break
+		}
+	}
+	if body < 0 {
+		return nil, -1, fmt.Errorf("cannot parse table definition %q", sql)
+	}
+	for _, tok := range tokens[body+1:] {
+		if strings.EqualFold(tok, "ROWID") {
+			return nil, -1, errors.New("WITHOUT ROWID tables are not supported")
+		}
+	}
+	def := tokens[body]
+	if len(def) < 2 || def[len(def)-1] != ')' {
+		return nil, -1, fmt.Errorf("table definition %q has no closing parenthesis", sql)
+	}
+	def = def[1 : len(def)-1]
+
+	var columns []string
+	var types []string
+	rowid, pkColumn := -1, ""
+	var parts [][]string
+	part := []string{}
+	for _, tok := range append(sqlTokens(def), ",") {
+		if tok == "," {
+			parts = append(parts, part)
+			part = []string{}
+			continue
+		}
+		part = append(part, tok)
+	}
+	for _, part := range parts {
+		if len(part) == 0 {
+			continue
+		}
+		switch strings.ToUpper(part[0]) {
+		case "CONSTRAINT", "PRIMARY", "UNIQUE", "CHECK", "FOREIGN":
+			// A table constraint. PRIMARY KEY(col) makes an INTEGER column the rowid alias.
+			for i := 0; i+2 < len(part); i++ {
+				if strings.EqualFold(part[i], "PRIMARY") && strings.EqualFold(part[i+1], "KEY") {
+					if cols := sqlTokens(strings.Trim(part[i+2], "()")); len(cols) == 1 {
+						pkColumn = sqlUnquote(cols[0])
+					}
+				}
+			}
+			continue
+		}
+		columns = append(columns, sqlUnquote(part[0]))
+		var typ []string
+		i := 1
+		for ; i < len(part) && !sqlColumnConstraints[strings.ToUpper(part[i])]; i++ {
+			typ = append(typ, strings.ToUpper(part[i]))
+		}
+		types = append(types, strings.Join(typ, " "))
+		for ; i+1 < len(part); i++ {
+			if strings.EqualFold(part[i], "PRIMARY") && strings.EqualFold(part[i+1], "KEY") {
+				desc := i+2 < len(part) && strings.EqualFold(part[i+2], "DESC")
+				if types[len(types)-1] == "INTEGER" && !desc {
+					rowid = len(columns) - 1
+				}
+			}
+		}
+	}
+	if pkColumn != "" {
+		for i, col := range columns {
+			if strings.EqualFold(col, pkColumn) && types[i] == "INTEGER" {
+				rowid = i
+			}
+		}
+	}
+	if len(columns) == 0 {
+		return nil, -1, fmt.Errorf("no columns in table definition %q", sql)
+	}
+	return columns, rowid, nil
+}
+
+// sqliteSource is a RowSource over a table of an SQLite database.
+type sqliteSource struct {
+	file   *os.File
+	db     *sqliteFile
+	table  sqliteTable
+	cursor *sqliteCursor
+}
+
+// openSQLiteSource opens a table of an SQLite database file. Without a table name, the database
+// must have a single table.
+func openSQLiteSource(path, table string) (*sqliteSource, error) {
+	// Committed transactions may still be in the write-ahead log, which is not read.
+	if fi, err := os.Stat(path + "-wal"); err == nil && fi.Size() > 0 {
+		return nil, fmt.Errorf("'%s' has a write-ahead log; checkpoint it first, e.g. with sqlite3 '%s' 'PRAGMA wal_checkpoint(TRUNCATE)'", path, path)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	db, err := openSQLite(f, fi.Size())
+	if err == nil {
+		var tables []sqliteTable
+		if tables, err = db.tables(); err == nil {
+			var names []string
+			for _, t := range tables {
+				names = append(names, t.name)
+				if strings.EqualFold(t.name, table) || (table == "" && len(tables) == 1) {
+					return &sqliteSource{file: f, db: db, table: t, cursor: db.cursor(t.root)}, nil
+				}
+			}
+			switch {
+			case len(tables) == 0:
+				err = errors.New("database has no tables")
+			case table == "":
+				err = fmt.Errorf("database has several tables (%s); choose one in the mapping", strings.Join(names, ", "))
+			default:
+				err = fmt.Errorf("table '%s' not found (tables: %s)", table, strings.Join(names, ", "))
+			}
+		}
+	}
+	f.Close()
+	return nil, fmt.Errorf("failed to read SQLite database '%s': %w", path, err)
+}
+
+// Columns returns the columns of the table.
+func (s *sqliteSource) Columns() []string {
+	return s.table.columns
+}
+
+// Next returns the next row of the table in rowid order.
+func (s *sqliteSource) Next() (map[string]string, error) {
+	rowid, payload, err := s.cursor.next()
+	if err != nil {
+		return nil, err
+	}
+	values, err := s.db.record(payload)
+	if err != nil {
+		return nil, fmt.Errorf("row %d of table '%s': %w", rowid, s.table.name, err)
+	}
+	row := make(map[string]string, len(s.table.columns))
+	for i, col := range s.table.columns {
+		// Columns added by ALTER TABLE are missing from older records.
+		if i < len(values) {
+			row[col] = sqliteValueString(values[i])
+		}
+	}
+	if i := s.table.rowidColumn; i >= 0 {
+		row[s.table.columns[i]] = strconv.FormatInt(rowid, 10)
+	}
+	return row, nil
+}
+
+// Close closes the database file.
+func (s *sqliteSource) Close() error {
+	return s.file.Close()
+}
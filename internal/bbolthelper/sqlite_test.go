@@ -0,0 +1,163 @@
+package bbolthelper
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// testdata/ecdict.db is a small copy of ECDICT's stardict.db, written by SQLite with 512-byte
+// pages so that the table has interior pages. The row for "word07" has a detail long enough to
+// spill onto overflow pages, and the "note" column was added by ALTER TABLE after the other rows,
+// so only the last row, "zebra", stores it.
+const testSQLitePath = "testdata/ecdict.db"
+
+func TestParseCreateTable(t *testing.T) {
+	tests := []struct {
+		sql     string
+		columns []string
+		rowid   int
+	}{
+		{
+			`CREATE TABLE "stardict" ("id" INTEGER PRIMARY KEY AUTOINCREMENT, "word" VARCHAR(64) COLLATE NOCASE NOT NULL UNIQUE, "sw" VARCHAR(64))`,
+			[]string{"id", "word", "sw"}, 0,
+		},
+		{
+			"CREATE TABLE glossary (term TEXT NOT NULL, [gloss text] TEXT DEFAULT('a, b'), `id` integer, PRIMARY KEY (id))",
+			[]string{"term", "gloss text", "id"}, 2,
+		},
+		{
+			`CREATE TABLE t (id INTEGER PRIMARY KEY DESC, word TEXT, CHECK (length(word) > 0))`,
+			[]string{"id", "word"}, -1,
+		},
+		{
+			`CREATE TABLE t (id INT PRIMARY KEY, word)`,
+			[]string{"id", "word"}, -1,
+		},
+		{
+			`CREATE TABLE t (a TEXT DEFAULT ')', b TEXT, c INTEGER)`,
+			[]string{"a", "b", "c"}, -1,
+		},
+	}
+	for _, tc := range tests {
+		columns, rowid, err := parseCreateTable(tc.sql)
+		if err != nil {
+			t.Errorf("parseCreateTable(%q) failed: %v", tc.sql, err)
+			continue
+		}
+		if !reflect.DeepEqual(columns, tc.columns) || rowid != tc.rowid {
+			t.Errorf("parseCreateTable(%q) = %q, %d; want %q, %d", tc.sql, columns, rowid, tc.columns, tc.rowid)
+		}
+	}
+
+	if _, _, err := parseCreateTable(`CREATE TABLE t (word TEXT PRIMARY KEY, gloss TEXT) WITHOUT ROWID`); err == nil {
+		t.Error("parseCreateTable() accepted a WITHOUT ROWID table")
+	}
+	for _, sql := range []string{`CREATE TABLE t(`, `CREATE TABLE t (a TEXT DEFAULT '(', b TEXT`} {
+		if _, _, err := parseCreateTable(sql); err == nil {
+			t.Errorf("parseCreateTable(%q) accepted an unterminated column list", sql)
+		}
+	}
+}
+
+func TestSQLiteSource(t *testing.T) {
+	src, err := openSQLiteSource(testSQLitePath, "")
+	if err != nil {
+		t.Fatalf("openSQLiteSource() failed: %v", err)
+	}
+	defer src.Close()
+
+	wantColumns := []string{"id", "word", "sw", "phonetic", "definition", "translation", "pos", "collins",
+		"oxford", "tag", "bnc", "frq", "exchange", "detail", "audio", "note"}
+	if got := src.Columns(); !reflect.DeepEqual(got, wantColumns) {
+		t.Errorf("Columns() = %q, want %q", got, wantColumns)
+	}
+
+	var rows []map[string]string
+	for {
+		row, err := src.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Next() failed: %v", err)
+		}
+		rows = append(rows, row)
+	}
+	if len(rows) != 33 {
+		t.Fatalf("read %d rows, want 33", len(rows))
+	}
+	first := rows[0]
+	if first["id"] != "1" || first["word"] != "go" || first["translation"] != "v. 去, 走\nn. 尝试" || first["frq"] != "77" {
+		t.Errorf("first row = %v", first)
+	}
+	if got := rows[9]["detail"]; got != strings.Repeat("x", 3000) {
+		t.Errorf("overflowing detail has %d bytes, want 3000", len(got))
+	}
+	last := rows[len(rows)-1]
+	if last["id"] != "33" || last["word"] != "zebra" || last["note"] != "added later" {
+		t.Errorf("last row = %v", last)
+	}
+	if _, ok := first["note"]; ok {
+		t.Errorf("first row has a note, which was added after it: %v", first)
+	}
+}
+
+func TestOpenSQLiteSource_Errors(t *testing.T) {
+	if _, err := openSQLiteSource(testSQLitePath, "missing"); err == nil || !strings.Contains(err.Error(), "stardict") {
+		t.Errorf("openSQLiteSource() with a missing table = %v, want an error listing the tables", err)
+	}
+
+	dir := t.TempDir()
+	notSQLite := filepath.Join(dir, "test.csv")
+	writeTestCSV(t, dir, testCSVRows)
+	if _, err := openSQLiteSource(notSQLite, ""); err == nil {
+		t.Error("openSQLiteSource() accepted a CSV file")
+	}
+
+	data, err := os.ReadFile(testSQLitePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "ecdict.db")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path+"-wal", []byte("pending"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := openSQLiteSource(path, ""); err == nil || !strings.Contains(err.Error(), "write-ahead log") {
+		t.Errorf("openSQLiteSource() with a write-ahead log = %v, want an error", err)
+	}
+}
+
+func TestDBStore_ImportTable_SQLite(t *testing.T) {
+	store := newTestStore(t, Config{})
+	n, err := store.ImportTable(t.Context(), testSQLitePath, "sqlite", BuiltinMappings["ecdict"], ImportOptions{})
+	if err != nil {
+		t.Fatalf("ImportTable() failed: %v", err)
+	}
+	if n != 33 {
+		t.Errorf("ImportTable() stored %d records, want 33", n)
+	}
+
+	entry, found, err := store.GetEntry("go")
+	if err != nil || !found {
+		t.Fatalf("GetEntry(go) = found %v, %v", found, err)
+	}
+	if entry.Translation != "v. 去, 走\nn. 尝试" || entry.Collins != 5 || !entry.Oxford || entry.Frq != 77 {
+		t.Errorf("GetEntry(go) = %+v", entry)
+	}
+	if entry.Extra["id"] != "" || entry.Extra["sw"] != "" {
+		t.Errorf("GetEntry(go) kept dropped columns: %v", entry.Extra)
+	}
+	if _, found, _ := store.GetEntry("happy"); !found {
+		t.Error("GetEntry(happy) found nothing; the headword should be lowercased")
+	}
+	if entry, _, _ := store.GetEntry("zebra"); entry == nil || entry.Extra["note"] != "added later" {
+		t.Errorf("GetEntry(zebra) = %+v, want the note column in Extra", entry)
+	}
+}
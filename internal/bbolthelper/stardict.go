@@ -0,0 +1,385 @@
+package bbolthelper
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"html"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// starDictMagic is the first line of every StarDict .ifo file.
+const starDictMagic = "StarDict's dict ifo file"
+
+// starDictInfo holds the .ifo settings used by the importer.
+type starDictInfo struct {
+	BookName  string
+	WordCount int
+	// OffsetBits is the size of the .idx data offsets, 32 or 64.
+	OffsetBits int
+	// SameTypeSequence lists the field types of every entry when they all share one layout; the
+	// type markers are then omitted from the .dict data.
+	SameTypeSequence string
+	Website          string
+}
+
+// readStarDictInfo parses a StarDict .ifo file.
+func readStarDictInfo(path string) (*starDictInfo, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open StarDict info file '%s': %w", path, err)
+	}
+	defer f.Close()
+
+	sc := bufio.NewScanner(f)
+	if !sc.Scan() || strings.TrimPrefix(strings.TrimSpace(sc.Text()), "\ufeff") != starDictMagic {
+		return nil, fmt.Errorf("'%s' is not a StarDict info file", path)
+	}
+	info := &starDictInfo{OffsetBits: 32}
+	for sc.Scan() {
+		key, value, ok := strings.Cut(strings.TrimSpace(sc.Text()), "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "version":
+			if value != "2.4.2" && value != "3.0.0" {
+				return nil, fmt.Errorf("unsupported StarDict version '%s' in '%s'", value, path)
+			}
+		case "bookname":
+			info.BookName = value
+		case "wordcount":
+			info.WordCount, _ = strconv.Atoi(value)
+		case "idxoffsetbits":
+			if info.OffsetBits, err = strconv.Atoi(value); err != nil || (info.OffsetBits != 32 && info.OffsetBits != 64) {
+				return nil, fmt.Errorf("invalid idxoffsetbits '%s' in '%s'", value, path)
+			}
+		case "sametypesequence":
+			info.SameTypeSequence = value
+		case "website":
+			info.Website = value
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read StarDict info file '%s': %w", path, err)
+	}
+	return info, nil
+}
+
+// StarDictBookName returns the bookname recorded in a StarDict .ifo file.
+func StarDictBookName(ifoPath string) (string, error) {
+	info, err := readStarDictInfo(ifoPath)
+	if err != nil {
+		return "", err
+	}
+	return info.BookName, nil
+}
+
+// starDictIdxEntry locates the data of one headword in the .dict file.
+type starDictIdxEntry struct {
+	word   string
+	offset int64
+	size   int64
+}
+
+// readStarDictIdx parses a .idx file: zero-terminated headwords, each followed by the big-endian
+// offset and size of its data.
+func readStarDictIdx(data []byte, offsetBits int) ([]starDictIdxEntry, error) {
+	offsetLen := offsetBits / 8
+	var entries []starDictIdxEntry
+	for len(data) > 0 {
+		end := bytes.IndexByte(data, 0)
+		if end < 0 || len(data) < end+1+offsetLen+4 {
+			return nil, fmt.Errorf("truncated index entry %d", len(entries))
+		}
+		e := starDictIdxEntry{word: string(data[:end])}
+		data = data[end+1:]
+		if offsetLen == 8 {
+			e.offset = int64(binary.BigEndian.Uint64(data))
+		} else {
+			e.offset = int64(binary.BigEndian.Uint32(data))
+		}
+		e.size = int64(binary.BigEndian.Uint32(data[offsetLen:]))
+		data = data[offsetLen+4:]
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+// readStarDictSyn parses a .syn file: zero-terminated synonyms, each followed by the big-endian
+// number of the .idx entry it stands for. It returns the synonyms of each entry.
+func readStarDictSyn(data []byte, idxCount int) (map[int][]string, error) {
+	syns := make(map[int][]string)
+	for n := 0; len(data) > 0; n++ {
+		end := bytes.IndexByte(data, 0)
+		if end < 0 || len(data) < end+5 {
+			return nil, fmt.Errorf("truncated synonym entry %d", n)
+		}
+		word := string(data[:end])
+		target := int(binary.BigEndian.Uint32(data[end+1:]))
+		data = data[end+5:]
+		if target >= idxCount {
+			return nil, fmt.Errorf("synonym '%s' refers to index entry %d of %d", word, target, idxCount)
+		}
+		syns[target] = append(syns[target], word)
+	}
+	return syns, nil
+}
+
+// starDictField is one typed field of a StarDict entry.
+type starDictField struct {
+	kind byte
+	data []byte
+}
+
+// parseStarDictData splits the data of an entry into its fields. Lower-case field types are text,
+// terminated by a zero byte; upper-case types are binary, prefixed by their big-endian size. With
+// a sametypesequence the type markers are omitted and the last field runs to the end of the data.
+func parseStarDictData(data []byte, sameTypes string) ([]starDictField, error) {
+	var fields []starDictField
+	read := func(kind byte, last bool) error {
+		f := starDictField{kind: kind}
+		switch {
+		case last:
+			f.data, data = data, nil
+		case kind >= 'a' && kind <= 'z':
+			if end := bytes.IndexByte(data, 0); end >= 0 {
+				f.data, data = data[:end], data[end+1:]
+			} else {
+				f.data, data = data, nil // The final field may omit its terminator
+			}
+		default:
+			if len(data) < 4 || int64(len(data)-4) < int64(binary.BigEndian.Uint32(data)) {
+				return fmt.Errorf("truncated '%c' field", kind)
+			}
+			size := binary.BigEndian.Uint32(data)
+			f.data, data = data[4:4+size], data[4+size:]
+		}
+		fields = append(fields, f)
+		return nil
+	}
+
+	if sameTypes != "" {
+		for i := 0; i < len(sameTypes); i++ {
+			if err := read(sameTypes[i], i == len(sameTypes)-1); err != nil {
+				return nil, err
+			}
+		}
+		return fields, nil
+	}
+	for len(data) > 0 {
+		kind := data[0]
+		data = data[1:]
+		if err := read(kind, false); err != nil {
+			return nil, err
+		}
+	}
+	return fields, nil
+}
+
+var (
+	// markupBreak matches the tags that end a line of HTML, Pango or XDXF markup.
+	markupBreak = regexp.MustCompile(`(?i)<br\s*/?>|</(p|div|li|tr|h[1-6]|def|ex)>`)
+	markupTag   = regexp.MustCompile(`<[^>]*>`)
+)
+
+// markupText reduces markup to plain text lines.
+func markupText(s string) string {
+	s = markupBreak.ReplaceAllString(s, "\n")
+	s = html.UnescapeString(markupTag.ReplaceAllString(s, ""))
+	return cleanLines(s)
+}
+
+// cleanLines trims every line of s and drops empty ones.
+func cleanLines(s string) string {
+	var lines []string
+	for _, line := range strings.Split(s, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// starDictEntry converts the fields of an entry into the fields ne renders: the phonetic
+// transcription ('t') becomes phonetic, and every text meaning becomes a line of translation.
+// Markup (Pango, HTML, XDXF, PowerWord) is reduced to plain text; binary and resource fields are
+// dropped.
+func starDictEntry(word string, fields []starDictField) *Entry {
+	e := &Entry{Word: word}
+	var meanings []string
+	for _, f := range fields {
+		text := string(f.data)
+		switch f.kind {
+		case 't':
+			if e.Phonetic == "" {
+				e.Phonetic = strings.TrimSpace(text)
+			}
+		case 'm', 'l', 'y', 'w', 'n':
+			meanings = append(meanings, cleanLines(text))
+		case 'g', 'h', 'x', 'k':
+			meanings = append(meanings, markupText(text))
+		}
+	}
+	e.Translation = cleanLines(strings.Join(meanings, "\n"))
+	return e
+}
+
+// starDictFile returns the first of the paths with the given extensions that exists.
+func starDictFile(base string, exts ...string) (string, bool) {
+	for _, ext := range exts {
+		if _, err := os.Stat(base + ext); err == nil {
+			return base + ext, true
+		}
+	}
+	return "", false
+}
+
+// ImportStarDict imports a StarDict dictionary given the path of its .ifo file. The .idx (or
+// .idx.gz), .dict (or dictzip-compressed .dict.dz) and optional .syn files must sit next to it.
+// Headwords are stored in lower case; entries whose headwords differ only in case are merged.
+// Every synonym listed in the .syn file that is not a headword itself is stored as a copy of its
+// entry. The book name and website of the .ifo are the default display name and source of the
+// dictionary. It returns the number of records stored.
+func (s *DBStore) ImportStarDict(ctx context.Context, ifoPath string, opts ImportOptions) (int, error) {
+	info, err := readStarDictInfo(ifoPath)
+	if err != nil {
+		return 0, err
+	}
+	base := strings.TrimSuffix(ifoPath, filepath.Ext(ifoPath))
+	idxPath, ok := starDictFile(base, ".idx", ".idx.gz")
+	if !ok {
+		return 0, fmt.Errorf("StarDict index file '%s.idx' not found", base)
+	}
+	dictPath, ok := starDictFile(base, ".dict", ".dict.dz")
+	if !ok {
+		return 0, fmt.Errorf("StarDict data file '%s.dict' not found", base)
+	}
+	s.logger.Info("Starting StarDict import...", zap.String("ifo", ifoPath), zap.String("bookName", info.BookName),
+		zap.String("idx", idxPath), zap.String("dict", dictPath))
+
+	idxData, err := readCompressible(idxPath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read StarDict index '%s': %w", idxPath, err)
+	}
+	idx, err := readStarDictIdx(idxData, info.OffsetBits)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse StarDict index '%s': %w", idxPath, err)
+	}
+	if info.WordCount != 0 && info.WordCount != len(idx) {
+		s.logger.Warn("StarDict index does not match the word count of the info file",
+			zap.Int("wordcount", info.WordCount), zap.Int("entries", len(idx)))
+	}
+
+	// Headwords that differ only in case are merged into one record. The .idx sorts them with
+	// g_ascii_strcasecmp, which folds ASCII letters only, so variants such as "É" and "é" need not
+	// be adjacent and the entries are grouped by lowercased headword first.
+	groups := make(map[string][]int, len(idx))
+	var keys []string
+	for j, e := range idx {
+		key := strings.ToLower(e.word)
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], j)
+	}
+	synonyms := map[int][]string{}
+	if synPath, ok := starDictFile(base, ".syn"); ok {
+		synData, err := os.ReadFile(synPath)
+		if err != nil {
+			return 0, fmt.Errorf("failed to read StarDict synonyms '%s': %w", synPath, err)
+		}
+		if synonyms, err = readStarDictSyn(synData, len(idx)); err != nil {
+			return 0, fmt.Errorf("failed to parse StarDict synonyms '%s': %w", synPath, err)
+		}
+	}
+
+	dict, closer, err := openCompressible(dictPath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open StarDict data '%s': %w", dictPath, err)
+	}
+	defer closer.Close()
+
+	// Synonyms are queued after their entry.
+	type pendingRecord struct {
+		key    string
+		fields map[string]string
+	}
+	var pending []pendingRecord
+	seenSynonyms := make(map[string]bool)
+	next := func() (string, map[string]string, error) {
+		for len(pending) == 0 {
+			if len(keys) == 0 {
+				return "", nil, io.EOF
+			}
+			key := keys[0]
+			keys = keys[1:]
+			merged := &Entry{Word: key}
+			var syns []string
+			for _, j := range groups[key] {
+				data := make([]byte, idx[j].size)
+				if _, err := dict.ReadAt(data, idx[j].offset); err != nil {
+					return "", nil, fmt.Errorf("failed to read data of '%s' from '%s': %w", idx[j].word, dictPath, err)
+				}
+				fields, err := parseStarDictData(data, info.SameTypeSequence)
+				if err != nil {
+					s.logger.Warn("Malformed StarDict entry, skipping.", zap.String("word", idx[j].word), zap.Error(err))
+					continue
+				}
+				e := starDictEntry(key, fields)
+				if merged.Phonetic == "" {
+					merged.Phonetic = e.Phonetic
+				}
+				merged.Translation = cleanLines(merged.Translation + "\n" + e.Translation)
+				syns = append(syns, synonyms[j]...)
+			}
+			if merged.Phonetic == "" && merged.Translation == "" {
+				continue
+			}
+			fields := merged.Map()
+			pending = append(pending, pendingRecord{key, fields})
+			for _, syn := range syns {
+				syn = strings.ToLower(syn)
+				if groups[syn] != nil || seenSynonyms[syn] {
+					continue
+				}
+				seenSynonyms[syn] = true
+				pending = append(pending, pendingRecord{syn, fields})
+			}
+		}
+		r := pending[0]
+		pending = pending[1:]
+		return r.key, r.fields, nil
+	}
+
+	if opts.Manifest.DisplayName == "" {
+		opts.Manifest.DisplayName = info.BookName
+	}
+	if opts.Manifest.Source == "" {
+		opts.Manifest.Source = info.Website
+	}
+	sum, err := fileSHA256(dictPath)
+	if err != nil {
+		return 0, err
+	}
+	meta := &Metadata{
+		Format:        "stardict",
+		SourceFile:    filepath.Base(ifoPath),
+		SourceSHA256:  sum,
+		HeaderColumns: []string{"word", FieldPhonetic, FieldTranslation},
+	}
+	records, err := s.importRecords(ctx, next, meta, opts)
+	if err != nil {
+		return records, fmt.Errorf("failed to import StarDict dictionary '%s': %w", ifoPath, err)
+	}
+	return records, nil
+}
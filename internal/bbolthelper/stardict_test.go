@@ -0,0 +1,246 @@
+package bbolthelper
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"encoding/binary"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// writeTestDictzip compresses data into a dictzip file with chunks of chunkLen bytes. Every chunk
+// is deflated independently, so it can be inflated on its own as dictzip requires.
+func writeTestDictzip(t *testing.T, path string, data []byte, chunkLen int) {
+	t.Helper()
+	var body bytes.Buffer
+	var sizes []byte
+	for start := 0; start < len(data); start += chunkLen {
+		before := body.Len()
+		fw, err := flate.NewWriter(&body, flate.BestCompression)
+		if err != nil {
+			t.Fatalf("flate.NewWriter() failed: %v", err)
+		}
+		fw.Write(data[start:min(start+chunkLen, len(data))])
+		if start+chunkLen >= len(data) {
+			fw.Close()
+		} else {
+			fw.Flush()
+		}
+		sizes = binary.LittleEndian.AppendUint16(sizes, uint16(body.Len()-before))
+	}
+
+	ra := binary.LittleEndian.AppendUint16(nil, 1) // version
+	ra = binary.LittleEndian.AppendUint16(ra, uint16(chunkLen))
+	ra = binary.LittleEndian.AppendUint16(ra, uint16(len(sizes)/2))
+	ra = append(ra, sizes...)
+	extra := append([]byte("RA"), binary.LittleEndian.AppendUint16(nil, uint16(len(ra)))...)
+	extra = append(extra, ra...)
+
+	out := []byte{0x1f, 0x8b, 8, gzipFlagExtra | gzipFlagName, 0, 0, 0, 0, 2, 3}
+	out = binary.LittleEndian.AppendUint16(out, uint16(len(extra)))
+	out = append(out, extra...)
+	out = append(out, "test.dict\x00"...)
+	out = append(out, body.Bytes()...)
+	out = binary.LittleEndian.AppendUint32(out, crc32.ChecksumIEEE(data))
+	out = binary.LittleEndian.AppendUint32(out, uint32(len(data)))
+	if err := os.WriteFile(path, out, 0644); err != nil {
+		t.Fatalf("Failed to write dictzip file: %v", err)
+	}
+}
+
+// testStarDictEntry is one headword of a StarDict test fixture, with its 't' and 'm' fields.
+type testStarDictEntry struct {
+	word, phonetic, meaning string
+}
+
+// writeTestStarDict writes a StarDict dictionary with sametypesequence=tm, a dictzip-compressed
+// .dict.dz and a .syn file mapping each synonym to an entry number, and returns the .ifo path.
+func writeTestStarDict(t *testing.T, dir string, entries []testStarDictEntry, synonyms map[string]uint32) string {
+	t.Helper()
+	base := filepath.Join(dir, "test")
+	var dict, idx, syn []byte
+	for _, e := range entries {
+		data := append([]byte(e.phonetic), 0)
+		data = append(data, e.meaning...)
+		idx = append(idx, e.word+"\x00"...)
+		idx = binary.BigEndian.AppendUint32(idx, uint32(len(dict)))
+		idx = binary.BigEndian.AppendUint32(idx, uint32(len(data)))
+		dict = append(dict, data...)
+	}
+	for word, target := range synonyms {
+		syn = append(syn, word+"\x00"...)
+		syn = binary.BigEndian.AppendUint32(syn, target)
+	}
+	ifo := strings.Join([]string{
+		starDictMagic,
+		"version=2.4.2",
+		"bookname=Test Dictionary",
+		"wordcount=" + strconv.Itoa(len(entries)),
+		"idxfilesize=" + strconv.Itoa(len(idx)),
+		"sametypesequence=tm",
+		"website=https://example.org/dict",
+	}, "\n") + "\n"
+
+	writeTestDictzip(t, base+".dict.dz", dict, 16)
+	for path, content := range map[string][]byte{base + ".ifo": []byte(ifo), base + ".idx": idx, base + ".syn": syn} {
+		if err := os.WriteFile(path, content, 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", path, err)
+		}
+	}
+	return base + ".ifo"
+}
+
+func TestDictzipReader_ReadAt(t *testing.T) {
+	data := []byte(strings.Repeat("the quick brown fox jumps over the lazy dog. ", 20))
+	path := filepath.Join(t.TempDir(), "test.dict.dz")
+	writeTestDictzip(t, path, data, 64)
+
+	r, closer, err := openCompressible(path)
+	if err != nil {
+		t.Fatalf("openCompressible() failed: %v", err)
+	}
+	defer closer.Close()
+	if _, ok := r.(*dictzipReader); !ok {
+		t.Fatalf("openCompressible() returned %T, want a dictzip reader", r)
+	}
+	for _, tc := range []struct{ off, n int }{{0, 10}, {60, 10}, {100, 300}, {len(data) - 5, 5}} {
+		got := make([]byte, tc.n)
+		if _, err := r.ReadAt(got, int64(tc.off)); err != nil {
+			t.Errorf("ReadAt(%d bytes at %d) failed: %v", tc.n, tc.off, err)
+			continue
+		}
+		if want := data[tc.off : tc.off+tc.n]; !bytes.Equal(got, want) {
+			t.Errorf("ReadAt(%d bytes at %d) = %q, want %q", tc.n, tc.off, got, want)
+		}
+	}
+
+	// A dictzip file is also a plain gzip file.
+	f, _ := os.Open(path)
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatalf("gzip.NewReader() failed: %v", err)
+	}
+	if got, err := io.ReadAll(zr); err != nil || !bytes.Equal(got, data) {
+		t.Errorf("gzip read = %d bytes, %v; want the original data", len(got), err)
+	}
+}
+
+func TestParseStarDictData(t *testing.T) {
+	var data []byte
+	data = append(data, "mplain meaning\x00"...)
+	data = append(data, 'W')
+	data = binary.BigEndian.AppendUint32(data, 3)
+	data = append(data, 1, 0, 2)
+	data = append(data, "tˈtest\x00"...)
+	data = append(data, "h<b>bold</b> &amp; more<br>second line"...)
+
+	fields, err := parseStarDictData(data, "")
+	if err != nil {
+		t.Fatalf("parseStarDictData() failed: %v", err)
+	}
+	var kinds string
+	for _, f := range fields {
+		kinds += string(f.kind)
+	}
+	if kinds != "mWth" {
+		t.Fatalf("parseStarDictData() field types = %q, want %q", kinds, "mWth")
+	}
+	if !bytes.Equal(fields[1].data, []byte{1, 0, 2}) {
+		t.Errorf("binary field = %v, want [1 0 2]", fields[1].data)
+	}
+
+	e := starDictEntry("test", fields)
+	if e.Phonetic != "ˈtest" {
+		t.Errorf("Phonetic = %q, want %q", e.Phonetic, "ˈtest")
+	}
+	if want := "plain meaning\nbold & more\nsecond line"; e.Translation != want {
+		t.Errorf("Translation = %q, want %q", e.Translation, want)
+	}
+
+	if _, err := parseStarDictData([]byte{'W', 0, 0, 0, 9, 1}, ""); err == nil {
+		t.Error("parseStarDictData() accepted a truncated binary field")
+	}
+}
+
+func TestDBStore_ImportStarDict(t *testing.T) {
+	dir := t.TempDir()
+	ifo := writeTestStarDict(t, dir, []testStarDictEntry{
+		{"Apple", "ˈæpl", "n. 苹果公司"},
+		{"apple", "", "n. 苹果"},
+		{"go", "gəʊ", "v. 去\nn. 围棋"},
+		// g_ascii_strcasecmp does not fold "É", so "Über" sorts between the variants of "été".
+		{"Été", "", "n. 夏天（大写）"},
+		{"Über", "", "prep. 在……之上"},
+		{"été", "ete", "n. 夏天"},
+	}, map[string]uint32{"went": 2, "APPLES": 1, "go": 2})
+
+	store := newTestStore(t, Config{})
+	n, err := store.ImportStarDict(t.Context(), ifo, ImportOptions{BatchSize: 2})
+	if err != nil {
+		t.Fatalf("ImportStarDict() failed: %v", err)
+	}
+	if n != 6 {
+		t.Errorf("ImportStarDict() stored %d records, want 6 (apple, apples, go, went, été, über)", n)
+	}
+
+	tests := []struct {
+		word, phonetic, translation string
+	}{
+		{"apple", "ˈæpl", "n. 苹果公司\nn. 苹果"}, // Merged across case
+		{"apples", "ˈæpl", "n. 苹果公司\nn. 苹果"},
+		{"go", "gəʊ", "v. 去\nn. 围棋"},
+		{"went", "gəʊ", "v. 去\nn. 围棋"},     // Synonym of go
+		{"été", "ete", "n. 夏天（大写）\nn. 夏天"}, // Merged although not adjacent
+		{"über", "", "prep. 在……之上"},
+	}
+	for _, tc := range tests {
+		entry, found, err := store.GetEntry(tc.word)
+		if err != nil || !found {
+			t.Errorf("GetEntry(%q) = found %v, %v; want an entry", tc.word, found, err)
+			continue
+		}
+		if entry.Phonetic != tc.phonetic || entry.Translation != tc.translation {
+			t.Errorf("GetEntry(%q) = %q, %q; want %q, %q", tc.word, entry.Phonetic, entry.Translation, tc.phonetic, tc.translation)
+		}
+	}
+
+	meta, found, err := store.Metadata()
+	if err != nil || !found {
+		t.Fatalf("Metadata() = found %v, %v", found, err)
+	}
+	if meta.DisplayName != "Test Dictionary" || meta.Source != "https://example.org/dict" {
+		t.Errorf("Metadata() manifest = %+v, want the book name and website of the .ifo", meta.Manifest)
+	}
+	if meta.SourceFile != "test.ifo" || meta.RecordCount != 6 || meta.SourceSHA256 == "" {
+		t.Errorf("Metadata() = %+v, want source test.ifo with 6 records", meta)
+	}
+	if want := []string{"word", FieldPhonetic, FieldTranslation}; !reflect.DeepEqual(meta.HeaderColumns, want) {
+		t.Errorf("Metadata().HeaderColumns = %v, want %v", meta.HeaderColumns, want)
+	}
+}
+
+func TestReadStarDictIdx_64BitOffsets(t *testing.T) {
+	var idx []byte
+	idx = append(idx, "big\x00"...)
+	idx = binary.BigEndian.AppendUint64(idx, 1<<33)
+	idx = binary.BigEndian.AppendUint32(idx, 7)
+
+	entries, err := readStarDictIdx(idx, 64)
+	if err != nil {
+		t.Fatalf("readStarDictIdx() failed: %v", err)
+	}
+	if want := []starDictIdxEntry{{"big", 1 << 33, 7}}; !reflect.DeepEqual(entries, want) {
+		t.Errorf("readStarDictIdx() = %+v, want %+v", entries, want)
+	}
+	if _, err := readStarDictIdx(idx[:len(idx)-1], 64); err == nil {
+		t.Error("readStarDictIdx() accepted a truncated entry")
+	}
+}
@@ -0,0 +1,209 @@
+package bbolthelper
+
+// porterStem reduces a lowercase English word to its stem with the Porter (1980) algorithm,
+// following the reference implementation (including its "bli" and "logi" rules).
+// Words of fewer than three letters and words with non a-z characters are returned unchanged.
+func porterStem(word string) string {
+	if len(word) < 3 {
+		return word
+	}
+	for i := 0; i < len(word); i++ {
+		if word[i] < 'a' || word[i] > 'z' {
+			return word
+		}
+	}
+	b := []byte(word)
+	b = porterStep1ab(b)
+	b = porterStep1c(b)
+	b = porterReplaceLongest(b, porterStep2, 0)
+	b = porterReplaceLongest(b, porterStep3, 0)
+	b = porterStep4(b)
+	b = porterStep5(b)
+	return string(b)
+}
+
+// porterRule replaces a suffix with a replacement.
+type porterRule struct {
+	suffix, replacement string
+}
+
+var porterStep2 = []porterRule{
+	{"ational", "ate"}, {"tional", "tion"}, {"enci", "ence"}, {"anci", "ance"}, {"izer", "ize"},
+	{"bli", "ble"}, {"alli", "al"}, {"entli", "ent"}, {"eli", "e"}, {"ousli", "ous"},
+	{"ization", "ize"}, {"ation", "ate"}, {"ator", "ate"}, {"alism", "al"}, {"iveness", "ive"},
+	{"fulness", "ful"}, {"ousness", "ous"}, {"aliti", "al"}, {"iviti", "ive"}, {"biliti", "ble"},
+	{"logi", "log"},
+}
+
+var porterStep3 = []porterRule{
+	{"icate", "ic"}, {"ative", ""}, {"alize", "al"}, {"iciti", "ic"}, {"ical", "ic"}, {"ful", ""}, {"ness", ""},
+}
+
+var porterStep4Suffixes = []string{
+	"al", "ance", "ence", "er", "ic", "able", "ible", "ant", "ement", "ment", "ent",
+	"ion", "ou", "ism", "ate", "iti", "ous", "ive", "ize",
+}
+
+// porterCons reports whether b[i] is a consonant: not a vowel, and 'y' only after a vowel.
+func porterCons(b []byte, i int) bool {
+	switch b[i] {
+	case 'a', 'e', 'i', 'o', 'u':
+		return false
+	case 'y':
+		return i == 0 || !porterCons(b, i-1)
+	}
+	return true
+}
+
+// porterMeasure returns m for a stem of the form [C](VC){m}[V].
+func porterMeasure(b []byte) int {
+	m, i := 0, 0
+	for i < len(b) && porterCons(b, i) {
+		i++
+	}
+	for i < len(b) {
+		for i < len(b) && !porterCons(b, i) {
+			i++
+		}
+		if i == len(b) {
+			break
+		}
+		for i < len(b) && porterCons(b, i) {
+			i++
+		}
+		m++
+	}
+	return m
+}
+
+// porterHasVowel reports whether the stem contains a vowel (*v*).
+func porterHasVowel(b []byte) bool {
+	for i := range b {
+		if !porterCons(b, i) {
+			return true
+		}
+	}
+	return false
+}
+
+// porterDoubleCons reports whether the stem ends with a double consonant (*d).
+func porterDoubleCons(b []byte) bool {
+	n := len(b)
+	return n >= 2 && b[n-1] == b[n-2] && porterCons(b, n-1)
+}
+
+// porterCVC reports whether the stem ends consonant-vowel-consonant, the last not w, x or y (*o).
+func porterCVC(b []byte) bool {
+	n := len(b)
+	if n < 3 || !porterCons(b, n-3) || porterCons(b, n-2) || !porterCons(b, n-1) {
+		return false
+	}
+	switch b[n-1] {
+	case 'w', 'x', 'y':
+		return false
+	}
+	return true
+}
+
+// porterHasSuffix reports whether b ends with suffix.
+func porterHasSuffix(b []byte, suffix string) bool {
+	return len(b) >= len(suffix) && string(b[len(b)-len(suffix):]) == suffix
+}
+
+// porterReplaceLongest applies the rule with the longest matching suffix when the remaining
+// stem has a measure greater than minMeasure. Only that rule is considered.
+func porterReplaceLongest(b []byte, rules []porterRule, minMeasure int) []byte {
+	best := -1
+	for i, r := range rules {
+		if porterHasSuffix(b, r.suffix) && (best < 0 || len(r.suffix) > len(rules[best].suffix)) {
+			best = i
+		}
+	}
+	if best < 0 {
+		return b
+	}
+	stem := b[:len(b)-len(rules[best].suffix)]
+	if porterMeasure(stem) <= minMeasure {
+		return b
+	}
+	return append(stem, rules[best].replacement...)
+}
+
+func porterStep1ab(b []byte) []byte {
+	switch {
+	case porterHasSuffix(b, "sses"), porterHasSuffix(b, "ies"):
+		b = b[:len(b)-2]
+	case porterHasSuffix(b, "ss"):
+	case porterHasSuffix(b, "s"):
+		b = b[:len(b)-1]
+	}
+
+	if porterHasSuffix(b, "eed") {
+		if porterMeasure(b[:len(b)-3]) > 0 {
+			b = b[:len(b)-1]
+		}
+		return b
+	}
+	var stem []byte
+	switch {
+	case porterHasSuffix(b, "ed") && porterHasVowel(b[:len(b)-2]):
+		stem = b[:len(b)-2]
+	case porterHasSuffix(b, "ing") && porterHasVowel(b[:len(b)-3]):
+		stem = b[:len(b)-3]
+	default:
+		return b
+	}
+	switch {
+	case porterHasSuffix(stem, "at"), porterHasSuffix(stem, "bl"), porterHasSuffix(stem, "iz"):
+		return append(stem, 'e')
+	case porterDoubleCons(stem):
+		switch stem[len(stem)-1] {
+		case 'l', 's', 'z':
+			return stem
+		}
+		return stem[:len(stem)-1]
+	case porterMeasure(stem) == 1 && porterCVC(stem):
+		return append(stem, 'e')
+	}
+	return stem
+}
+
+func porterStep1c(b []byte) []byte {
+	if porterHasSuffix(b, "y") && porterHasVowel(b[:len(b)-1]) {
+		b[len(b)-1] = 'i'
+	}
+	return b
+}
+
+func porterStep4(b []byte) []byte {
+	best := ""
+	for _, suffix := range porterStep4Suffixes {
+		if porterHasSuffix(b, suffix) && len(suffix) > len(best) {
+			best = suffix
+		}
+	}
+	if best == "" {
+		return b
+	}
+	stem := b[:len(b)-len(best)]
+	if porterMeasure(stem) <= 1 {
+		return b
+	}
+	if best == "ion" && !porterHasSuffix(stem, "s") && !porterHasSuffix(stem, "t") {
+		return b
+	}
+	return stem
+}
+
+func porterStep5(b []byte) []byte {
+	if porterHasSuffix(b, "e") {
+		stem := b[:len(b)-1]
+		if m := porterMeasure(stem); m > 1 || (m == 1 && !porterCVC(stem)) {
+			b = stem
+		}
+	}
+	if porterHasSuffix(b, "ll") && porterMeasure(b) > 1 {
+		b = b[:len(b)-1]
+	}
+	return b
+}
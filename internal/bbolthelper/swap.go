@@ -0,0 +1,148 @@
+package bbolthelper
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	bolt "go.etcd.io/bbolt"
+	"go.uber.org/zap"
+)
+
+const (
+	// buildSuffix is appended to the target path to name the sibling file a database is built in.
+	buildSuffix = ".build"
+	// compactTxMaxSize bounds the size of each transaction while copying into a compacted file.
+	compactTxMaxSize = 64 << 20
+)
+
+// BuildPath returns the sibling path used to build a new version of the database at targetPath.
+// It is stable so that an interrupted build can be resumed.
+func BuildPath(targetPath string) string {
+	return targetPath + buildSuffix
+}
+
+// Verify checks that the store's bucket exists and that its key count matches the record
+// count of its build metadata, when present. An empty bucket is an error.
+func (s *DBStore) Verify() error {
+	meta, hasMeta, err := s.Metadata()
+	if err != nil {
+		return err
+	}
+	return s.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(s.bucketName))
+		if b == nil {
+			return fmt.Errorf("verification failed: bucket '%s' not found in '%s'", s.bucketName, s.dbPath)
+		}
+		n := countKeys(b)
+		if n == 0 {
+			return fmt.Errorf("verification failed: bucket '%s' in '%s' is empty", s.bucketName, s.dbPath)
+		}
+		if hasMeta && meta.RecordCount != n {
+			return fmt.Errorf("verification failed: bucket '%s' holds %d records but its metadata records %d", s.bucketName, n, meta.RecordCount)
+		}
+		return nil
+	})
+}
+
+// PublishTo verifies the store, writes a compacted copy into a temporary file next to targetPath,
+// fsyncs it and atomically renames it over targetPath. Processes that already have the old file
+// open keep reading the old inode until they close it; new readers see only the complete database.
+// The store itself is left open and unchanged.
+func (s *DBStore) PublishTo(targetPath string) error {
+	if err := s.Verify(); err != nil {
+		return err
+	}
+
+	tmpPath, err := createSiblingTemp(targetPath, s.dbFileMode)
+	if err != nil {
+		return err
+	}
+	published := false
+	defer func() {
+		if !published {
+			os.Remove(tmpPath)
+		}
+	}()
+
+	s.logger.Info("Writing compacted database for publishing", zap.String("from", s.dbPath), zap.String("tempDB", tmpPath))
+	if err := s.compactInto(tmpPath); err != nil {
+		return err
+	}
+	if err := replaceFile(tmpPath, targetPath); err != nil {
+		return err
+	}
+	published = true
+	s.logger.Info("Database published", zap.String("dbPath", targetPath))
+	return nil
+}
+
+// createSiblingTemp creates an empty temporary file with the given mode in the directory of
+// targetPath, so that it can later be renamed over targetPath atomically.
+func createSiblingTemp(targetPath string, mode os.FileMode) (string, error) {
+	tmpFile, err := os.CreateTemp(filepath.Dir(targetPath), "."+filepath.Base(targetPath)+".*.tmp")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temporary file next to '%s': %w", targetPath, err)
+	}
+	tmpPath := tmpFile.Name()
+	tmpFile.Close()
+	// CreateTemp uses mode 0600; the file must end up readable like any other database.
+	if err := os.Chmod(tmpPath, mode); err != nil {
+		os.Remove(tmpPath)
+		return "", fmt.Errorf("failed to set mode of '%s': %w", tmpPath, err)
+	}
+	return tmpPath, nil
+}
+
+// compactInto copies the store's contents into a new database at dstPath using bolt.Compact.
+// The file is fsynced before returning.
+func (s *DBStore) compactInto(dstPath string) error {
+	dst, err := bolt.Open(dstPath, s.dbFileMode, nil)
+	if err != nil {
+		return fmt.Errorf("failed to open compaction target '%s': %w", dstPath, err)
+	}
+	if err := bolt.Compact(dst, s.db, compactTxMaxSize); err != nil {
+		dst.Close()
+		return fmt.Errorf("failed to compact '%s' into '%s': %w", s.dbPath, dstPath, err)
+	}
+	if err := dst.Sync(); err != nil {
+		dst.Close()
+		return fmt.Errorf("failed to sync '%s': %w", dstPath, err)
+	}
+	if err := dst.Close(); err != nil {
+		return fmt.Errorf("failed to close compaction target '%s': %w", dstPath, err)
+	}
+	return nil
+}
+
+// replaceFile fsyncs srcPath, renames it over dstPath and fsyncs the directory so the rename is durable.
+// The rename is atomic: dstPath refers either to the old file or to the new one, never to nothing.
+func replaceFile(srcPath, dstPath string) error {
+	if err := syncPath(srcPath); err != nil {
+		return err
+	}
+	if err := os.Rename(srcPath, dstPath); err != nil {
+		return fmt.Errorf("failed to rename '%s' to '%s': %w", srcPath, dstPath, err)
+	}
+	// Directories cannot be opened for syncing on Windows; the rename is still atomic there.
+	if runtime.GOOS != "windows" {
+		if err := syncPath(filepath.Dir(dstPath)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// syncPath opens a file or directory and fsyncs it.
+func syncPath(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open '%s' for sync: %w", path, err)
+	}
+	defer f.Close()
+	if err := f.Sync(); err != nil {
+		return fmt.Errorf("failed to sync '%s': %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package bbolthelper
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestDBStore_Verify(t *testing.T) {
+	store := newTestStore(t, Config{})
+	if err := store.Verify(); err == nil {
+		t.Errorf("Verify() on an empty bucket should fail")
+	}
+
+	if err := store.Put("apple", map[string]string{"frq": "1"}); err != nil {
+		t.Fatalf("Put() failed: %v", err)
+	}
+	if err := store.Verify(); err != nil {
+		t.Errorf("Verify() without metadata error = %v", err)
+	}
+
+	if err := store.WriteMetadata(&Metadata{RecordCount: 2}); err != nil {
+		t.Fatalf("WriteMetadata() failed: %v", err)
+	}
+	if err := store.Verify(); err == nil {
+		t.Errorf("Verify() should fail when the metadata record count does not match")
+	}
+}
+
+func TestDBStore_PublishTo(t *testing.T) {
+	dir := t.TempDir()
+	targetPath := filepath.Join(dir, "ecdict.bbolt")
+	csvPath := writeTestCSV(t, dir, testCSVRows)
+
+	// Publish a first version and keep a reader open on it.
+	first := newTestStore(t, Config{DBPath: BuildPath(targetPath)})
+	if err := first.Put("old", map[string]string{"frq": "1"}); err != nil {
+		t.Fatalf("Put() failed: %v", err)
+	}
+	if err := first.PublishTo(targetPath); err != nil {
+		t.Fatalf("PublishTo() error = %v", err)
+	}
+	first.Close()
+	os.Remove(BuildPath(targetPath))
+
+	reader, err := NewDBStore(Config{DBPath: targetPath, ReadOnly: true, Logger: zap.NewNop()})
+	if err != nil {
+		t.Fatalf("NewDBStore() on published file failed: %v", err)
+	}
+	defer reader.Close()
+
+	// Build and publish a second version while the reader is open.
+	second := newTestStore(t, Config{DBPath: BuildPath(targetPath)})
+	if _, err := second.ImportFromCSV(csvPath, 0); err != nil {
+		t.Fatalf("ImportFromCSV() error = %v", err)
+	}
+	if err := second.PublishTo(targetPath); err != nil {
+		t.Fatalf("PublishTo() while a reader is open error = %v", err)
+	}
+
+	// The open reader still sees the old inode.
+	if _, found, err := reader.Get("old"); err != nil || !found {
+		t.Errorf("open reader Get(old) found = %v, err = %v; want the old contents", found, err)
+	}
+
+	fresh, err := NewDBStore(Config{DBPath: targetPath, ReadOnly: true, Logger: zap.NewNop()})
+	if err != nil {
+		t.Fatalf("NewDBStore() on republished file failed: %v", err)
+	}
+	defer fresh.Close()
+	if _, found, _ := fresh.Get("apple"); !found {
+		t.Errorf("new reader Get(apple) not found after republishing")
+	}
+	if _, found, _ := fresh.Get("old"); found {
+		t.Errorf("new reader Get(old) found, want only the new contents")
+	}
+	if meta, found, _ := fresh.Metadata(); !found || meta.RecordCount != len(testCSVRows) {
+		t.Errorf("published Metadata() = %+v, %v", meta, found)
+	}
+
+	fi, err := os.Stat(targetPath)
+	if err != nil {
+		t.Fatalf("Stat() failed: %v", err)
+	}
+	if fi.Mode().Perm() != DefaultDBFileMode {
+		t.Errorf("published file mode = %v, want %v", fi.Mode().Perm(), DefaultDBFileMode)
+	}
+	matches, _ := filepath.Glob(filepath.Join(dir, ".*.tmp"))
+	if len(matches) != 0 {
+		t.Errorf("temporary files left behind: %v", matches)
+	}
+}
+
+// TestDBStore_PublishTo_KeepsOtherBuckets rebuilds one dictionary of a database as kvbuilder does:
+// in a build file that first copies the other buckets, then publishes it over the database. The
+// existing dictionary was written without metadata, as by kvbuilder before builds recorded it,
+// and must survive.
+func TestDBStore_PublishTo_KeepsOtherBuckets(t *testing.T) {
+	dir := t.TempDir()
+	targetPath := filepath.Join(dir, "ecdict.bbolt")
+
+	legacy := newTestStore(t, Config{DBPath: targetPath})
+	if err := legacy.PutEntry(&Entry{Word: "go", Translation: "v. 去"}); err != nil {
+		t.Fatalf("PutEntry() failed: %v", err)
+	}
+	legacy.Close()
+
+	build := newTestStore(t, Config{DBPath: BuildPath(targetPath), BucketName: "medical"})
+	if _, err := build.CopyDictionaries(targetPath); err != nil {
+		t.Fatalf("CopyDictionaries() failed: %v", err)
+	}
+	if _, err := build.ImportCSV(t.Context(), writeTestCSV(t, dir, medicalTestRows), ImportOptions{}); err != nil {
+		t.Fatalf("ImportCSV() failed: %v", err)
+	}
+	if err := build.PublishTo(targetPath); err != nil {
+		t.Fatalf("PublishTo() failed: %v", err)
+	}
+	build.Close()
+
+	published, err := NewDBStore(Config{DBPath: targetPath, ReadOnly: true, Logger: zap.NewNop()})
+	if err != nil {
+		t.Fatalf("NewDBStore() on published file failed: %v", err)
+	}
+	defer published.Close()
+	if entry, found, err := published.GetEntry("go"); err != nil || !found || entry.Translation != "v. 去" {
+		t.Errorf("GetEntry(go) = %+v, %v, %v; want the entry of the existing dictionary", entry, found, err)
+	}
+	if _, found, _ := published.Dictionary("medical").GetEntry("aspirin"); !found {
+		t.Error("medical GetEntry(aspirin) found nothing after publishing")
+	}
+}
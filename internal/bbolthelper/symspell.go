@@ -0,0 +1,196 @@
+package bbolthelper
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/agnivade/levenshtein"
+	bolt "go.etcd.io/bbolt"
+)
+
+// IndexSymSpell is the name of the symmetric-delete (SymSpell) index used by FindSimilar.
+// It is not built by default because it is several times larger than the dictionary keys.
+const IndexSymSpell = "symspell"
+
+const (
+	// SymSpellMaxDistance is the largest edit distance the symspell index can answer.
+	SymSpellMaxDistance = 2
+	// symSpellPrefixLength limits deletes to the first runes of each word. Longer words share
+	// their entries with every word of the same prefix, which bounds the index size; candidates
+	// are verified with a full Levenshtein distance afterwards.
+	symSpellPrefixLength = 7
+	// symSpellParamsKey holds the JSON-encoded symSpellParams. Words never contain NUL.
+	symSpellParamsKey = "\x00params"
+	// symSpellSep separates the words stored under one delete.
+	symSpellSep = "\n"
+	// symSpellWordsPerShard sets how many emit shards a dictionary is split into.
+	symSpellWordsPerShard = 100000
+)
+
+// symSpellParams records how the index was built, so lookups use the same settings.
+type symSpellParams struct {
+	MaxDistance  int `json:"max_distance"`
+	PrefixLength int `json:"prefix_length"`
+}
+
+// symSpellIndexBuilder maps every delete of every word's prefix to the words producing it.
+// The full delete map of a large dictionary needs several GB, so emit builds it in shards
+// selected by a hash of the delete; bucket pages left sparse by the unsorted shards are
+// compacted away when the database is published.
+type symSpellIndexBuilder struct {
+	words []string
+}
+
+func newSymSpellIndexBuilder() indexBuilder {
+	return &symSpellIndexBuilder{}
+}
+
+func (sb *symSpellIndexBuilder) add(word string, fields map[string]string) {
+	sb.words = append(sb.words, word)
+}
+
+func (sb *symSpellIndexBuilder) emit(put func(key, value []byte) error) error {
+	params, err := json.Marshal(symSpellParams{MaxDistance: SymSpellMaxDistance, PrefixLength: symSpellPrefixLength})
+	if err != nil {
+		return err
+	}
+	if err := put([]byte(symSpellParamsKey), params); err != nil {
+		return err
+	}
+
+	shards := uint32(1 + len(sb.words)/symSpellWordsPerShard)
+	for shard := uint32(0); shard < shards; shard++ {
+		// Words are referenced by their position in the sorted key list to keep memory use down.
+		deletes := make(map[string][]int32)
+		var scratch []string
+		for id, word := range sb.words {
+			scratch = appendSymSpellDeletes(scratch[:0], word, SymSpellMaxDistance, symSpellPrefixLength, shard, shards)
+			for _, d := range scratch {
+				deletes[d] = append(deletes[d], int32(id))
+			}
+		}
+
+		keys := make([]string, 0, len(deletes))
+		for k := range deletes {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			// bolt keeps a reference to each value until the transaction commits, so no buffer reuse.
+			var buf bytes.Buffer
+			for i, id := range deletes[k] {
+				if i > 0 {
+					buf.WriteString(symSpellSep)
+				}
+				buf.WriteString(sb.words[id])
+			}
+			if err := put(symSpellKey(k), buf.Bytes()); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// symSpellShard assigns a delete to one of n shards (FNV-1a).
+func symSpellShard(del []byte, n uint32) uint32 {
+	h := uint32(2166136261)
+	for i := 0; i < len(del); i++ {
+		h ^= uint32(del[i])
+		h *= 16777619
+	}
+	return h % n
+}
+
+// symSpellKey returns the bucket key of a delete. bolt rejects empty keys, so the empty delete
+// of short words is stored under a single NUL byte.
+func symSpellKey(del string) []byte {
+	if del == "" {
+		return []byte{0}
+	}
+	return []byte(del)
+}
+
+// symSpellDeletes returns the distinct strings obtained by deleting up to maxDistance runes from
+// the first prefixLength runes of word, including the unmodified prefix itself.
+func symSpellDeletes(word string, maxDistance, prefixLength int) []string {
+	return appendSymSpellDeletes(nil, word, maxDistance, prefixLength, 0, 1)
+}
+
+// appendSymSpellDeletes appends the distinct deletes of word that fall into the given shard.
+// Deletes outside the shard are hashed but never allocated, which keeps sharded builds cheap.
+func appendSymSpellDeletes(dst []string, word string, maxDistance, prefixLength int, shard, shards uint32) []string {
+	runes := []rune(word)
+	if len(runes) > prefixLength {
+		runes = runes[:prefixLength]
+	}
+	first := len(dst)
+	removed := make([]bool, len(runes))
+	buf := make([]byte, 0, len(word))
+
+	// Visit every set of at most maxDistance removed positions exactly once.
+	var visit func(start, left int)
+	visit = func(start, left int) {
+		buf = buf[:0]
+		for i, r := range runes {
+			if !removed[i] {
+				buf = utf8.AppendRune(buf, r)
+			}
+		}
+		if symSpellShard(buf, shards) == shard && !slices.Contains(dst[first:], string(buf)) {
+			dst = append(dst, string(buf))
+		}
+		if left == 0 {
+			return
+		}
+		for i := start; i < len(runes); i++ {
+			removed[i] = true
+			visit(i+1, left-1)
+			removed[i] = false
+		}
+	}
+	visit(0, maxDistance)
+	return dst
+}
+
+// symSpellLookup returns the words of the index bucket within maxDistance edits of word,
+// including word itself when present. It reports false when the index cannot answer queries
+// of that distance.
+func symSpellLookup(ib *bolt.Bucket, word string, maxDistance int) ([]string, bool, error) {
+	var params symSpellParams
+	if err := json.Unmarshal(ib.Get([]byte(symSpellParamsKey)), &params); err != nil {
+		return nil, false, fmt.Errorf("failed to decode symspell index parameters: %w", err)
+	}
+	if maxDistance > params.MaxDistance {
+		return nil, false, nil
+	}
+
+	seen := make(map[string]bool)
+	var matches []string
+	inputLen := len([]rune(word))
+	for _, d := range symSpellDeletes(word, maxDistance, params.PrefixLength) {
+		v := ib.Get(symSpellKey(d))
+		if v == nil {
+			continue
+		}
+		for _, candidate := range strings.Split(string(v), symSpellSep) {
+			if seen[candidate] {
+				continue
+			}
+			seen[candidate] = true
+			if abs(len([]rune(candidate))-inputLen) > maxDistance {
+				continue
+			}
+			if levenshtein.ComputeDistance(word, candidate) <= maxDistance {
+				matches = append(matches, candidate)
+			}
+		}
+	}
+	sort.Strings(matches)
+	return matches, true, nil
+}
@@ -0,0 +1,123 @@
+package bbolthelper
+
+import (
+	"math/rand"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/agnivade/levenshtein"
+	bolt "go.etcd.io/bbolt"
+)
+
+func TestSymSpellDeletes(t *testing.T) {
+	got := symSpellDeletes("abc", 1, symSpellPrefixLength)
+	sort.Strings(got)
+	if want := []string{"ab", "abc", "ac", "bc"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("symSpellDeletes(abc, 1) = %v, want %v", got, want)
+	}
+	// Only the prefix is used, and deletes are rune-based.
+	got = symSpellDeletes("éabcdefgh", 0, 3)
+	if want := []string{"éab"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("symSpellDeletes(éabcdefgh, 0, 3) = %v, want %v", got, want)
+	}
+}
+
+// newSymSpellTestStore stores words with the given frequencies and builds the symspell index.
+func newSymSpellTestStore(t *testing.T, words map[string]string) *DBStore {
+	t.Helper()
+	store := newTestStore(t, Config{BucketName: "TestBucket"})
+	err := store.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(store.bucketName))
+		for word, freq := range words {
+			v, err := SerializeWith(store.codec, map[string]string{FieldFrq: freq})
+			if err != nil {
+				return err
+			}
+			if err := b.Put([]byte(word), v); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Failed to store test words: %v", err)
+	}
+	if err := store.BuildIndexes(IndexSymSpell); err != nil {
+		t.Fatalf("BuildIndexes() failed: %v", err)
+	}
+	return store
+}
+
+func TestSymSpellLookup_MatchesLevenshtein(t *testing.T) {
+	r := rand.New(rand.NewSource(7))
+	randomWord := func() string {
+		b := make([]rune, 1+r.Intn(12))
+		for i := range b {
+			b[i] = []rune("abcdeé")[r.Intn(6)]
+		}
+		return string(b)
+	}
+	words := map[string]string{}
+	for len(words) < 2000 {
+		words[randomWord()] = "1"
+	}
+	store := newSymSpellTestStore(t, words)
+
+	err := store.db.View(func(tx *bolt.Tx) error {
+		ib := tx.Bucket(store.indexBucketName(IndexSymSpell))
+		for i := 0; i < 200; i++ {
+			q := randomWord()
+			for maxDistance := 1; maxDistance <= SymSpellMaxDistance; maxDistance++ {
+				got, ok, err := symSpellLookup(ib, q, maxDistance)
+				if err != nil || !ok {
+					t.Fatalf("symSpellLookup(%q, %d) = ok %v, err %v", q, maxDistance, ok, err)
+				}
+				var want []string
+				for w := range words {
+					if levenshtein.ComputeDistance(q, w) <= maxDistance {
+						want = append(want, w)
+					}
+				}
+				sort.Strings(want)
+				if !reflect.DeepEqual(got, want) {
+					t.Errorf("symSpellLookup(%q, %d) = %v, want %v", q, maxDistance, got, want)
+				}
+			}
+		}
+		if _, ok, _ := symSpellLookup(ib, "abc", SymSpellMaxDistance+1); ok {
+			t.Errorf("symSpellLookup() answered distance %d, want fallback", SymSpellMaxDistance+1)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDBStore_FindSimilarWithSymSpellIndex(t *testing.T) {
+	store := newSymSpellTestStore(t, map[string]string{
+		"develop": "100", "development": "80", "developer": "90", "devel": "70", "apple": "300", "apply": "250",
+	})
+
+	for _, tt := range []struct {
+		word        string
+		maxDistance int
+		want        []string
+	}{
+		{"develp", 1, []string{"devel", "develop"}},
+		{"devlp", 2, []string{"devel", "develop"}},
+		{"apple", 1, []string{"apply"}},
+		{"xyz", 2, []string{}},
+		// Beyond the index's distance FindSimilar falls back to the scan.
+		{"dvlp", 3, []string{"devel", "develop"}},
+	} {
+		got, err := store.FindSimilar(tt.word, tt.maxDistance)
+		if err != nil {
+			t.Fatalf("FindSimilar(%s, %d) failed: %v", tt.word, tt.maxDistance, err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("FindSimilar(%s, %d) with symspell index = %v, want %v", tt.word, tt.maxDistance, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,303 @@
+package bbolthelper
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// TableFormats are the formats of the tabular sources read by ImportTable.
+var TableFormats = []string{"csv", "tsv", "jsonl", "sqlite"}
+
+// RowSource reads the rows of a tabular source.
+type RowSource interface {
+	// Columns returns the column names in source order. A source whose rows may add columns, such
+	// as a JSON Lines file, includes those of the rows read so far.
+	Columns() []string
+	// Next returns the next row by column name, or io.EOF after the last row.
+	Next() (map[string]string, error)
+	Close() error
+}
+
+// OpenRowSource opens a tabular source: a CSV or TSV file with a header row, a JSON Lines file
+// of objects, or a table of an SQLite database (see Mapping.Table).
+func OpenRowSource(path, format string, m Mapping) (RowSource, error) {
+	switch format {
+	case "csv", "tsv":
+		return openDelimitedSource(path, format == "tsv")
+	case "jsonl":
+		return openJSONLSource(path)
+	case "sqlite":
+		return openSQLiteSource(path, m.Table)
+	}
+	return nil, fmt.Errorf("unknown table format '%s' (want one of %s)", format, strings.Join(TableFormats, ", "))
+}
+
+// delimitedSource reads a CSV or TSV file with a header row. TSV fields are split at every tab
+// without quoting, as in Tatoeba exports.
+type delimitedSource struct {
+	file    *os.File
+	csv     *csv.Reader
+	lines   *bufio.Scanner
+	columns []string
+}
+
+func openDelimitedSource(path string, tsv bool) (*delimitedSource, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	s := &delimitedSource{file: f}
+	if tsv {
+		s.lines = bufio.NewScanner(f)
+		s.lines.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	} else {
+		s.csv = csv.NewReader(f)
+		s.csv.FieldsPerRecord = -1
+	}
+	header, err := s.read()
+	if err != nil {
+		f.Close()
+		if err == io.EOF {
+			return nil, fmt.Errorf("'%s' is empty or has no header", path)
+		}
+		return nil, fmt.Errorf("failed to read header of '%s': %w", path, err)
+	}
+	// Strip a UTF-8 byte order mark from the first column name.
+	header[0] = strings.TrimPrefix(header[0], "\ufeff")
+	s.columns = header
+	return s, nil
+}
+
+// read returns the fields of the next line.
+func (s *delimitedSource) read() ([]string, error) {
+	if s.csv != nil {
+		return s.csv.Read()
+	}
+	for s.lines.Scan() {
+		line := strings.TrimSuffix(s.lines.Text(), "\r")
+		if line != "" {
+			return strings.Split(line, "\t"), nil
+		}
+	}
+	if err := s.lines.Err(); err != nil {
+		return nil, err
+	}
+	return nil, io.EOF
+}
+
+func (s *delimitedSource) Columns() []string {
+	return s.columns
+}
+
+func (s *delimitedSource) Next() (map[string]string, error) {
+	fields, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+	row := make(map[string]string, len(s.columns))
+	for i, col := range s.columns {
+		if i < len(fields) {
+			row[col] = fields[i]
+		}
+	}
+	return row, nil
+}
+
+func (s *delimitedSource) Close() error {
+	return s.file.Close()
+}
+
+// jsonlSource reads a JSON Lines file with one object per line. Strings are kept as they are,
+// numbers as written, true and false become "1" and "0" as in ECDICT's oxford column, arrays of
+// strings become one line per element, and other values their JSON text.
+type jsonlSource struct {
+	file    *os.File
+	lines   *bufio.Scanner
+	lineNo  int
+	columns []string
+	seen    map[string]bool
+	// first is the first object, read to find the columns.
+	first map[string]string
+}
+
+func openJSONLSource(path string) (*jsonlSource, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	s := &jsonlSource{file: f, lines: bufio.NewScanner(f), seen: make(map[string]bool)}
+	s.lines.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	row, err := s.read()
+	if err != nil {
+		f.Close()
+		if err == io.EOF {
+			return nil, fmt.Errorf("'%s' has no objects", path)
+		}
+		return nil, fmt.Errorf("failed to read '%s': %w", path, err)
+	}
+	s.first = row
+	return s, nil
+}
+
+// read decodes the next object, and adds its new keys to the columns in document order.
+func (s *jsonlSource) read() (map[string]string, error) {
+	for s.lines.Scan() {
+		s.lineNo++
+		line := bytes.TrimSpace(s.lines.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		dec := json.NewDecoder(bytes.NewReader(line))
+		dec.UseNumber()
+		if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+			return nil, fmt.Errorf("line %d is not a JSON object", s.lineNo)
+		}
+		row := make(map[string]string)
+		for dec.More() {
+			tok, err := dec.Token()
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", s.lineNo, err)
+			}
+			key, _ := tok.(string)
+			var v any
+			if err := dec.Decode(&v); err != nil {
+				return nil, fmt.Errorf("line %d: %w", s.lineNo, err)
+			}
+			if !s.seen[key] {
+				s.seen[key] = true
+				s.columns = append(s.columns, key)
+			}
+			row[key] = jsonValueString(v)
+		}
+		return row, nil
+	}
+	if err := s.lines.Err(); err != nil {
+		return nil, err
+	}
+	return nil, io.EOF
+}
+
+// jsonValueString renders a decoded JSON value as a field value.
+func jsonValueString(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case bool:
+		if v {
+			return "1"
+		}
+		return "0"
+	case []any:
+		lines := make([]string, 0, len(v))
+		for _, e := range v {
+			s, ok := e.(string)
+			if !ok {
+				data, _ := json.Marshal(v)
+				return string(data)
+			}
+			lines = append(lines, s)
+		}
+		return strings.Join(lines, "\n")
+	}
+	data, _ := json.Marshal(v)
+	return string(data)
+}
+
+// Columns returns the keys of the objects read so far, in order of appearance.
+func (s *jsonlSource) Columns() []string {
+	return s.columns
+}
+
+func (s *jsonlSource) Next() (map[string]string, error) {
+	if row := s.first; row != nil {
+		s.first = nil
+		return row, nil
+	}
+	return s.read()
+}
+
+func (s *jsonlSource) Close() error {
+	return s.file.Close()
+}
+
+// ImportTable imports a tabular source (see OpenRowSource) into the store's bucket, turning its
+// rows into records according to a mapping. Rows without a headword are skipped. Like the other
+// formats but unlike ImportCSV, the import cannot be resumed.
+func (s *DBStore) ImportTable(ctx context.Context, path, format string, mapping Mapping, opts ImportOptions) (int, error) {
+	if err := mapping.Validate(); err != nil {
+		return 0, err
+	}
+	sum, err := fileSHA256(path)
+	if err != nil {
+		return 0, err
+	}
+	src, err := OpenRowSource(path, format, mapping)
+	if err != nil {
+		return 0, err
+	}
+	defer src.Close()
+
+	columns := src.Columns()
+	if len(columns) == 0 {
+		return 0, fmt.Errorf("'%s' has no columns", path)
+	}
+	key := mapping.Key
+	if key == "" {
+		key = columns[0]
+	}
+	if !slices.Contains(columns, key) {
+		return 0, fmt.Errorf("key column '%s' not found in '%s' (columns: %s)", key, path, strings.Join(columns, ", "))
+	}
+	cols, names := mapping.fields(columns, key)
+	s.logger.Info("Importing table.", zap.String("source", path), zap.String("format", format),
+		zap.String("key", key), zap.Strings("columns", cols), zap.Strings("fields", names))
+
+	meta := &Metadata{
+		Format:        format,
+		SourceFile:    filepath.Base(path),
+		SourceSHA256:  sum,
+		HeaderColumns: append([]string{"word"}, names...),
+	}
+	rows := 0
+	next := func() (string, map[string]string, error) {
+		row, err := src.Next()
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				err = fmt.Errorf("failed to read row %d of '%s': %w", rows+1, path, err)
+			}
+			return "", nil, err
+		}
+		rows++
+		if len(src.Columns()) > len(columns) {
+			columns = src.Columns()
+			cols, names = mapping.fields(columns, key)
+			meta.HeaderColumns = append([]string{"word"}, names...)
+		}
+		word, fields := mapping.record(row, key, cols, names)
+		if word == "" {
+			s.logger.Warn("Row has no headword, skipping.", zap.Int("row", rows), zap.String("key", key))
+		}
+		return word, fields, nil
+	}
+	records, err := s.importRecords(ctx, next, meta, opts)
+	if err != nil {
+		return records, fmt.Errorf("failed to import %s table '%s': %w", format, path, err)
+	}
+	return records, nil
+}
@@ -0,0 +1,159 @@
+package bbolthelper
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// writeTestFile writes content to a file named name in a fresh temporary directory.
+func writeTestFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write %s: %v", name, err)
+	}
+	return path
+}
+
+func TestDBStore_ImportTable_TSV(t *testing.T) {
+	path := writeTestFile(t, "glossary.tsv", "\ufeffterm\tgloss\towner\n"+
+		"SLA\t服务等级协议; service level agreement\tops\r\n"+
+		"\n"+
+		"\tno headword\tops\n"+
+		"RPO\t恢复点目标\n")
+	mapping := Mapping{
+		Rename:     map[string]string{"gloss": FieldTranslation},
+		Drop:       []string{"owner"},
+		Transforms: map[string][]string{"gloss": {"lines:;"}},
+	}
+
+	store := newTestStore(t, Config{})
+	n, err := store.ImportTable(t.Context(), path, "tsv", mapping, ImportOptions{})
+	if err != nil {
+		t.Fatalf("ImportTable() failed: %v", err)
+	}
+	if n != 2 {
+		t.Errorf("ImportTable() stored %d records, want 2", n)
+	}
+	entry, found, err := store.GetEntry("sla")
+	if err != nil || !found {
+		t.Fatalf("GetEntry(sla) = found %v, %v", found, err)
+	}
+	if entry.Translation != "服务等级协议\nservice level agreement" || len(entry.Extra) != 0 {
+		t.Errorf("GetEntry(sla) = %+v", entry)
+	}
+	if entry, _, _ := store.GetEntry("rpo"); entry == nil || entry.Translation != "恢复点目标" {
+		t.Errorf("GetEntry(rpo) = %+v, want a short row to be stored", entry)
+	}
+
+	meta, _, err := store.Metadata()
+	if err != nil {
+		t.Fatalf("Metadata() failed: %v", err)
+	}
+	if meta.SourceFile != "glossary.tsv" || len(meta.HeaderColumns) != 2 || meta.HeaderColumns[1] != FieldTranslation {
+		t.Errorf("Metadata() = %+v", meta)
+	}
+}
+
+func TestDBStore_ImportTable_JSONL(t *testing.T) {
+	path := writeTestFile(t, "glossary.jsonl", `{"term": "Kubernetes", "senses": ["容器编排系统", "k8s"], "frq": 1200, "oxford": true, "meta": {"team": "infra"}}
+{"term": "etcd", "senses": ["分布式键值存储"], "frq": null}
+not json
+`)
+	mapping := Mapping{
+		Key:         "term",
+		KeepKeyCase: true,
+		Rename:      map[string]string{"senses": FieldTranslation},
+	}
+
+	store := newTestStore(t, Config{})
+	_, err := store.ImportTable(t.Context(), path, "jsonl", mapping, ImportOptions{})
+	if err == nil {
+		t.Fatal("ImportTable() accepted a line that is not JSON")
+	}
+
+	path = writeTestFile(t, "glossary.jsonl", `{"term": "Kubernetes", "senses": ["容器编排系统", "k8s"], "frq": 1200, "oxford": true, "meta": {"team": "infra"}}
+
+{"term": "etcd", "senses": ["分布式键值存储"], "frq": null}
+`)
+	store = newTestStore(t, Config{})
+	n, err := store.ImportTable(t.Context(), path, "jsonl", mapping, ImportOptions{})
+	if err != nil {
+		t.Fatalf("ImportTable() failed: %v", err)
+	}
+	if n != 2 {
+		t.Errorf("ImportTable() stored %d records, want 2", n)
+	}
+	entry, found, err := store.GetEntry("Kubernetes")
+	if err != nil || !found {
+		t.Fatalf("GetEntry(Kubernetes) = found %v, %v; want the key case kept", found, err)
+	}
+	if entry.Translation != "容器编排系统\nk8s" || entry.Frq != 1200 || !entry.Oxford || entry.Extra["meta"] != `{"team":"infra"}` {
+		t.Errorf("GetEntry(Kubernetes) = %+v", entry)
+	}
+	if entry, _, _ := store.GetEntry("etcd"); entry == nil || entry.Frq != 0 {
+		t.Errorf("GetEntry(etcd) = %+v", entry)
+	}
+}
+
+func TestDBStore_ImportTable_JSONLOptionalKeys(t *testing.T) {
+	path := writeTestFile(t, "glossary.jsonl", `{"term": "alpha"}
+{"term": "beta", "gloss": "第二个字母", "owner": "docs"}
+{"term": "gamma", "owner": "docs", "note": "γ"}
+`)
+	mapping := Mapping{Rename: map[string]string{"gloss": FieldTranslation}, Drop: []string{"owner"}}
+
+	store := newTestStore(t, Config{})
+	n, err := store.ImportTable(t.Context(), path, "jsonl", mapping, ImportOptions{})
+	if err != nil || n != 3 {
+		t.Fatalf("ImportTable() = %d, %v; want 3 records", n, err)
+	}
+	if entry, _, _ := store.GetEntry("beta"); entry == nil || entry.Translation != "第二个字母" || len(entry.Extra) != 0 {
+		t.Errorf("GetEntry(beta) = %+v, want the key missing from the first object renamed", entry)
+	}
+	if entry, _, _ := store.GetEntry("gamma"); entry == nil || entry.Extra["note"] != "γ" || entry.Extra["owner"] != "" {
+		t.Errorf("GetEntry(gamma) = %+v, want the note kept and the owner dropped", entry)
+	}
+	meta, _, err := store.Metadata()
+	if err != nil {
+		t.Fatalf("Metadata() failed: %v", err)
+	}
+	if want := []string{"word", FieldTranslation, "note"}; !reflect.DeepEqual(meta.HeaderColumns, want) {
+		t.Errorf("Metadata().HeaderColumns = %v, want %v", meta.HeaderColumns, want)
+	}
+
+	empty := writeTestFile(t, "empty.jsonl", "{}\n"+`{"term": "alpha"}`+"\n")
+	if _, err := store.ImportTable(t.Context(), empty, "jsonl", Mapping{}, ImportOptions{}); err == nil {
+		t.Error("ImportTable() accepted a first object without keys")
+	}
+}
+
+func TestDBStore_ImportTable_Errors(t *testing.T) {
+	store := newTestStore(t, Config{})
+	csvPath := writeTestCSV(t, t.TempDir(), testCSVRows)
+	if _, err := store.ImportTable(t.Context(), csvPath, "csv", Mapping{Key: "headword"}, ImportOptions{}); err == nil {
+		t.Error("ImportTable() accepted a missing key column")
+	}
+	if _, err := store.ImportTable(t.Context(), csvPath, "xml", Mapping{}, ImportOptions{}); err == nil {
+		t.Error("ImportTable() accepted an unknown format")
+	}
+	bad := Mapping{Transforms: map[string][]string{"word": {"shout"}}}
+	if _, err := store.ImportTable(t.Context(), csvPath, "csv", bad, ImportOptions{}); err == nil {
+		t.Error("ImportTable() accepted an unknown transform")
+	}
+
+	// The zero mapping reads a CSV file as ImportCSV does.
+	n, err := store.ImportTable(t.Context(), csvPath, "csv", Mapping{}, ImportOptions{})
+	if err != nil || n != len(testCSVRows) {
+		t.Fatalf("ImportTable() = %d, %v; want %d records", n, err, len(testCSVRows))
+	}
+	entry, found, err := store.GetEntry("listen")
+	if err != nil || !found || entry.Phonetic != "ˈlisn" {
+		t.Errorf("GetEntry(listen) = %+v, %v, %v", entry, found, err)
+	}
+	if entry, _, _ := store.GetEntry("go"); entry == nil || entry.Definition != "v. move\nn. a turn" {
+		t.Errorf("GetEntry(go) = %+v, want escaped line breaks kept", entry)
+	}
+}
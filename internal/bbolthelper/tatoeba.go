@@ -0,0 +1,293 @@
+package bbolthelper
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	bolt "go.etcd.io/bbolt"
+	"go.uber.org/zap"
+)
+
+const (
+	// tatoebaExamplesPerWord is the number of example sentences stored for each headword.
+	tatoebaExamplesPerWord = 10
+	// tatoebaTranslationLanguage is the Tatoeba code of the language the examples are shown in.
+	tatoebaTranslationLanguage = "cmn"
+	// exampleRareRank is the frq rank from which words count as rare, and the rank of unknown words.
+	exampleRareRank = 50000
+)
+
+// exampleWord matches the words of a sentence, including contractions such as "don't".
+var exampleWord = regexp.MustCompile(`\p{L}+(?:'\p{L}+)*`)
+
+// exampleWords returns the lowercased words of a sentence.
+func exampleWords(sentence string) []string {
+	return exampleWord.FindAllString(strings.ToLower(strings.ReplaceAll(sentence, "’", "'")), -1)
+}
+
+// exampleWordCost is the cost of a word in an example sentence: 1 for the thousand most common
+// words, growing with the ECDICT frequency rank (frq). Sentences are ranked by the total cost of
+// their words, so short sentences of common words come first.
+func exampleWordCost(frq int) int {
+	if frq <= 0 || frq > exampleRareRank {
+		frq = exampleRareRank
+	}
+	return 1 + frq/1000
+}
+
+// rankedExample is a candidate example sentence of a headword.
+type rankedExample struct {
+	cost int
+	Example
+}
+
+// less orders examples by cost, then by length, then alphabetically.
+func (a rankedExample) less(b rankedExample) bool {
+	if a.cost != b.cost {
+		return a.cost < b.cost
+	}
+	if len(a.Sentence) != len(b.Sentence) {
+		return len(a.Sentence) < len(b.Sentence)
+	}
+	return a.Sentence < b.Sentence
+}
+
+// addRankedExample inserts ex into the sorted list, keeping at most tatoebaExamplesPerWord
+// examples and no duplicate sentences.
+func addRankedExample(list []rankedExample, ex rankedExample) []rankedExample {
+	i := sort.Search(len(list), func(i int) bool { return ex.less(list[i]) })
+	if i >= tatoebaExamplesPerWord || (i > 0 && list[i-1].Sentence == ex.Sentence) {
+		return list
+	}
+	list = append(list, rankedExample{})
+	copy(list[i+1:], list[i:])
+	list[i] = ex
+	return list[:min(len(list), tatoebaExamplesPerWord)]
+}
+
+// exampleWordInfo is what the reference dictionary says about a word of an example sentence.
+type exampleWordInfo struct {
+	frq int
+	// headwords are the word itself, if it is a headword, and its lemmas.
+	headwords []string
+}
+
+// referenceDictionary returns the dictionary of the database, other than the store's own and not a
+// supplement, that can best lemmatize and rank the examples, such as ECDICT: preferably one with
+// inflections, then one with frq ranks, then the highest-priority one. A small glossary of higher
+// priority would leave most words of the sentences without a headword.
+func (s *DBStore) referenceDictionary() (*DBStore, bool, error) {
+	dicts, err := s.Dictionaries()
+	if err != nil {
+		return nil, false, err
+	}
+	var best *Metadata
+	bestScore := -1
+	for _, d := range dicts {
+		if d.Bucket == s.bucketName || d.Supplement {
+			continue
+		}
+		score := 0
+		if s.Dictionary(d.Bucket).hasInflections() {
+			score += 2
+		}
+		// Dictionaries built without metadata are ECDICT CSV imports, which have frq ranks.
+		if len(d.HeaderColumns) == 0 || slices.Contains(d.HeaderColumns, FieldFrq) {
+			score++
+		}
+		if score > bestScore {
+			best, bestScore = d, score
+		}
+	}
+	if best == nil {
+		return nil, false, nil
+	}
+	return s.Dictionary(best.Bucket), true, nil
+}
+
+// hasInflections reports whether the store's dictionary has a non-empty inflections index.
+func (s *DBStore) hasInflections() bool {
+	found := false
+	s.db.View(func(tx *bolt.Tx) error {
+		if b := tx.Bucket(s.indexBucketName(IndexInflections)); b != nil {
+			k, _ := b.Cursor().First()
+			found = k != nil
+		}
+		return nil
+	})
+	return found
+}
+
+// readTSV calls fn with the tab-separated fields of every line of a file.
+func readTSV(ctx context.Context, path string, fn func(fields []string) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	for lineNo := 1; sc.Scan(); lineNo++ {
+		if lineNo%100000 == 0 {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+		}
+		if err := fn(strings.Split(sc.Text(), "\t")); err != nil {
+			return fmt.Errorf("'%s' line %d: %w", path, lineNo, err)
+		}
+	}
+	return sc.Err()
+}
+
+// ImportTatoeba imports example sentences from a Tatoeba export: sentences.tsv, with the ID,
+// language and text of each sentence, and links.tsv, next to it, with the IDs of each sentence
+// and of a translation. Every English sentence with a Mandarin translation becomes an example of
+// the headwords of its words, lemmatized with the inflections index of the reference dictionary
+// (the highest-priority other dictionary, such as ECDICT), so that "He went home." is an example
+// of "went" and of "go". The tatoebaExamplesPerWord cheapest examples are stored for each
+// headword, using the word frequencies (frq) of the reference dictionary; see exampleWordCost.
+//
+// The dictionary is marked as a supplement, so ne merges the examples into the entries of the
+// other dictionaries. The display name defaults to "Tatoeba".
+func (s *DBStore) ImportTatoeba(ctx context.Context, sentencesPath string, opts ImportOptions) (int, error) {
+	// Tatoeba publishes the files as .csv, although they are tab-separated.
+	linksPath := filepath.Join(filepath.Dir(sentencesPath), "links.tsv")
+	if _, err := os.Stat(linksPath); err != nil {
+		linksPath = filepath.Join(filepath.Dir(sentencesPath), "links.csv")
+	}
+	sum, err := fileSHA256(sentencesPath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read Tatoeba sentences '%s': %w", sentencesPath, err)
+	}
+
+	ref, found, err := s.referenceDictionary()
+	if err != nil {
+		return 0, err
+	}
+	if !found {
+		s.logger.Warn("No dictionary to lemmatize and rank the examples with; every word is its own headword.")
+	}
+	vocabulary := make(map[string]exampleWordInfo)
+	lookup := func(word string) (exampleWordInfo, error) {
+		if info, ok := vocabulary[word]; ok {
+			return info, nil
+		}
+		info := exampleWordInfo{headwords: []string{word}}
+		if ref != nil {
+			entry, found, err := ref.GetEntry(word)
+			if err != nil {
+				return info, err
+			}
+			lemmas, err := ref.Lemmas(word)
+			if err != nil {
+				return info, err
+			}
+			info.headwords = nil
+			if found {
+				info.frq = entry.Frq
+				info.headwords = append(info.headwords, word)
+			}
+			for _, l := range lemmas {
+				info.headwords = append(info.headwords, l.Lemma)
+			}
+			info.headwords = dedupe(info.headwords)
+		}
+		vocabulary[word] = info
+		return info, nil
+	}
+
+	// The translations are read first, then the links from the English sentences to them, so that
+	// only the examples are held in memory rather than every English sentence.
+	translations := make(map[string]string)
+	err = readTSV(ctx, sentencesPath, func(f []string) error {
+		if len(f) >= 3 && f[1] == tatoebaTranslationLanguage {
+			translations[f[0]] = f[2]
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to read Tatoeba sentences '%s': %w", sentencesPath, err)
+	}
+	translated := make(map[string]string)
+	err = readTSV(ctx, linksPath, func(f []string) error {
+		if len(f) < 2 {
+			return nil
+		}
+		if t, ok := translations[f[1]]; ok && translated[f[0]] == "" {
+			translated[f[0]] = t
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to read Tatoeba links '%s': %w", linksPath, err)
+	}
+	translations = nil
+
+	examples := make(map[string][]rankedExample)
+	err = readTSV(ctx, sentencesPath, func(f []string) error {
+		if len(f) < 3 || f[1] != "eng" || translated[f[0]] == "" {
+			return nil
+		}
+		ex := rankedExample{Example: Example{Sentence: strings.TrimSpace(f[2]), Translation: translated[f[0]]}}
+		var headwords []string
+		for _, word := range exampleWords(ex.Sentence) {
+			info, err := lookup(word)
+			if err != nil {
+				return err
+			}
+			ex.cost += exampleWordCost(info.frq)
+			headwords = append(headwords, info.headwords...)
+		}
+		for _, h := range dedupe(headwords) {
+			examples[h] = addRankedExample(examples[h], ex)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to read Tatoeba sentences '%s': %w", sentencesPath, err)
+	}
+	s.logger.Info("Ranked Tatoeba examples.", zap.Int("headwords", len(examples)), zap.Int("vocabulary", len(vocabulary)))
+
+	words := make([]string, 0, len(examples))
+	for w := range examples {
+		words = append(words, w)
+	}
+	sort.Strings(words)
+	next := func() (string, map[string]string, error) {
+		if len(words) == 0 {
+			return "", nil, io.EOF
+		}
+		w := words[0]
+		words = words[1:]
+		e := &Entry{Word: w}
+		for _, ex := range examples[w] {
+			e.Examples = append(e.Examples, ex.Example)
+		}
+		return w, e.Map(), nil
+	}
+
+	opts.Manifest.Supplement = true
+	if opts.Manifest.DisplayName == "" {
+		opts.Manifest.DisplayName = "Tatoeba"
+	}
+	meta := &Metadata{
+		Format:        "tatoeba",
+		SourceFile:    filepath.Base(sentencesPath),
+		SourceSHA256:  sum,
+		HeaderColumns: []string{"word", FieldExamples},
+	}
+	records, err := s.importRecords(ctx, next, meta, opts)
+	if err != nil {
+		return records, fmt.Errorf("failed to import Tatoeba examples '%s': %w", sentencesPath, err)
+	}
+	return records, nil
+}
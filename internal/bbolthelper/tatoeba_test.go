@@ -0,0 +1,150 @@
+package bbolthelper
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writeTestTatoeba writes a Tatoeba export of English and Mandarin sentences and returns the path
+// of sentences.tsv. Sentence 5 has no Mandarin translation, sentence 6 is German.
+func writeTestTatoeba(t *testing.T, dir string) string {
+	t.Helper()
+	files := map[string][]string{
+		"sentences.tsv": {
+			"1\teng\tWe go.",
+			"2\teng\tHe went to the apple orchard yesterday.",
+			"3\teng\tApply it.",
+			"4\teng\tI went.",
+			"5\teng\tGo away.",
+			"6\tdeu\tGeh.",
+			"101\tcmn\t我们走。",
+			"102\tcmn\t他昨天去了苹果园。",
+			"103\tcmn\t应用它。",
+			"104\tcmn\t我去了。",
+		},
+		"links.tsv": {"1\t101", "101\t1", "2\t102", "3\t103", "4\t104", "5\t6"},
+	}
+	for name, lines := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(strings.Join(lines, "\n")+"\n"), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	return filepath.Join(dir, "sentences.tsv")
+}
+
+func TestAddRankedExample(t *testing.T) {
+	var list []rankedExample
+	for i := 20; i > 0; i-- {
+		list = addRankedExample(list, rankedExample{cost: i, Example: Example{Sentence: strings.Repeat("x", i)}})
+	}
+	list = addRankedExample(list, rankedExample{cost: 1, Example: Example{Sentence: "x"}})
+	if len(list) != tatoebaExamplesPerWord {
+		t.Fatalf("addRankedExample() kept %d examples, want %d", len(list), tatoebaExamplesPerWord)
+	}
+	for i, ex := range list {
+		if ex.cost != i+1 {
+			t.Errorf("list[%d].cost = %d, want %d (sorted, without the duplicate)", i, ex.cost, i+1)
+		}
+	}
+}
+
+func TestDBStore_ImportTatoeba(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "examples.db")
+	base := newTestStore(t, Config{DBPath: dbPath})
+	if _, err := base.ImportCSV(t.Context(), writeTestCSV(t, t.TempDir(), testCSVRows), ImportOptions{}); err != nil {
+		t.Fatalf("ImportCSV() failed: %v", err)
+	}
+	if err := base.BuildIndexes(IndexInflections); err != nil {
+		t.Fatalf("BuildIndexes() failed: %v", err)
+	}
+	base.Close()
+
+	store := newTestStore(t, Config{DBPath: dbPath, BucketName: "tatoeba"})
+	n, err := store.ImportTatoeba(t.Context(), writeTestTatoeba(t, t.TempDir()), ImportOptions{})
+	if err != nil {
+		t.Fatalf("ImportTatoeba() failed: %v", err)
+	}
+	// go, went, apple and apply are headwords; the other words are not.
+	if n != 4 {
+		t.Errorf("ImportTatoeba() stored %d records, want 4", n)
+	}
+
+	tests := []struct {
+		word string
+		want []Example
+	}{
+		// "We go." costs 1+51, "I went." 51+2 and the long sentence much more.
+		{"go", []Example{
+			{"We go.", "我们走。"},
+			{"I went.", "我去了。"},
+			{"He went to the apple orchard yesterday.", "他昨天去了苹果园。"},
+		}},
+		{"went", []Example{{"I went.", "我去了。"}, {"He went to the apple orchard yesterday.", "他昨天去了苹果园。"}}},
+		{"apply", []Example{{"Apply it.", "应用它。"}}},
+	}
+	for _, tc := range tests {
+		entry, found, err := store.GetEntry(tc.word)
+		if err != nil || !found {
+			t.Errorf("GetEntry(%q) = found %v, %v; want examples", tc.word, found, err)
+			continue
+		}
+		if !reflect.DeepEqual(entry.Examples, tc.want) {
+			t.Errorf("GetEntry(%q).Examples = %v, want %v", tc.word, entry.Examples, tc.want)
+		}
+	}
+
+	meta, found, err := store.Metadata()
+	if err != nil || !found || !meta.Supplement || meta.DisplayName != "Tatoeba" {
+		t.Errorf("Metadata() = %+v, found %v, %v; want a Tatoeba supplement", meta, found, err)
+	}
+}
+
+func TestDBStore_ImportTatoeba_WithGlossary(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "examples.db")
+	// A higher-priority glossary without inflections or frq ranks must not become the reference.
+	glossary := newTestStore(t, Config{DBPath: dbPath, BucketName: "medical"})
+	if _, err := glossary.ImportTable(t.Context(), writeTestFile(t, "glossary.jsonl",
+		`{"term": "apple", "translation": "苹果"}`+"\n"+`{"term": "aspirin", "translation": "阿司匹林"}`+"\n",
+	), "jsonl", Mapping{}, ImportOptions{Manifest: Manifest{Priority: 5}}); err != nil {
+		t.Fatalf("ImportTable(glossary) failed: %v", err)
+	}
+	if err := glossary.BuildIndexes(IndexInflections); err != nil {
+		t.Fatalf("BuildIndexes(glossary) failed: %v", err)
+	}
+	glossary.Close()
+	base := newTestStore(t, Config{DBPath: dbPath})
+	if _, err := base.ImportCSV(t.Context(), writeTestCSV(t, t.TempDir(), testCSVRows), ImportOptions{}); err != nil {
+		t.Fatalf("ImportCSV() failed: %v", err)
+	}
+	if err := base.BuildIndexes(IndexInflections); err != nil {
+		t.Fatalf("BuildIndexes() failed: %v", err)
+	}
+	base.Close()
+
+	store := newTestStore(t, Config{DBPath: dbPath, BucketName: "tatoeba"})
+	ref, found, err := store.referenceDictionary()
+	if err != nil || !found || ref.BucketName() != DefaultBucketName {
+		t.Fatalf("referenceDictionary() = %v, %v; want %s", found, err, DefaultBucketName)
+	}
+	n, err := store.ImportTatoeba(t.Context(), writeTestTatoeba(t, t.TempDir()), ImportOptions{})
+	if err != nil || n != 4 {
+		t.Fatalf("ImportTatoeba() = %d, %v; want 4 records", n, err)
+	}
+	if entry, found, _ := store.GetEntry("go"); !found || len(entry.Examples) != 3 {
+		t.Errorf("GetEntry(go) = %+v, %v; want the examples of go and went", entry, found)
+	}
+}
+
+func TestParseExamples(t *testing.T) {
+	examples := []Example{{"Go.", "走。"}, {"No translation.", ""}}
+	stored := FormatExamples(examples)
+	if want := `Go.\t走。\nNo translation.`; stored != want {
+		t.Errorf("FormatExamples() = %q, want %q", stored, want)
+	}
+	if got := ParseExamples(stored); !reflect.DeepEqual(got, examples) {
+		t.Errorf("ParseExamples(FormatExamples()) = %v, want %v", got, examples)
+	}
+}
@@ -0,0 +1,364 @@
+package bbolthelper
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	bolt "go.etcd.io/bbolt"
+	"go.uber.org/zap"
+)
+
+// IndexTranslation is the name of the character n-gram index over the translation field,
+// used by ReverseLookup to find English headwords from Chinese text.
+const IndexTranslation = "translation"
+
+// The translation index bucket holds two key spaces:
+//
+//	"g:" + n-gram        -> posting list: ascending word ids, delta-encoded as uvarints
+//	"w:" + uint32 BE id  -> headword
+//
+// Ids are positions in the sorted key list at build time; they keep posting lists a few bytes
+// per entry. N-grams are the single CJK characters and the pairs of adjacent CJK characters.
+const (
+	translationGramPrefix = "g:"
+	translationWordPrefix = "w:"
+)
+
+// Match qualities of ReverseMatch, best first.
+const (
+	MatchExact    = "exact"    // One sense of the translation is the query
+	MatchContains = "contains" // The translation contains the query
+	MatchPartial  = "partial"  // The translation contains some of the query's n-grams
+)
+
+// matchTiers orders the match qualities for ranking.
+var matchTiers = map[string]int{MatchExact: 3, MatchContains: 2, MatchPartial: 1}
+
+// ReverseMatch is an English headword whose translation matches a Chinese query.
+type ReverseMatch struct {
+	Word        string `json:"word"`
+	Translation string `json:"translation"`
+	Frq         int    `json:"frq,omitempty"`
+	Match       string `json:"match"`
+	// Score refines the ranking within a match quality: for "contains" it is the share of the
+	// tightest sense taken by the query, for "partial" the share of query n-grams found.
+	Score float64 `json:"score"`
+}
+
+// IsCJK reports whether r is a Chinese, Japanese or Korean character.
+func IsCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul)
+}
+
+// ContainsCJK reports whether s contains any CJK character.
+func ContainsCJK(s string) bool {
+	return strings.IndexFunc(s, IsCJK) >= 0
+}
+
+// translationGrams returns the distinct n-grams of the CJK runs of s: every character, and every
+// pair of adjacent characters.
+func translationGrams(s string) []string {
+	seen := make(map[string]bool)
+	var grams []string
+	add := func(g string) {
+		if !seen[g] {
+			seen[g] = true
+			grams = append(grams, g)
+		}
+	}
+	var prev rune
+	for _, r := range s {
+		if !IsCJK(r) {
+			prev = 0
+			continue
+		}
+		add(string(r))
+		if prev != 0 {
+			add(string([]rune{prev, r}))
+		}
+		prev = r
+	}
+	return grams
+}
+
+// queryGrams returns the n-grams to look up for a query: the pairs of adjacent characters of
+// each CJK run, or the character itself for runs of one character.
+func queryGrams(query string) []string {
+	var grams []string
+	for _, run := range strings.FieldsFunc(query, func(r rune) bool { return !IsCJK(r) }) {
+		runes := []rune(run)
+		if len(runes) == 1 {
+			grams = append(grams, run)
+			continue
+		}
+		for i := 1; i < len(runes); i++ {
+			grams = append(grams, string(runes[i-1:i+1]))
+		}
+	}
+	return dedupe(grams)
+}
+
+// translationIndexBuilder collects the n-gram posting lists of all translations.
+type translationIndexBuilder struct {
+	words    []string
+	postings map[string][]uint32
+}
+
+func newTranslationIndexBuilder() indexBuilder {
+	return &translationIndexBuilder{postings: make(map[string][]uint32)}
+}
+
+func (tb *translationIndexBuilder) add(word string, fields map[string]string) {
+	grams := translationGrams(decodeEscapes(fields[FieldTranslation]))
+	if len(grams) == 0 {
+		return
+	}
+	id := uint32(len(tb.words))
+	tb.words = append(tb.words, word)
+	for _, g := range grams {
+		tb.postings[g] = append(tb.postings[g], id)
+	}
+}
+
+func (tb *translationIndexBuilder) emit(put func(key, value []byte) error) error {
+	grams := make([]string, 0, len(tb.postings))
+	for g := range tb.postings {
+		grams = append(grams, g)
+	}
+	sort.Strings(grams)
+	for _, g := range grams {
+		if err := put([]byte(translationGramPrefix+g), encodePostings(tb.postings[g])); err != nil {
+			return err
+		}
+	}
+	for id, word := range tb.words {
+		if err := put(translationWordKey(uint32(id)), []byte(word)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// translationWordKey returns the key of a headword id.
+func translationWordKey(id uint32) []byte {
+	key := make([]byte, len(translationWordPrefix)+4)
+	copy(key, translationWordPrefix)
+	binary.BigEndian.PutUint32(key[len(translationWordPrefix):], id)
+	return key
+}
+
+// encodePostings delta-encodes ascending ids as uvarints.
+func encodePostings(ids []uint32) []byte {
+	var buf bytes.Buffer
+	var tmp [binary.MaxVarintLen32]byte
+	prev := uint32(0)
+	for _, id := range ids {
+		buf.Write(tmp[:binary.PutUvarint(tmp[:], uint64(id-prev))])
+		prev = id
+	}
+	return buf.Bytes()
+}
+
+// decodePostings is the inverse of encodePostings.
+func decodePostings(data []byte) ([]uint32, error) {
+	var ids []uint32
+	prev := uint32(0)
+	for len(data) > 0 {
+		delta, n := binary.Uvarint(data)
+		if n <= 0 {
+			return nil, fmt.Errorf("corrupt posting list")
+		}
+		data = data[n:]
+		prev += uint32(delta)
+		ids = append(ids, prev)
+	}
+	return ids, nil
+}
+
+var (
+	// translationPOS matches a leading part-of-speech label such as "n. " or "vt. ".
+	translationPOS = regexp.MustCompile(`^[a-z]+\.\s*`)
+	// translationNotes matches bracketed domain labels and parenthesized notes, e.g. "[计]" or "(go的过去式)".
+	translationNotes = regexp.MustCompile(`\[[^\]]*\]|\([^)]*\)|（[^）]*）`)
+)
+
+// translationSenses splits a translation into its individual senses, without labels or notes.
+func translationSenses(translation string) []string {
+	var senses []string
+	for _, line := range strings.Split(translation, "\n") {
+		line = translationNotes.ReplaceAllString(translationPOS.ReplaceAllString(strings.TrimSpace(line), ""), "")
+		for _, sense := range strings.FieldsFunc(line, func(r rune) bool { return strings.ContainsRune(",，;；、", r) }) {
+			if sense = strings.TrimSpace(sense); sense != "" {
+				senses = append(senses, sense)
+			}
+		}
+	}
+	return senses
+}
+
+// scoreTranslation rates how well translation matches query. It returns false for no match.
+func scoreTranslation(query, translation string, grams []string) (string, float64, bool) {
+	queryLen := utf8.RuneCountInString(query)
+	if strings.Contains(translation, query) {
+		tightest := utf8.RuneCountInString(translation)
+		for _, sense := range translationSenses(translation) {
+			if sense == query {
+				return MatchExact, 1, true
+			}
+			if n := utf8.RuneCountInString(sense); strings.Contains(sense, query) && n < tightest {
+				tightest = n
+			}
+		}
+		return MatchContains, float64(queryLen) / float64(tightest), true
+	}
+	if len(grams) == 0 {
+		return "", 0, false
+	}
+	found := 0
+	for _, g := range grams {
+		if strings.Contains(translation, g) {
+			found++
+		}
+	}
+	if found == 0 {
+		return "", 0, false
+	}
+	return MatchPartial, float64(found) / float64(len(grams)), true
+}
+
+// ReverseLookup finds English headwords whose translation matches a Chinese query, best first:
+// exact senses before translations containing the query, before partial n-gram matches; within
+// each, more frequent words come first. It uses the translation index when it was built and
+// scans every translation otherwise. A limit <= 0 returns all matches.
+func (s *DBStore) ReverseLookup(query string, limit int) ([]ReverseMatch, error) {
+	query = strings.TrimSpace(query)
+	grams := queryGrams(query)
+	if len(grams) == 0 {
+		return nil, nil
+	}
+
+	var matches []ReverseMatch
+	err := s.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(s.bucketName))
+		if b == nil {
+			return fmt.Errorf("bucket '%s' not found during ReverseLookup operation", s.bucketName)
+		}
+
+		consider := func(word string, v []byte) {
+			// Decode only the two fields needed; binary records skip building the full map.
+			translation, _, err := DeserializeField(v, FieldTranslation)
+			var frq string
+			if err == nil {
+				frq, _, err = DeserializeField(v, FieldFrq)
+			}
+			if err != nil {
+				s.logger.Warn("Failed to deserialize value for reverse lookup, skipping.", zap.String("word", word), zap.Error(err))
+				return
+			}
+			entry := ParseEntry(word, map[string]string{FieldTranslation: translation, FieldFrq: frq})
+			if match, score, ok := scoreTranslation(query, entry.Translation, grams); ok {
+				matches = append(matches, ReverseMatch{Word: word, Translation: entry.Translation, Frq: entry.Frq, Match: match, Score: score})
+			}
+		}
+
+		if ib := tx.Bucket(s.indexBucketName(IndexTranslation)); ib != nil {
+			words, err := translationCandidates(ib, grams)
+			if err != nil {
+				return fmt.Errorf("failed to read translation index: %w", err)
+			}
+			s.logger.Debug("Reverse lookup used translation index", zap.String("query", query), zap.Int("candidates", len(words)))
+			for _, word := range words {
+				if v := b.Get([]byte(word)); v != nil {
+					consider(word, v)
+				}
+			}
+			return nil
+		}
+
+		s.logger.Debug("Translation index not built, scanning all translations", zap.String("query", query))
+		return b.ForEach(func(k, v []byte) error {
+			consider(string(k), v)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return orderReverseMatches(matches, limit), nil
+}
+
+// MergeReverseMatches merges the matches of several dictionaries, given in priority order, as
+// ReverseLookup orders those of one dictionary. A word found in several dictionaries is listed
+// once, with its translation from the first.
+func MergeReverseMatches(lists [][]ReverseMatch, limit int) []ReverseMatch {
+	return orderReverseMatches(mergeResults(lists, func(m ReverseMatch) string { return m.Word }), limit)
+}
+
+// orderReverseMatches sorts matches by match quality, then frequency, and applies limit.
+func orderReverseMatches(matches []ReverseMatch, limit int) []ReverseMatch {
+	sort.SliceStable(matches, func(i, j int) bool {
+		a, b := matches[i], matches[j]
+		if matchTiers[a.Match] != matchTiers[b.Match] {
+			return matchTiers[a.Match] > matchTiers[b.Match]
+		}
+		if a.Match == MatchPartial && a.Score != b.Score {
+			return a.Score > b.Score // More of the query found
+		}
+		if (a.Frq == 0) != (b.Frq == 0) {
+			return b.Frq == 0 // Ranked words before unranked ones
+		}
+		if a.Frq != b.Frq {
+			return a.Frq < b.Frq // Lower frq value first (higher frequency)
+		}
+		return a.Score > b.Score
+	})
+	if limit > 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches
+}
+
+// translationCandidates returns the headwords whose translations contain every query n-gram.
+// When no translation has them all, it returns those containing any of them, for partial matches.
+func translationCandidates(ib *bolt.Bucket, grams []string) ([]string, error) {
+	counts := make(map[uint32]int)
+	for _, g := range grams {
+		ids, err := decodePostings(ib.Get([]byte(translationGramPrefix + g)))
+		if err != nil {
+			return nil, fmt.Errorf("n-gram '%s': %w", g, err)
+		}
+		for _, id := range ids {
+			counts[id]++
+		}
+	}
+
+	var ids []uint32
+	for id, n := range counts {
+		if n == len(grams) {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		for id := range counts {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	words := make([]string, 0, len(ids))
+	for _, id := range ids {
+		word := ib.Get(translationWordKey(id))
+		if word == nil {
+			return nil, fmt.Errorf("headword id %d not found", id)
+		}
+		words = append(words, string(word))
+	}
+	return words, nil
+}
@@ -0,0 +1,105 @@
+package bbolthelper
+
+import (
+	"reflect"
+	"testing"
+)
+
+// reverseTestRows adds Chinese translations sharing characters with the fixture's "苹果".
+var reverseTestRows = append([]string{
+	`apple tree,,,n. 苹果树,,,,,,5000,,,`,
+	`applesauce,,,n. 苹果酱; [美]胡说,,,,,,9000,,,`,
+	`pomaceous,,,a. 苹果的,,,,,,,,,`,
+	`juice,,,n. 果汁,,,,,,1800,,,`,
+}, testCSVRows...)
+
+func TestTranslationGrams(t *testing.T) {
+	if got, want := translationGrams("n. 苹果, 果"), []string{"苹", "果", "苹果"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("translationGrams() = %v, want %v", got, want)
+	}
+	if got, want := queryGrams("苹果汁 书"), []string{"苹果", "果汁", "书"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("queryGrams() = %v, want %v", got, want)
+	}
+	if !ContainsCJK("go 去") || ContainsCJK("café") {
+		t.Error("ContainsCJK() misclassified input")
+	}
+
+	ids := []uint32{0, 3, 4, 300, 70000}
+	got, err := decodePostings(encodePostings(ids))
+	if err != nil || !reflect.DeepEqual(got, ids) {
+		t.Errorf("decodePostings(encodePostings(%v)) = %v, %v", ids, got, err)
+	}
+}
+
+func TestScoreTranslation(t *testing.T) {
+	tests := []struct {
+		translation string
+		wantMatch   string
+		wantScore   float64
+	}{
+		{"n. 苹果\nn. 苹果公司", MatchExact, 1},
+		{"v. 去(go的过去式)", "", 0},
+		{"n. 苹果树", MatchContains, 2.0 / 3},
+		{"n. 果汁", "", 0},
+	}
+	for _, tt := range tests {
+		match, score, ok := scoreTranslation("苹果", tt.translation, queryGrams("苹果"))
+		if match != tt.wantMatch || score != tt.wantScore || ok != (tt.wantMatch != "") {
+			t.Errorf("scoreTranslation(苹果, %q) = %q, %v, %v; want %q, %v", tt.translation, match, score, ok, tt.wantMatch, tt.wantScore)
+		}
+	}
+	if match, _, _ := scoreTranslation("去", "v. 去(go的过去式)", queryGrams("去")); match != MatchExact {
+		t.Errorf("scoreTranslation() ignoring notes = %q, want %q", match, MatchExact)
+	}
+}
+
+func TestDBStore_ReverseLookup(t *testing.T) {
+	store := newTestStore(t, Config{BucketName: "TestBucket"})
+	if _, err := store.ImportFromCSV(writeTestCSV(t, t.TempDir(), reverseTestRows), 0); err != nil {
+		t.Fatalf("ImportFromCSV() failed: %v", err)
+	}
+
+	words := func(ms []ReverseMatch) []string {
+		var out []string
+		for _, m := range ms {
+			out = append(out, m.Word)
+		}
+		return out
+	}
+	tests := []struct {
+		query string
+		limit int
+		want  []string
+	}{
+		// Exact sense first, then containing translations by frequency; unranked words last.
+		{"苹果", 0, []string{"apple", "apple tree", "applesauce", "pomaceous"}},
+		{"苹果", 2, []string{"apple", "apple tree"}},
+		{"去", 0, []string{"go", "went"}},
+		// No translation has both n-grams: partial matches, most of the query found first.
+		{"苹果汁", 2, []string{"juice", "apple"}},
+		{"书", 0, nil},
+		{"book", 0, nil},
+	}
+
+	check := func(label string) {
+		for _, tt := range tests {
+			got, err := store.ReverseLookup(tt.query, tt.limit)
+			if err != nil {
+				t.Fatalf("%s: ReverseLookup(%q) failed: %v", label, tt.query, err)
+			}
+			if !reflect.DeepEqual(words(got), tt.want) {
+				t.Errorf("%s: ReverseLookup(%q, %d) = %v, want %v", label, tt.query, tt.limit, words(got), tt.want)
+			}
+		}
+	}
+	check("scan")
+	if err := store.BuildIndexes(IndexTranslation); err != nil {
+		t.Fatalf("BuildIndexes() failed: %v", err)
+	}
+	check("index")
+
+	got, _ := store.ReverseLookup("苹果", 1)
+	if want := (ReverseMatch{Word: "apple", Translation: "n. 苹果", Frq: 2000, Match: MatchExact, Score: 1}); len(got) != 1 || got[0] != want {
+		t.Errorf("ReverseLookup(苹果, 1) = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,398 @@
+package bbolthelper
+
+import (
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"html"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// wiktionaryPage is the part of a <page> element of a MediaWiki XML dump the importer reads.
+type wiktionaryPage struct {
+	Title    string    `xml:"title"`
+	NS       int       `xml:"ns"`
+	Redirect *struct{} `xml:"redirect"`
+	Text     string    `xml:"revision>text"`
+}
+
+// wiktionaryPOS maps the part-of-speech headings of English Wiktionary entries to the
+// abbreviations used by ECDICT definitions. Senses under other headings are not imported.
+var wiktionaryPOS = map[string]string{
+	"Noun":         "n.",
+	"Proper noun":  "n.",
+	"Verb":         "v.",
+	"Adjective":    "adj.",
+	"Adverb":       "adv.",
+	"Pronoun":      "pron.",
+	"Preposition":  "prep.",
+	"Conjunction":  "conj.",
+	"Interjection": "int.",
+	"Determiner":   "det.",
+	"Article":      "art.",
+	"Numeral":      "num.",
+	"Particle":     "part.",
+	"Prefix":       "pref.",
+	"Suffix":       "suf.",
+	"Phrase":       "phr.",
+	"Proverb":      "phr.",
+	"Abbreviation": "abbr.",
+	"Initialism":   "abbr.",
+	"Acronym":      "abbr.",
+	"Contraction":  "abbr.",
+}
+
+// wiktionaryLanguages names the language codes that commonly appear in English etymologies.
+// Other codes are shown as they are.
+var wiktionaryLanguages = map[string]string{
+	"en":      "English",
+	"enm":     "Middle English",
+	"ang":     "Old English",
+	"sco":     "Scots",
+	"gmw-pro": "Proto-West Germanic",
+	"gem-pro": "Proto-Germanic",
+	"ine-pro": "Proto-Indo-European",
+	"non":     "Old Norse",
+	"goh":     "Old High German",
+	"gmh":     "Middle High German",
+	"de":      "German",
+	"dum":     "Middle Dutch",
+	"nl":      "Dutch",
+	"odt":     "Old Dutch",
+	"osx":     "Old Saxon",
+	"ofs":     "Old Frisian",
+	"fy":      "West Frisian",
+	"got":     "Gothic",
+	"fro":     "Old French",
+	"frm":     "Middle French",
+	"xno":     "Anglo-Norman",
+	"fr":      "French",
+	"la":      "Latin",
+	"LL.":     "Late Latin",
+	"ML.":     "Medieval Latin",
+	"NL.":     "New Latin",
+	"la-vul":  "Vulgar Latin",
+	"grc":     "Ancient Greek",
+	"el":      "Greek",
+	"it":      "Italian",
+	"es":      "Spanish",
+	"pt":      "Portuguese",
+	"ar":      "Arabic",
+	"fa":      "Persian",
+	"sa":      "Sanskrit",
+	"hi":      "Hindi",
+	"ja":      "Japanese",
+	"zh":      "Chinese",
+	"cmn":     "Mandarin",
+	"yue":     "Cantonese",
+	"ga":      "Irish",
+	"cy":      "Welsh",
+	"da":      "Danish",
+	"sv":      "Swedish",
+	"no":      "Norwegian",
+	"is":      "Icelandic",
+	"ru":      "Russian",
+	"pl":      "Polish",
+	"tr":      "Turkish",
+	"he":      "Hebrew",
+}
+
+var (
+	wikiHeading  = regexp.MustCompile(`^(={2,6})\s*([^=].*?)\s*={2,6}\s*$`)
+	wikiComment  = regexp.MustCompile(`(?s)<!--.*?-->`)
+	wikiRef      = regexp.MustCompile(`(?s)<ref[^>]*/>|<ref[^>]*>.*?</ref>`)
+	wikiLink     = regexp.MustCompile(`\[\[([^\[\]]*)\]\]`)
+	wikiTemplate = regexp.MustCompile(`\{\{([^{}]*)\}\}`)
+	wikiNamedArg = regexp.MustCompile(`(?s)^\s*([\w-]+)\s*=(.*)$`)
+	wikiEmphasis = regexp.MustCompile(`'{2,}`)
+	wikiIPA      = regexp.MustCompile(`\{\{IPA\|en\|([^|}]+)`)
+	wikiAudio    = regexp.MustCompile(`\{\{audio\|en\|([^|}]+)`)
+)
+
+// wiktionaryEntry extracts the English section of a Wiktionary page: the etymologies, the first
+// IPA transcription and audio file of the pronunciation sections, and the senses under the
+// part-of-speech headings as "pos. sense" lines of the definition. ok is false when the page has
+// no English section or nothing could be extracted from it.
+func wiktionaryEntry(title, text string) (e *Entry, ok bool) {
+	e = &Entry{Word: title}
+	var etymologies, senses, etymology []string
+	endEtymology := func() {
+		if s := wikitextPlain(strings.Join(etymology, " ")); s != "" {
+			etymologies = append(etymologies, s)
+		}
+		etymology = nil
+	}
+
+	english, section := false, ""
+	for _, line := range strings.Split(text, "\n") {
+		if m := wikiHeading.FindStringSubmatch(line); m != nil {
+			if len(m[1]) == 2 {
+				if english {
+					break // The next language
+				}
+				english = m[2] == "English"
+				continue
+			}
+			if english {
+				endEtymology()
+				section = m[2]
+			}
+			continue
+		}
+		if !english {
+			continue
+		}
+		switch {
+		case strings.HasPrefix(section, "Etymology"):
+			etymology = append(etymology, line)
+		case section == "Pronunciation":
+			if m := wikiIPA.FindStringSubmatch(line); m != nil && e.Phonetic == "" {
+				e.Phonetic = strings.Trim(strings.TrimSpace(m[1]), "/[]")
+			}
+			if m := wikiAudio.FindStringSubmatch(line); m != nil && e.Audio == "" {
+				e.Audio = wikimediaFileURL(m[1])
+			}
+		default:
+			// Senses are "# ..." lines; "#:" examples, "#*" quotations and "##" subsenses are skipped.
+			abbr, isPOS := wiktionaryPOS[section]
+			if !isPOS || len(line) < 2 || line[0] != '#' || strings.ContainsRune("*:#", rune(line[1])) {
+				continue
+			}
+			if s := wikitextPlain(line[1:]); s != "" {
+				senses = append(senses, abbr+" "+s)
+			}
+		}
+	}
+	endEtymology()
+
+	e.Etymology = strings.Join(etymologies, "\n")
+	e.Definition = strings.Join(senses, "\n")
+	return e, e.Etymology != "" || e.Definition != "" || e.Phonetic != "" || e.Audio != ""
+}
+
+// wikitextPlain renders a fragment of wikitext as plain text: links become their label, the
+// templates common in etymologies and senses are rendered roughly as Wiktionary shows them, other
+// templates, references and markup are dropped, and whitespace is collapsed.
+func wikitextPlain(s string) string {
+	s = wikiComment.ReplaceAllString(s, "")
+	s = wikiRef.ReplaceAllString(s, "")
+	s = wikiLink.ReplaceAllStringFunc(s, func(m string) string { return wikiLinkText(m[2 : len(m)-2]) })
+	// Templates are expanded innermost first.
+	for {
+		t := wikiTemplate.ReplaceAllStringFunc(s, func(m string) string { return wikiTemplateText(m[2 : len(m)-2]) })
+		if t == s {
+			break
+		}
+		s = t
+	}
+	s = wikiEmphasis.ReplaceAllString(s, "")
+	s = html.UnescapeString(markupTag.ReplaceAllString(s, ""))
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// wikiLinkText returns the text shown for the link [[inner]]. Links to files and categories are
+// dropped.
+func wikiLinkText(inner string) string {
+	target := strings.TrimPrefix(inner, ":")
+	lower := strings.ToLower(target)
+	for _, ns := range []string{"file:", "image:", "category:"} {
+		if strings.HasPrefix(lower, ns) {
+			return ""
+		}
+	}
+	if i := strings.LastIndex(target, "|"); i >= 0 {
+		return target[i+1:]
+	}
+	target, _, _ = strings.Cut(target, "#")
+	return strings.TrimPrefix(target, "w:")
+}
+
+// wikiTemplateText renders the template {{inner}}, whose arguments contain no templates.
+func wikiTemplateText(inner string) string {
+	parts := strings.Split(inner, "|")
+	name := strings.TrimSpace(parts[0])
+	var args []string
+	named := make(map[string]string)
+	for _, p := range parts[1:] {
+		if m := wikiNamedArg.FindStringSubmatch(p); m != nil {
+			named[m[1]] = strings.TrimSpace(m[2])
+		} else {
+			args = append(args, strings.TrimSpace(p))
+		}
+	}
+	arg := func(i int) string {
+		if i < len(args) {
+			return args[i]
+		}
+		return ""
+	}
+	// term renders a term with its alternative display form and gloss, as in
+	// {{m|en|go||to move}}: the lang, term, alt and gloss arguments start at argument i.
+	term := func(i int) string {
+		text := arg(i + 2)
+		if text == "" {
+			text = arg(i + 1)
+		}
+		gloss := arg(i + 3)
+		for _, k := range []string{"t", "gloss"} {
+			if gloss == "" {
+				gloss = named[k]
+			}
+		}
+		if gloss != "" {
+			text += " (“" + gloss + "”)"
+		}
+		return text
+	}
+	language := func(code string) string {
+		if name, ok := wiktionaryLanguages[code]; ok {
+			return name
+		}
+		return code
+	}
+
+	switch name {
+	case "inh", "inh+", "der", "der+", "bor", "bor+", "lbor", "ubor", "uder", "cal", "calque":
+		// {{inh|en|ang|gān||to go}}: the first argument is the language of the entry.
+		if t := term(1); t != "" && t != "-" {
+			return language(arg(1)) + " " + t
+		}
+		return language(arg(1))
+	case "cog", "noncog", "nc":
+		if t := term(0); t != "" && t != "-" {
+			return language(arg(0)) + " " + t
+		}
+		return language(arg(0))
+	case "m", "mention", "l", "link", "ll", "l-self", "m-self":
+		return term(0)
+	case "lb", "lbl", "label", "tlb":
+		var labels []string
+		for _, l := range args[min(1, len(args)):] {
+			if l != "" && l != "_" {
+				labels = append(labels, l)
+			}
+		}
+		return "(" + strings.Join(labels, ", ") + ")"
+	case "gloss", "gl", "q", "qual", "qualifier", "i", "qf":
+		return "(" + strings.Join(args, ", ") + ")"
+	case "w", "W", "pedia":
+		if alt := arg(1); alt != "" {
+			return alt
+		}
+		return arg(0)
+	case "n-g", "ng", "non-gloss definition", "non-gloss", "taxlink", "vern":
+		return arg(0)
+	}
+	// Form-of templates such as {{plural of|en|cat}} or {{past tense of|en|go}}.
+	if strings.HasSuffix(name, " of") && arg(1) != "" {
+		return name + " " + term(0)
+	}
+	return ""
+}
+
+// wikimediaFileURL returns the download URL of a Wikimedia Commons file such as "En-us-go.ogg".
+// Commons stores files under the first hex digits of the MD5 of their normalized name.
+func wikimediaFileURL(name string) string {
+	name = strings.ReplaceAll(strings.TrimSpace(name), " ", "_")
+	if r, size := utf8.DecodeRuneInString(name); r != utf8.RuneError {
+		name = string(unicode.ToUpper(r)) + name[size:]
+	}
+	sum := md5.Sum([]byte(name))
+	h := hex.EncodeToString(sum[:])
+	return "https://upload.wikimedia.org/wikipedia/commons/" + h[:1] + "/" + h[:2] + "/" + url.PathEscape(name)
+}
+
+// ImportWiktionary imports the English entries of a Wiktionary XML dump, plain or compressed
+// with bzip2 or gzip as published on dumps.wikimedia.org, into the store's bucket. The dump is
+// parsed as a stream. Each page of the main namespace with an English section becomes a record
+// with its etymology, IPA phonetic, audio URL and senses (see wiktionaryEntry), keyed by the
+// lowercased title; when titles differ only in case, the lowercase page wins.
+//
+// The dictionary is marked as a supplement, so ne merges its fields into the entries of the other
+// dictionaries. The display name defaults to "Wiktionary".
+func (s *DBStore) ImportWiktionary(ctx context.Context, path string, opts ImportOptions) (int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open Wiktionary dump '%s': %w", path, err)
+	}
+	defer f.Close()
+
+	// The checksum is computed while the dump is read, rather than in a second pass.
+	hasher := sha256.New()
+	br := bufio.NewReader(io.TeeReader(f, hasher))
+	var r io.Reader = br
+	if magic, _ := br.Peek(3); bytes.HasPrefix(magic, []byte("BZh")) {
+		r = bzip2.NewReader(br)
+	} else if bytes.HasPrefix(magic, []byte{0x1f, 0x8b}) {
+		zr, err := gzip.NewReader(br)
+		if err != nil {
+			return 0, fmt.Errorf("failed to read Wiktionary dump '%s': %w", path, err)
+		}
+		r = zr
+	}
+	dec := xml.NewDecoder(r)
+
+	meta := &Metadata{
+		Format:        "wiktionary",
+		SourceFile:    filepath.Base(path),
+		HeaderColumns: []string{"word", FieldPhonetic, FieldDefinition, FieldAudio, FieldEtymology},
+	}
+	seen := make(map[string]bool)
+	next := func() (string, map[string]string, error) {
+		for {
+			tok, err := dec.Token()
+			if err == io.EOF {
+				if _, err := io.Copy(io.Discard, br); err != nil {
+					return "", nil, fmt.Errorf("failed to read Wiktionary dump: %w", err)
+				}
+				meta.SourceSHA256 = hex.EncodeToString(hasher.Sum(nil))
+				return "", nil, io.EOF
+			}
+			if err != nil {
+				return "", nil, fmt.Errorf("failed to parse Wiktionary dump: %w", err)
+			}
+			start, ok := tok.(xml.StartElement)
+			if !ok || start.Name.Local != "page" {
+				continue
+			}
+			var page wiktionaryPage
+			if err := dec.DecodeElement(&page, &start); err != nil {
+				return "", nil, fmt.Errorf("failed to parse Wiktionary dump: %w", err)
+			}
+			word := strings.ToLower(page.Title)
+			if page.NS != 0 || page.Redirect != nil || (word != page.Title && seen[word]) {
+				continue
+			}
+			e, ok := wiktionaryEntry(page.Title, page.Text)
+			if !ok {
+				continue
+			}
+			seen[word] = true
+			return word, e.Map(), nil
+		}
+	}
+
+	opts.Manifest.Supplement = true
+	if opts.Manifest.DisplayName == "" {
+		opts.Manifest.DisplayName = "Wiktionary"
+	}
+	records, err := s.importRecords(ctx, next, meta, opts)
+	if err != nil {
+		return records, fmt.Errorf("failed to import Wiktionary dump '%s': %w", path, err)
+	}
+	return records, nil
+}
@@ -0,0 +1,159 @@
+package bbolthelper
+
+import (
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// testWiktionaryDump is a MediaWiki XML dump fixture. "go" has English and Dutch sections, "Go"
+// differs only in case, and the redirect, the talk page and the French-only page are skipped.
+const testWiktionaryDump = `<mediawiki xmlns="http://www.mediawiki.org/xml/export-0.11/" version="0.11" xml:lang="en">
+  <siteinfo><sitename>Wiktionary</sitename></siteinfo>
+  <page>
+    <title>Go</title>
+    <ns>0</ns>
+    <revision><text xml:space="preserve">==English==
+===Proper noun===
+# {{lb|en|programming}} A programming language.
+</text></revision>
+  </page>
+  <page>
+    <title>go</title>
+    <ns>0</ns>
+    <revision><text xml:space="preserve">==English==
+{{wikipedia}}
+===Etymology===
+From {{inh|en|enm|go}}, {{m|enm|gon}}, from {{inh|en|ang|gān||to go}}.&lt;ref&gt;OED&lt;/ref&gt;
+{{cog|de|gehen}}.
+
+===Pronunciation===
+* {{IPA|en|/ɡəʊ/|a=RP}}
+* {{IPA|en|/ɡoʊ/|a=GA}}
+* {{audio|en|En-uk-go.ogg|a=UK}}
+
+===Verb===
+{{en-verb|went|gone}}
+# {{lb|en|intransitive}} To [[move]] from one place to [[another#English|another]].
+#: ''Let's '''go'''.''
+#* 1611, King James Version
+## {{lb|en|euphemistic}} To die.
+# {{past tense of|en|goe}}
+
+===Noun===
+# A [[w:Go (game)|board game]] played on a grid.&lt;!-- comment --&gt;
+
+[[Category:English verbs]]
+
+==Dutch==
+===Verb===
+# gone
+</text></revision>
+  </page>
+  <page>
+    <title>goes</title>
+    <ns>0</ns>
+    <redirect title="go" />
+    <revision><text xml:space="preserve">#REDIRECT [[go]]</text></revision>
+  </page>
+  <page>
+    <title>Talk:go</title>
+    <ns>1</ns>
+    <revision><text xml:space="preserve">==English==
+===Noun===
+# discussion
+</text></revision>
+  </page>
+  <page>
+    <title>aller</title>
+    <ns>0</ns>
+    <revision><text xml:space="preserve">==French==
+===Verb===
+# to go
+</text></revision>
+  </page>
+</mediawiki>
+`
+
+func TestWikitextPlain(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"From {{inh|en|ang|gān||to go}}, from {{der|en|xyz|*gangan}}", "From Old English gān (“to go”), from xyz *gangan"},
+		{"{{lb|en|transitive|_|informal}} To [[walk]] [[quickly|fast]].", "(transitive, informal) To walk fast."},
+		{"{{plural of|en|cat}}", "plural of cat"},
+		{"{{m|en|go|t=to move}} and {{unknown|x}}[[File:Go.png|thumb|A go board]]", "go (“to move”) and"},
+		{"'''bold''' &amp; <sup>1</sup>{{n-g|Used as a greeting.}}", "bold & 1Used as a greeting."},
+	}
+	for _, tc := range tests {
+		if got := wikitextPlain(tc.in); got != tc.want {
+			t.Errorf("wikitextPlain(%q) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestWikimediaFileURL(t *testing.T) {
+	want := "https://upload.wikimedia.org/wikipedia/commons/" // Followed by the MD5 directories
+	got := wikimediaFileURL("en-us go.ogg")
+	if !strings.HasPrefix(got, want) || !strings.HasSuffix(got, "/En-us_go.ogg") {
+		t.Errorf("wikimediaFileURL() = %q, want a Commons URL of En-us_go.ogg", got)
+	}
+}
+
+func TestDBStore_ImportWiktionary(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "enwiktionary-pages-articles.xml.gz")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("Failed to create dump: %v", err)
+	}
+	zw := gzip.NewWriter(f)
+	zw.Write([]byte(testWiktionaryDump))
+	zw.Close()
+	f.Close()
+
+	store := newTestStore(t, Config{})
+	n, err := store.ImportWiktionary(t.Context(), path, ImportOptions{})
+	if err != nil {
+		t.Fatalf("ImportWiktionary() failed: %v", err)
+	}
+	if n != 2 {
+		t.Errorf("ImportWiktionary() stored %d records, want 2 (Go and go under one key)", n)
+	}
+
+	entry, found, err := store.GetEntry("go")
+	if err != nil || !found {
+		t.Fatalf("GetEntry(go) = found %v, %v", found, err)
+	}
+	if want := "From Middle English go, gon, from Old English gān (“to go”). German gehen."; entry.Etymology != want {
+		t.Errorf("Etymology = %q, want %q", entry.Etymology, want)
+	}
+	if entry.Phonetic != "ɡəʊ" {
+		t.Errorf("Phonetic = %q, want %q", entry.Phonetic, "ɡəʊ")
+	}
+	if !strings.HasSuffix(entry.Audio, "/En-uk-go.ogg") {
+		t.Errorf("Audio = %q, want the Commons URL of En-uk-go.ogg", entry.Audio)
+	}
+	wantDefinition := "v. (intransitive) To move from one place to another.\nv. past tense of goe\nn. A board game played on a grid."
+	if entry.Definition != wantDefinition {
+		t.Errorf("Definition = %q, want %q", entry.Definition, wantDefinition)
+	}
+	for _, word := range []string{"goes", "talk:go", "aller"} {
+		if _, found, _ := store.GetEntry(word); found {
+			t.Errorf("GetEntry(%q) found an entry, want it skipped", word)
+		}
+	}
+
+	meta, found, err := store.Metadata()
+	if err != nil || !found {
+		t.Fatalf("Metadata() = found %v, %v", found, err)
+	}
+	sum, err := fileSHA256(path)
+	if err != nil {
+		t.Fatalf("fileSHA256() failed: %v", err)
+	}
+	if !meta.Supplement || meta.DisplayName != "Wiktionary" || meta.RecordCount != 1 || meta.SourceSHA256 != sum {
+		t.Errorf("Metadata() = %+v, want a Wiktionary supplement of 1 record with the dump checksum", meta)
+	}
+}
@@ -0,0 +1,425 @@
+package bbolthelper
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	bolt "go.etcd.io/bbolt"
+	"go.uber.org/zap"
+)
+
+// Thesaurus relations imported from WordNet. Each relation is stored in a bucket named
+// "<dictionary bucket>:<relation>" mapping a lemma to its related words, one per line, most
+// common sense first.
+const (
+	// RelationSynonyms lists the other lemmas of the lemma's synsets.
+	RelationSynonyms = "synonyms"
+	// RelationAntonyms lists the lemmas WordNet marks as opposites of the lemma.
+	RelationAntonyms = "antonyms"
+	// RelationRelated lists broader terms (hypernyms) and, for adjectives, similar terms.
+	RelationRelated = "related"
+)
+
+// ThesaurusRelations lists the relations stored by ImportWordNet.
+var ThesaurusRelations = []string{RelationSynonyms, RelationAntonyms, RelationRelated}
+
+// wordNetFiles lists the parts of speech of the WordNet database files. Senses of a lemma in
+// several parts of speech are ordered by their tag counts in index.sense, and in this order when
+// the counts are equal or index.sense is missing.
+var wordNetFiles = []struct {
+	suffix string
+	pos    byte
+	label  string
+}{
+	{"noun", 'n', "n."},
+	{"verb", 'v', "v."},
+	{"adj", 'a', "adj."},
+	{"adv", 'r', "adv."},
+}
+
+// wordNetMarker matches the syntactic marker of adjectives in data.adj, e.g. "galore(ip)".
+var wordNetMarker = regexp.MustCompile(`\([a-z]+\)$`)
+
+// wordNetPointer is a relation from a synset, or one of its words, to another synset.
+type wordNetPointer struct {
+	symbol string
+	target string // Synset key, see wordNetKey
+	// sourceWord and targetWord are the 1-based word numbers of a lexical relation, 0 for a
+	// semantic one.
+	sourceWord, targetWord int
+}
+
+// wordNetSynset is one sense shared by a set of lemmas.
+type wordNetSynset struct {
+	words    []string
+	pointers []wordNetPointer
+	gloss    string
+}
+
+// wordNetKey identifies a synset by part of speech and data file offset. Adjective satellites
+// ("s") live in data.adj with the head adjectives.
+func wordNetKey(pos byte, offset string) string {
+	if pos == 's' {
+		pos = 'a'
+	}
+	return string(pos) + offset
+}
+
+// wordNetLemma converts a WordNet word to a dictionary key: lower case, with spaces instead of
+// underscores and without adjective markers.
+func wordNetLemma(word string) string {
+	return strings.ToLower(strings.ReplaceAll(wordNetMarker.ReplaceAllString(word, ""), "_", " "))
+}
+
+// parseWordNetData parses a line of a data.* file:
+//
+//	offset lex_filenum ss_type w_cnt word lex_id [word lex_id...] p_cnt [ptr...] [frames...] | gloss
+//
+// where w_cnt is hexadecimal and each pointer is "symbol offset pos source/target".
+func parseWordNetData(line string) (string, *wordNetSynset, error) {
+	data, gloss, _ := strings.Cut(line, " | ")
+	f := strings.Fields(data)
+	if len(f) < 4 {
+		return "", nil, fmt.Errorf("too few fields")
+	}
+	wordCount, err := strconv.ParseInt(f[3], 16, 32)
+	if err != nil || len(f) < 5+2*int(wordCount) {
+		return "", nil, fmt.Errorf("invalid word count '%s'", f[3])
+	}
+	syn := &wordNetSynset{gloss: strings.TrimSpace(gloss)}
+	for i := 0; i < int(wordCount); i++ {
+		syn.words = append(syn.words, wordNetLemma(f[4+2*i]))
+	}
+	p := 4 + 2*int(wordCount)
+	ptrCount, err := strconv.Atoi(f[p])
+	if err != nil || len(f) < p+1+4*ptrCount {
+		return "", nil, fmt.Errorf("invalid pointer count '%s'", f[p])
+	}
+	for i := 0; i < ptrCount; i++ {
+		ptr := f[p+1+4*i : p+5+4*i]
+		words, err := strconv.ParseUint(ptr[3], 16, 16)
+		if err != nil || len(ptr[2]) != 1 {
+			return "", nil, fmt.Errorf("invalid pointer '%s'", strings.Join(ptr, " "))
+		}
+		syn.pointers = append(syn.pointers, wordNetPointer{
+			symbol:     ptr[0],
+			target:     wordNetKey(ptr[2][0], ptr[1]),
+			sourceWord: int(words >> 8),
+			targetWord: int(words & 0xff),
+		})
+	}
+	return wordNetKey(f[2][0], f[0]), syn, nil
+}
+
+// readWordNetFile calls fn with every line of a WordNet database file, skipping the license
+// header, whose lines start with a space.
+func readWordNetFile(path string, fn func(line string) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	for sc.Scan() {
+		if line := sc.Text(); line != "" && line[0] != ' ' {
+			if err := fn(line); err != nil {
+				return err
+			}
+		}
+	}
+	return sc.Err()
+}
+
+// wordNetSense is one synset of a lemma, the lemma's word number in it, and the number of times
+// the sense was tagged in WordNet's semantic concordance.
+type wordNetSense struct {
+	synset   *wordNetSynset
+	pos      byte
+	word     int
+	tagCount int
+}
+
+// wordNetSenseTypes maps the synset type digit of a WordNet sense key to its part of speech.
+var wordNetSenseTypes = map[byte]byte{'1': 'n', '2': 'v', '3': 'a', '4': 'r', '5': 's'}
+
+// readWordNetTagCounts reads index.sense, whose lines are
+//
+//	lemma%ss_type:lex_filenum:lex_id:head_word:head_id synset_offset sense_number tag_cnt
+//
+// and returns the tag count of each sense, keyed by lemma and synset key (see wordNetKey).
+func readWordNetTagCounts(path string) (map[string]int, error) {
+	counts := make(map[string]int)
+	err := readWordNetFile(path, func(line string) error {
+		f := strings.Fields(line)
+		if len(f) != 4 {
+			return nil
+		}
+		word, key, ok := strings.Cut(f[0], "%")
+		count, err := strconv.Atoi(f[3])
+		if !ok || key == "" || wordNetSenseTypes[key[0]] == 0 || err != nil {
+			return nil
+		}
+		pos := wordNetSenseTypes[key[0]]
+		counts[wordNetLemma(word)+" "+wordNetKey(pos, f[1])] = count
+		return nil
+	})
+	return counts, err
+}
+
+// ImportWordNet imports a Princeton WordNet database directory (data.noun, index.noun and so on
+// for verbs, adjectives and adverbs, and index.sense if present). Every lemma of the index files
+// becomes a record whose definition lists the gloss of each sense, most common first across parts
+// of speech (see wordNetFiles), and the thesaurus relations (RelationSynonyms, RelationAntonyms,
+// RelationRelated) are stored in their own buckets next to the dictionary bucket. The dictionary
+// is named WordNet unless opts.Manifest sets another display name. It returns the number of
+// records stored.
+func (s *DBStore) ImportWordNet(ctx context.Context, dir string, opts ImportOptions) (int, error) {
+	s.logger.Info("Starting WordNet import...", zap.String("source", dir))
+
+	synsets := make(map[string]*wordNetSynset)
+	for _, file := range wordNetFiles {
+		path := filepath.Join(dir, "data."+file.suffix)
+		err := readWordNetFile(path, func(line string) error {
+			key, syn, err := parseWordNetData(line)
+			if err != nil {
+				s.logger.Warn("Malformed WordNet data line, skipping.", zap.String("file", path), zap.String("line", line), zap.Error(err))
+				return nil
+			}
+			synsets[key] = syn
+			return nil
+		})
+		if err != nil {
+			return 0, fmt.Errorf("failed to read WordNet data file '%s': %w", path, err)
+		}
+	}
+
+	// index.sense counts how often each sense was tagged, which orders the senses of a lemma
+	// across parts of speech, so that the verb "go" comes before the board game.
+	tagCounts := map[string]int{}
+	sensePath := filepath.Join(dir, "index.sense")
+	if _, err := os.Stat(sensePath); err == nil {
+		if tagCounts, err = readWordNetTagCounts(sensePath); err != nil {
+			return 0, fmt.Errorf("failed to read WordNet sense index '%s': %w", sensePath, err)
+		}
+	} else {
+		s.logger.Warn("WordNet sense index not found, listing senses by part of speech.", zap.String("file", sensePath))
+	}
+
+	// The index files list the synsets of each lemma by decreasing frequency:
+	//	lemma pos synset_cnt p_cnt [ptr_symbol...] sense_cnt tagsense_cnt synset_offset...
+	senses := make(map[string][]wordNetSense)
+	for _, file := range wordNetFiles {
+		path := filepath.Join(dir, "index."+file.suffix)
+		err := readWordNetFile(path, func(line string) error {
+			f := strings.Fields(line)
+			count := -1
+			if len(f) >= 4 {
+				count, _ = strconv.Atoi(f[2])
+			}
+			if count < 0 || len(f) < 4+count {
+				s.logger.Warn("Malformed WordNet index line, skipping.", zap.String("file", path), zap.String("line", line))
+				return nil
+			}
+			lemma := wordNetLemma(f[0])
+			for _, offset := range f[len(f)-count:] {
+				key := wordNetKey(file.pos, offset)
+				syn := synsets[key]
+				if syn == nil {
+					continue
+				}
+				for i, w := range syn.words {
+					if w == lemma {
+						senses[lemma] = append(senses[lemma], wordNetSense{synset: syn, pos: file.pos, word: i + 1,
+							tagCount: tagCounts[lemma+" "+key]})
+						break
+					}
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return 0, fmt.Errorf("failed to read WordNet index file '%s': %w", path, err)
+		}
+	}
+
+	lemmas := make([]string, 0, len(senses))
+	for lemma, ss := range senses {
+		lemmas = append(lemmas, lemma)
+		sort.SliceStable(ss, func(i, j int) bool { return ss[i].tagCount > ss[j].tagCount })
+	}
+	sort.Strings(lemmas)
+
+	relations := map[string]map[string][]string{}
+	for _, rel := range ThesaurusRelations {
+		relations[rel] = make(map[string][]string)
+	}
+	for _, lemma := range lemmas {
+		for _, sense := range senses[lemma] {
+			addWordNetRelations(relations, lemma, sense, synsets)
+		}
+	}
+
+	labels := map[byte]string{}
+	for _, file := range wordNetFiles {
+		labels[file.pos] = file.label
+	}
+	i := 0
+	next := func() (string, map[string]string, error) {
+		if i >= len(lemmas) {
+			return "", nil, io.EOF
+		}
+		lemma := lemmas[i]
+		i++
+		var glosses []string
+		for _, sense := range senses[lemma] {
+			glosses = append(glosses, labels[sense.pos]+" "+sense.synset.gloss)
+		}
+		return lemma, (&Entry{Word: lemma, Definition: strings.Join(glosses, "\n")}).Map(), nil
+	}
+
+	if opts.Manifest.DisplayName == "" {
+		opts.Manifest.DisplayName = "WordNet"
+	}
+	meta := &Metadata{
+		Format:        "wordnet",
+		SourceFile:    filepath.Base(filepath.Clean(dir)),
+		HeaderColumns: []string{"word", FieldDefinition},
+	}
+	records, err := s.importRecords(ctx, next, meta, opts)
+	if err != nil {
+		return records, fmt.Errorf("failed to import WordNet database '%s': %w", dir, err)
+	}
+
+	for _, rel := range ThesaurusRelations {
+		if err := s.putRelation(rel, relations[rel]); err != nil {
+			return records, err
+		}
+	}
+	return records, nil
+}
+
+// addWordNetRelations adds the relations of one sense of a lemma.
+func addWordNetRelations(relations map[string]map[string][]string, lemma string, sense wordNetSense, synsets map[string]*wordNetSynset) {
+	for _, w := range sense.synset.words {
+		if w != lemma {
+			relations[RelationSynonyms][lemma] = append(relations[RelationSynonyms][lemma], w)
+		}
+	}
+	for _, ptr := range sense.synset.pointers {
+		target := synsets[ptr.target]
+		if target == nil {
+			continue
+		}
+		switch {
+		case ptr.symbol == "!" && ptr.sourceWord == sense.word && ptr.targetWord > 0 && ptr.targetWord <= len(target.words):
+			relations[RelationAntonyms][lemma] = append(relations[RelationAntonyms][lemma], target.words[ptr.targetWord-1])
+		case ptr.symbol == "@" || ptr.symbol == "@i" || ptr.symbol == "&":
+			for _, w := range target.words {
+				if w != lemma {
+					relations[RelationRelated][lemma] = append(relations[RelationRelated][lemma], w)
+				}
+			}
+		}
+	}
+}
+
+// relationBucketName returns the bucket holding a thesaurus relation of the store's dictionary.
+func (s *DBStore) relationBucketName(relation string) []byte {
+	return []byte(s.bucketName + ":" + relation)
+}
+
+// putRelation replaces the bucket of a thesaurus relation in one transaction.
+func (s *DBStore) putRelation(relation string, lists map[string][]string) error {
+	name := s.relationBucketName(relation)
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		if err := tx.DeleteBucket(name); err != nil && err != bolt.ErrBucketNotFound {
+			return fmt.Errorf("failed to drop old relation bucket '%s': %w", name, err)
+		}
+		b, err := tx.CreateBucket(name)
+		if err != nil {
+			return fmt.Errorf("failed to create relation bucket '%s': %w", name, err)
+		}
+		return emitSortedLists(lists, "\n", b.Put)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to store relation '%s': %w", relation, err)
+	}
+	s.logger.Info("Thesaurus relation stored", zap.String("relation", relation), zap.Int("lemmas", len(lists)))
+	return nil
+}
+
+// Thesaurus returns the highest-priority dictionary of the database with thesaurus relations, as
+// imported by ImportWordNet. The boolean is false when there is none.
+func (s *DBStore) Thesaurus() (*DBStore, bool, error) {
+	dicts, err := s.Dictionaries()
+	if err != nil {
+		return nil, false, err
+	}
+	var thesaurus *DBStore
+	err = s.db.View(func(tx *bolt.Tx) error {
+		for _, d := range dicts {
+			if t := s.Dictionary(d.Bucket); tx.Bucket(t.relationBucketName(RelationSynonyms)) != nil {
+				thesaurus = t
+				return nil
+			}
+		}
+		return nil
+	})
+	return thesaurus, thesaurus != nil, err
+}
+
+// RelatedWord is a word related to a lemma, cross-linked to a dictionary entry.
+type RelatedWord struct {
+	Word string `json:"word"`
+	// Translation is the first line of the word's translation in the cross-linked dictionary.
+	Translation string `json:"translation,omitempty"`
+}
+
+// Related returns the words related to word by a thesaurus relation of the store, most common
+// sense first. Each word carries the first line of its translation in dict, typically ECDICT,
+// when dict has an entry for it. A limit <= 0 returns all words.
+func (s *DBStore) Related(word, relation string, dict *DBStore, limit int) ([]RelatedWord, error) {
+	var related []RelatedWord
+	err := s.db.View(func(tx *bolt.Tx) error {
+		rb := tx.Bucket(s.relationBucketName(relation))
+		if rb == nil {
+			return fmt.Errorf("relation '%s' not found for dictionary '%s'", relation, s.bucketName)
+		}
+		v := rb.Get([]byte(strings.ToLower(word)))
+		if v == nil {
+			return nil
+		}
+		var db *bolt.Bucket
+		if dict != nil {
+			db = tx.Bucket([]byte(dict.bucketName))
+		}
+		for _, w := range strings.Split(string(v), "\n") {
+			if limit > 0 && len(related) >= limit {
+				break
+			}
+			rw := RelatedWord{Word: w}
+			if db != nil {
+				if data := db.Get([]byte(w)); data != nil {
+					if t, ok, err := DeserializeField(data, FieldTranslation); err == nil && ok {
+						rw.Translation, _, _ = strings.Cut(decodeEscapes(t), "\n")
+					}
+				}
+			}
+			related = append(related, rw)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return related, nil
+}
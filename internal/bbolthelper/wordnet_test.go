@@ -0,0 +1,148 @@
+package bbolthelper
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// testWordNet is a WordNet database fixture: dog is a canine, happy and unhappy are antonyms,
+// cheerful is similar to happy, and go shares a verb synset with travel. The board game go is
+// listed first in the files, but index.sense ranks the verb above it.
+var testWordNet = map[string]string{
+	"data.noun": `  1 This software and database is being provided to you, the LICENSEE, by
+00000001 05 n 02 dog 0 domestic_dog 0 001 @ 00000002 n 0000 | a domesticated canine
+00000002 05 n 01 canine 0 000 | a carnivore with teeth for tearing
+00000003 04 n 01 go 0 000 | a board game for two players
+`,
+	"data.verb": `00000020 38 v 02 travel 0 go 0 000 | change location; move
+`,
+	"data.adj": `00000010 00 a 02 happy 0 glad(p) 0 002 ! 00000011 a 0101 & 00000012 a 0000 | enjoying well-being
+00000011 00 a 01 unhappy 0 001 ! 00000010 a 0101 | experiencing sorrow
+00000012 00 s 01 cheerful 0 001 & 00000010 a 0000 | being full of cheer
+`,
+	"data.adv": "",
+	"index.noun": `  1 This software and database is being provided to you, the LICENSEE, by
+canine n 1 0 1 0 00000002
+dog n 1 1 @ 1 0 00000001
+domestic_dog n 1 1 @ 1 0 00000001
+go n 1 0 1 0 00000003
+`,
+	"index.verb": `go v 1 0 1 0 00000020
+travel v 1 0 1 0 00000020
+`,
+	"index.adj": `cheerful a 1 1 & 1 0 00000012
+glad a 1 0 1 0 00000010
+happy a 1 2 ! & 1 0 00000010
+unhappy a 1 1 ! 1 0 00000011
+`,
+	"index.adv": "",
+	"index.sense": `go%1:04:00:: 00000003 1 0
+go%2:38:00:: 00000020 1 5
+happy%3:00:00:: 00000010 1 3
+`,
+}
+
+// newWordNetTestStore imports the base CSV fixture and the WordNet fixture, as the "wordnet"
+// dictionary, into one database and returns the base store.
+func newWordNetTestStore(t *testing.T) *DBStore {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range testWordNet {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	dbPath := filepath.Join(t.TempDir(), "wordnet.db")
+	wordnet := newTestStore(t, Config{DBPath: dbPath, BucketName: "wordnet"})
+	n, err := wordnet.ImportWordNet(t.Context(), dir, ImportOptions{})
+	if err != nil {
+		t.Fatalf("ImportWordNet() failed: %v", err)
+	}
+	if n != 9 {
+		t.Errorf("ImportWordNet() stored %d records, want 9", n)
+	}
+	wordnet.Close()
+
+	base := newTestStore(t, Config{DBPath: dbPath})
+	if _, err := base.ImportCSV(t.Context(), writeTestCSV(t, t.TempDir(), testCSVRows), ImportOptions{}); err != nil {
+		t.Fatalf("ImportCSV() failed: %v", err)
+	}
+	return base
+}
+
+func TestDBStore_ImportWordNet(t *testing.T) {
+	base := newWordNetTestStore(t)
+	thesaurus, found, err := base.Thesaurus()
+	if err != nil || !found {
+		t.Fatalf("Thesaurus() = found %v, %v; want the wordnet dictionary", found, err)
+	}
+	if thesaurus.BucketName() != "wordnet" {
+		t.Errorf("Thesaurus() bucket = %q, want %q", thesaurus.BucketName(), "wordnet")
+	}
+
+	entry, found, err := thesaurus.GetEntry("happy")
+	if err != nil || !found || entry.Definition != "adj. enjoying well-being" {
+		t.Errorf("GetEntry(happy) = %+v, %v, %v; want the adjective gloss", entry, found, err)
+	}
+	if entry, _, _ := thesaurus.GetEntry("go"); entry == nil || entry.Definition != "v. change location; move\nn. a board game for two players" {
+		t.Errorf("GetEntry(go) = %+v, want the verb sense, tagged more often, first", entry)
+	}
+	if meta, _, _ := thesaurus.Metadata(); meta == nil || meta.Label() != "WordNet" {
+		t.Errorf("Metadata() = %+v, want the WordNet label", meta)
+	}
+
+	tests := []struct {
+		word, relation string
+		want           []string
+	}{
+		{"dog", RelationSynonyms, []string{"domestic dog"}},
+		{"dog", RelationRelated, []string{"canine"}},
+		{"happy", RelationSynonyms, []string{"glad"}},
+		{"happy", RelationAntonyms, []string{"unhappy"}},
+		{"happy", RelationRelated, []string{"cheerful"}},
+		{"glad", RelationAntonyms, nil}, // The antonym pointer is from happy only
+		{"unhappy", RelationAntonyms, []string{"happy"}},
+		{"Travel", RelationSynonyms, []string{"go"}},
+		{"canine", RelationSynonyms, nil},
+	}
+	for _, tc := range tests {
+		related, err := thesaurus.Related(tc.word, tc.relation, nil, 0)
+		if err != nil {
+			t.Errorf("Related(%q, %q) failed: %v", tc.word, tc.relation, err)
+			continue
+		}
+		var words []string
+		for _, r := range related {
+			words = append(words, r.Word)
+		}
+		if !reflect.DeepEqual(words, tc.want) {
+			t.Errorf("Related(%q, %q) = %v, want %v", tc.word, tc.relation, words, tc.want)
+		}
+	}
+}
+
+func TestDBStore_Related_CrossLinks(t *testing.T) {
+	base := newWordNetTestStore(t)
+	thesaurus, _, _ := base.Thesaurus()
+
+	related, err := thesaurus.Related("travel", RelationSynonyms, base, 0)
+	if err != nil {
+		t.Fatalf("Related() failed: %v", err)
+	}
+	// The first translation line of go in the base fixture.
+	if want := []RelatedWord{{Word: "go", Translation: "v. 去"}}; !reflect.DeepEqual(related, want) {
+		t.Errorf("Related(travel) = %+v, want %+v", related, want)
+	}
+
+	related, _ = thesaurus.Related("happy", RelationSynonyms, base, 0)
+	if len(related) != 1 || related[0].Translation != "" {
+		t.Errorf("Related(happy) = %+v, want glad without a translation", related)
+	}
+
+	if _, err := base.Related("dog", RelationSynonyms, nil, 0); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("Related() on a dictionary without relations = %v, want a not found error", err)
+	}
+}